@@ -2,40 +2,113 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 )
 
 const key = "APP_ENV"
 const defaultEnv = "dev"
 
+const (
+	StorageDriverMongo  = "mongo"
+	StorageDriverMemory = "memory"
+	StorageDriverSQLite = "sqlite"
+)
+
 type Conf struct {
+	Storage     StorageConf     `json:"storage"`
 	Mongo       MongoConf       `json:"mongo"`
+	SQLite      SQLiteConf      `json:"sqlite"`
 	JWT         JWTConf         `json:"jwt"`
+	Auth        AuthConf        `json:"auth"`
 	Wallet      WalletConf      `json:"wallet"`
 	Transaction TransactionConf `json:"transaction"`
+	Statement   StatementConf   `json:"statement"`
+	Export      ExportConf      `json:"export"`
+	Balance     BalanceConf     `json:"balance"`
+	Signing     SigningConf     `json:"signing"`
+	StepUp      StepUpConf      `json:"stepUp"`
+	RateLimit   RateLimitConf   `json:"rateLimit"`
+	Archive     ArchiveConf     `json:"archive"`
+}
+
+// StorageConf picks where repositories keep their data, defaulting to mongo.
+// The memory driver needs no database and loses everything on shutdown.
+type StorageConf struct {
+	Driver string `json:"driver"`
 }
 
 type MongoConf struct {
 	URI        string         `json:"uri"`
 	Database   string         `json:"database"`
 	Collection CollectionConf `json:"collection"`
+	Migration  MigrationConf  `json:"migration"`
 }
 
 type CollectionConf struct {
-	Wallet      string `json:"wallet"`
-	Transaction string `json:"transaction"`
+	Wallet             string `json:"wallet"`
+	Transaction        string `json:"transaction"`
+	BankImport         string `json:"bankImport"`
+	BalanceSnapshot    string `json:"balanceSnapshot"`
+	RefreshToken       string `json:"refreshToken"`
+	APIKey             string `json:"apiKey"`
+	OAuthClient        string `json:"oauthClient"`
+	Challenge          string `json:"challenge"`
+	Migration          string `json:"migration"`
+	TransactionArchive string `json:"transactionArchive"`
+	Lease              string `json:"lease"`
+	Denylist           string `json:"denylist"`
+}
+
+type MigrationConf struct {
+	RunAtStartup  bool `json:"runAtStartup"`
+	LockTTLInSec  int  `json:"lockTtlInSec"`
+	LockWaitInSec int  `json:"lockWaitInSec"`
+}
+
+type SQLiteConf struct {
+	Path string `json:"path"`
 }
 
 type JWTConf struct {
-	ValidityDurationInMin int    `json:"validityDurationInMin"`
-	Issuer                string `json:"issuer"`
-	Secret                string `json:"secret"`
+	ValidityDurationInMin          int          `json:"validityDurationInMin"`
+	RefreshValidityDurationInHours int          `json:"refreshValidityDurationInHours"`
+	Issuer                         string       `json:"issuer"`
+	Audience                       string       `json:"audience"`
+	ClockSkewInSec                 int          `json:"clockSkewInSec"`
+	Secret                         string       `json:"secret"`
+	Keys                           []JWTKeyConf `json:"keys"`
+	KeyOverlapInMin                int          `json:"keyOverlapInMin"`
+}
+
+type JWTKeyConf struct {
+	ID             string    `json:"id"`
+	Algorithm      string    `json:"algorithm"`
+	PrivateKeyFile string    `json:"privateKeyFile"`
+	ActivatesAt    time.Time `json:"activatesAt"`
+}
+
+type AuthConf struct {
+	Users []UserConf `json:"users"`
+}
+
+type UserConf struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"passwordHash"`
+	Role         string `json:"role"`
 }
 
 type WalletConf struct {
 	InitialBalance float64 `json:"initialBalance"`
 	MaxBalance     float64 `json:"maxBalance"`
 	MinBalance     float64 `json:"minBalance"`
+	CacheSize      int     `json:"cacheSize"`
+	CacheTTLInSec  int     `json:"cacheTtlInSec"`
+	// FlushIntervalInSec is how often transactions left pending on wallets
+	// are stored, for the storage drivers that can leave them.
+	FlushIntervalInSec int `json:"flushIntervalInSec"`
 }
 
 type TransactionConf struct {
@@ -43,6 +116,95 @@ type TransactionConf struct {
 	MinAmount float64 `json:"minAmount"`
 }
 
+type StatementConf struct {
+	CacheSize int `json:"cacheSize"`
+}
+
+type ExportConf struct {
+	Currency string `json:"currency"`
+	BankID   string `json:"bankId"`
+}
+
+type BalanceConf struct {
+	SnapshotIntervalInMin int `json:"snapshotIntervalInMin"`
+}
+
+// ArchiveConf sets up moving the transactions older than MaxAgeInDays out of
+// the live ones. Archived transactions are only read while it is enabled.
+type ArchiveConf struct {
+	Enabled       bool `json:"enabled"`
+	MaxAgeInDays  int  `json:"maxAgeInDays"`
+	IntervalInMin int  `json:"intervalInMin"`
+	BatchSize     int  `json:"batchSize"`
+	LeaseTTLInSec int  `json:"leaseTtlInSec"`
+}
+
+// Check fails when archiving is enabled without a positive MaxAgeInDays, as
+// it would then archive every transaction, down to the ones just created.
+func (c ArchiveConf) Check() error {
+	if c.Enabled && c.MaxAgeInDays <= 0 {
+		return fmt.Errorf("archive maxAgeInDays must be positive, got %d", c.MaxAgeInDays)
+	}
+
+	return nil
+}
+
+// SigningConf sets up request signatures. Required makes every caller sign
+// its requests; OAuth clients and API keys can also require it one by one.
+type SigningConf struct {
+	Enabled        bool          `json:"enabled"`
+	Required       bool          `json:"required"`
+	ToleranceInSec int           `json:"toleranceInSec"`
+	MaxBodyBytes   int64         `json:"maxBodyBytes"`
+	Partners       []PartnerConf `json:"partners"`
+}
+
+type PartnerConf struct {
+	KeyID  string `json:"keyId"`
+	Secret string `json:"secret"`
+}
+
+// HasPartner tells whether requests can be signed with the given key id,
+// which takes signing to be enabled.
+func (c SigningConf) HasPartner(keyID string) bool {
+	if !c.Enabled {
+		return false
+	}
+
+	for _, partner := range c.Partners {
+		if partner.KeyID == keyID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type StepUpConf struct {
+	ThresholdAmount       float64 `json:"thresholdAmount"`
+	ValidityDurationInMin int     `json:"validityDurationInMin"`
+	MaxAttempts           int     `json:"maxAttempts"`
+	LockoutDurationInMin  int     `json:"lockoutDurationInMin"`
+	// FailureWindowInMin is how long incorrect codes count towards
+	// MaxAttempts across the challenges of a wallet, defaulting to the
+	// lockout duration.
+	FailureWindowInMin int `json:"failureWindowInMin"`
+}
+
+type RateLimitConf struct {
+	Enabled     bool                `json:"enabled"`
+	WindowInSec int                 `json:"windowInSec"`
+	Read        RateLimitBudgetConf `json:"read"`
+	Write       RateLimitBudgetConf `json:"write"`
+}
+
+// RateLimitBudgetConf sets the requests allowed per window, zero meaning no
+// limit.
+type RateLimitBudgetConf struct {
+	PerCaller int `json:"perCaller"`
+	PerWallet int `json:"perWallet"`
+}
+
 func Read(path string) (Conf, error) {
 	contentBytes, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,71 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+var badRequestErrors = []error{
+	ErrInvalidInterval,
+	ErrInvalidPeriod,
+}
+
+type AnalyticsService interface {
+	GetReport(ctx context.Context, walletID, interval string, from, to time.Time) (*Report, error)
+}
+
+type handler struct {
+	as AnalyticsService
+}
+
+func NewHandler(as AnalyticsService) *handler {
+	return &handler{as}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/wallets/:id/analytics", h.GetReport, auth.RequireScopes(auth.ScopeTransactionsRead))
+}
+
+func (h *handler) GetReport(c echo.Context) error {
+	loc, err := transaction.ParseLocation(c.QueryParam("tz"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = transaction.IntervalMonth
+	}
+
+	to := time.Now().In(loc)
+	if query := c.QueryParam("to"); query != "" {
+		if to, err = transaction.ParseTo(query, loc); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	from := to.AddDate(-1, 0, 0)
+	if query := c.QueryParam("from"); query != "" {
+		if from, err = transaction.ParseFrom(query, loc); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	report, err := h.as.GetReport(c.Request().Context(), c.Param("id"), interval, from, to)
+	if err != nil && wallet.ContainsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, wallet.ErrWalletNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
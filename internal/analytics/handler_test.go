@@ -0,0 +1,165 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/analytics"
+	"github.com/gokcelb/wallet-api/internal/analytics/mock"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/authtest"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockAnalyticsService(t *testing.T) *mock.MockAnalyticsService {
+	return mock.NewMockAnalyticsService(gomock.NewController(t))
+}
+
+func TestHandlerGetReport(t *testing.T) {
+	mockAnalyticsService := createMockAnalyticsService(t)
+	h := analytics.NewHandler(mockAnalyticsService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, istanbul)
+	to := time.Date(2022, time.April, 1, 0, 0, 0, 0, istanbul)
+	mockReport := &analytics.Report{
+		WalletID: "1",
+		Interval: transaction.IntervalWeek,
+		From:     from,
+		To:       to,
+		Buckets:  []*transaction.Bucket{{Period: from, Type: "deposit", Total: 100, Count: 1, Average: 100}},
+		Totals:   map[string]*analytics.Summary{"deposit": {Total: 100, Count: 1, Average: 100}},
+	}
+
+	testCases := []struct {
+		desc                       string
+		givenInterval              string
+		mockASReport               *analytics.Report
+		mockASErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "params are valid, return report",
+			givenInterval:              transaction.IntervalWeek,
+			mockASReport:               mockReport,
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       mockReport,
+		},
+		{
+			desc:                       "interval is not supported, return error",
+			givenInterval:              "year",
+			mockASErr:                  analytics.ErrInvalidInterval,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{analytics.ErrInvalidInterval.Error()},
+		},
+		{
+			desc:                       "wallet does not exist, return error",
+			givenInterval:              transaction.IntervalDay,
+			mockASErr:                  wallet.ErrWalletNotFound,
+			expectedResponseStatusCode: 404,
+			expectedResponseBody:       httpErr{wallet.ErrWalletNotFound.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAnalyticsService.EXPECT().
+				GetReport(gomock.Any(), "1", tC.givenInterval, from, to).
+				Return(tC.mockASReport, tC.mockASErr)
+
+			res, err := testServer.Client().Get(fmt.Sprintf(
+				"%s/wallets/1/analytics?interval=%s&from=2022-01-01&to=2022-03-31&tz=Europe/Istanbul",
+				testServer.URL,
+				tC.givenInterval,
+			))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerGetReportInvalidParams(t *testing.T) {
+	h := analytics.NewHandler(createMockAnalyticsService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                 string
+		givenQuery           string
+		expectedResponseBody interface{}
+	}{
+		{
+			desc:                 "invalid time zone, return error",
+			givenQuery:           "tz=Mars/Olympus",
+			expectedResponseBody: httpErr{transaction.ErrInvalidTimeZone.Error()},
+		},
+		{
+			desc:                 "invalid date, return error",
+			givenQuery:           "from=last-year",
+			expectedResponseBody: httpErr{transaction.ErrInvalidDate.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/analytics?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, 400, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerRequiresScope(t *testing.T) {
+	h := analytics.NewHandler(createMockAnalyticsService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsWrite))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(testServer.URL + "/wallets/1/analytics")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 403, res.StatusCode)
+}
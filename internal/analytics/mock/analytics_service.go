@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/analytics (interfaces: AnalyticsService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	analytics "github.com/gokcelb/wallet-api/internal/analytics"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAnalyticsService is a mock of AnalyticsService interface.
+type MockAnalyticsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsServiceMockRecorder
+}
+
+// MockAnalyticsServiceMockRecorder is the mock recorder for MockAnalyticsService.
+type MockAnalyticsServiceMockRecorder struct {
+	mock *MockAnalyticsService
+}
+
+// NewMockAnalyticsService creates a new mock instance.
+func NewMockAnalyticsService(ctrl *gomock.Controller) *MockAnalyticsService {
+	mock := &MockAnalyticsService{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsService) EXPECT() *MockAnalyticsServiceMockRecorder {
+	return m.recorder
+}
+
+// GetReport mocks base method.
+func (m *MockAnalyticsService) GetReport(arg0 context.Context, arg1, arg2 string, arg3, arg4 time.Time) (*analytics.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*analytics.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockAnalyticsServiceMockRecorder) GetReport(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockAnalyticsService)(nil).GetReport), arg0, arg1, arg2, arg3, arg4)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/analytics (interfaces: TransactionRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	transaction "github.com/gokcelb/wallet-api/internal/transaction"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransactionRepository is a mock of TransactionRepository interface.
+type MockTransactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionRepositoryMockRecorder
+}
+
+// MockTransactionRepositoryMockRecorder is the mock recorder for MockTransactionRepository.
+type MockTransactionRepositoryMockRecorder struct {
+	mock *MockTransactionRepository
+}
+
+// NewMockTransactionRepository creates a new mock instance.
+func NewMockTransactionRepository(ctrl *gomock.Controller) *MockTransactionRepository {
+	mock := &MockTransactionRepository{ctrl: ctrl}
+	mock.recorder = &MockTransactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AggregateByWalletID mocks base method.
+func (m *MockTransactionRepository) AggregateByWalletID(arg0 context.Context, arg1 string, arg2, arg3 time.Time, arg4 string, arg5 *time.Location) ([]*transaction.Bucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateByWalletID", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*transaction.Bucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AggregateByWalletID indicates an expected call of AggregateByWalletID.
+func (mr *MockTransactionRepositoryMockRecorder) AggregateByWalletID(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateByWalletID", reflect.TypeOf((*MockTransactionRepository)(nil).AggregateByWalletID), arg0, arg1, arg2, arg3, arg4, arg5)
+}
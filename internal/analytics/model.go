@@ -0,0 +1,22 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
+type Report struct {
+	WalletID string
+	Interval string
+	From     time.Time
+	To       time.Time
+	Buckets  []*transaction.Bucket
+	Totals   map[string]*Summary
+}
+
+type Summary struct {
+	Total   float64
+	Count   int64
+	Average float64
+}
@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+var (
+	ErrInvalidInterval = errors.New("interval must be day, week or month")
+	ErrInvalidPeriod   = errors.New("analytics period start must be before its end")
+)
+
+type WalletRepository interface {
+	Read(ctx context.Context, id string) (*wallet.Wallet, error)
+}
+
+type TransactionRepository interface {
+	AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*transaction.Bucket, error)
+}
+
+type service struct {
+	wr WalletRepository
+	tr TransactionRepository
+}
+
+func NewService(wr WalletRepository, tr TransactionRepository) *service {
+	return &service{wr, tr}
+}
+
+func (s *service) GetReport(ctx context.Context, walletID, interval string, from, to time.Time) (*Report, error) {
+	if interval != transaction.IntervalDay && interval != transaction.IntervalWeek && interval != transaction.IntervalMonth {
+		return nil, ErrInvalidInterval
+	}
+
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.wr.Read(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.tr.AggregateByWalletID(ctx, walletID, from, to, interval, from.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		WalletID: walletID,
+		Interval: interval,
+		From:     from,
+		To:       to,
+		Buckets:  buckets,
+		Totals:   map[string]*Summary{},
+	}
+
+	for _, bucket := range buckets {
+		summary, ok := report.Totals[bucket.Type]
+		if !ok {
+			summary = &Summary{}
+			report.Totals[bucket.Type] = summary
+		}
+		summary.Total += bucket.Total
+		summary.Count += bucket.Count
+	}
+
+	for _, summary := range report.Totals {
+		summary.Average = summary.Total / float64(summary.Count)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/analytics"
+	"github.com/gokcelb/wallet-api/internal/analytics/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockWalletRepository(t *testing.T) *mock.MockWalletRepository {
+	return mock.NewMockWalletRepository(gomock.NewController(t))
+}
+
+func createMockTransactionRepository(t *testing.T) *mock.MockTransactionRepository {
+	return mock.NewMockTransactionRepository(gomock.NewController(t))
+}
+
+func TestServiceGetReport(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := analytics.NewService(mockWalletRepository, mockTransactionRepository)
+
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	mockBuckets := []*transaction.Bucket{
+		{Period: from, Type: "deposit", Total: 300, Count: 2, Average: 150},
+		{Period: from, Type: "withdrawal", Total: 50, Count: 1, Average: 50},
+		{Period: from.AddDate(0, 1, 0), Type: "deposit", Total: 100, Count: 2, Average: 50},
+	}
+
+	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil)
+	mockTransactionRepository.EXPECT().
+		AggregateByWalletID(context.TODO(), "1", from, to, transaction.IntervalMonth, time.UTC).
+		Return(mockBuckets, nil)
+
+	report, err := s.GetReport(context.TODO(), "1", transaction.IntervalMonth, from, to)
+
+	assert.Nil(t, err)
+	assert.Equal(t, mockBuckets, report.Buckets)
+	assert.Equal(t, map[string]*analytics.Summary{
+		"deposit":    {Total: 400, Count: 4, Average: 100},
+		"withdrawal": {Total: 50, Count: 1, Average: 50},
+	}, report.Totals)
+}
+
+func TestServiceGetReportWithInvalidParams(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	s := analytics.NewService(mockWalletRepository, nil)
+
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		givenInterval string
+		givenTo       time.Time
+		mockRepoErr   error
+		expectedError error
+	}{
+		{
+			desc:          "interval is not supported, return error",
+			givenInterval: "year",
+			givenTo:       from.AddDate(0, 1, 0),
+			expectedError: analytics.ErrInvalidInterval,
+		},
+		{
+			desc:          "period end is before its start, return error",
+			givenInterval: transaction.IntervalDay,
+			givenTo:       from,
+			expectedError: analytics.ErrInvalidPeriod,
+		},
+		{
+			desc:          "wallet does not exist, return error",
+			givenInterval: transaction.IntervalWeek,
+			givenTo:       from.AddDate(0, 1, 0),
+			mockRepoErr:   wallet.ErrWalletNotFound,
+			expectedError: wallet.ErrWalletNotFound,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.mockRepoErr != nil {
+				mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, tC.mockRepoErr)
+			}
+
+			report, err := s.GetReport(context.TODO(), "1", tC.givenInterval, from, tC.givenTo)
+
+			assert.Nil(t, report)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
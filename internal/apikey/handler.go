@@ -0,0 +1,115 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+const Header = "X-API-Key"
+
+var badRequestErrors = []error{
+	ErrMissingName,
+	ErrMissingScopes,
+	ErrInvalidScope,
+	ErrUnknownSigningKeyID,
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, info *APIKeyCreationInfo) (*CreatedAPIKey, error)
+	GetAPIKeys(ctx context.Context) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, plainKey string) (*APIKey, error)
+}
+
+type handler struct {
+	as APIKeyService
+}
+
+func NewHandler(as APIKeyService) *handler {
+	return &handler{as}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	g := e.Group("/admin/api-keys", auth.RequireScopes(auth.ScopeAdmin))
+	g.POST("", h.CreateAPIKey)
+	g.GET("", h.GetAPIKeys)
+	g.DELETE("/:id", h.RevokeAPIKey)
+}
+
+func (h *handler) CreateAPIKey(c echo.Context) error {
+	var info APIKeyCreationInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	key, err := h.as.CreateAPIKey(c.Request().Context(), &info)
+	if err != nil && containsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusCreated, key)
+}
+
+func (h *handler) GetAPIKeys(c echo.Context) error {
+	keys, err := h.as.GetAPIKeys(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+func (h *handler) RevokeAPIKey(c echo.Context) error {
+	err := h.as.RevokeAPIKey(c.Request().Context(), c.Param("id"))
+	if err != nil && errors.Is(err, ErrAPIKeyNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Authenticate accepts requests carrying an API key in place of a JWT and
+// gives them the scopes and wallets of the key. Requests without a key are
+// left to the JWT middleware.
+func (h *handler) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		plainKey := c.Request().Header.Get(Header)
+		if plainKey == "" {
+			return next(c)
+		}
+
+		key, err := h.as.Authenticate(c.Request().Context(), plainKey)
+		if err != nil && errors.Is(err, ErrInvalidAPIKey) {
+			return echo.NewHTTPError(http.StatusUnauthorized, auth.TokenErrorResponse{Code: "invalid_api_key", Message: err.Error()})
+		} else if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		auth.SetClaims(c, &auth.Claims{Scopes: key.Scopes, WalletIDs: key.WalletIDs, SigningKeyID: key.SigningKeyID})
+		return next(c)
+	}
+}
+
+// HasKey tells the JWT middleware to skip requests authenticated by key.
+func HasKey(c echo.Context) bool {
+	return c.Request().Header.Get(Header) != ""
+}
+
+func containsError(err error, errList []error) bool {
+	for _, e := range errList {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package apikey_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"github.com/gokcelb/wallet-api/internal/apikey/mock"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message interface{} `json:"message"`
+}
+
+func createMockAPIKeyService(t *testing.T) *mock.MockAPIKeyService {
+	return mock.NewMockAPIKeyService(gomock.NewController(t))
+}
+
+func newTestServer(authenticate echo.MiddlewareFunc, register func(*echo.Echo)) *httptest.Server {
+	e := echo.New()
+	e.Use(authenticate)
+	register(e)
+	return httptest.NewServer(e.Server.Handler)
+}
+
+func TestHandlerCreateAPIKey(t *testing.T) {
+	mockAPIKeyService := createMockAPIKeyService(t)
+	h := apikey.NewHandler(mockAPIKeyService)
+	testServer := newTestServer(h.Authenticate, h.RegisterRoutes)
+	defer testServer.Close()
+
+	adminKey := &apikey.APIKey{ID: "admin", Scopes: []string{auth.ScopeAdmin}}
+	info := &apikey.APIKeyCreationInfo{Name: "payouts", Scopes: []string{auth.ScopeWalletsRead}}
+
+	testCases := []struct {
+		desc                       string
+		mockASResult               *apikey.CreatedAPIKey
+		mockASErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "info is valid, return created key",
+			mockASResult:               &apikey.CreatedAPIKey{APIKey: &apikey.APIKey{ID: "1", Name: "payouts"}, Key: "wk_key"},
+			expectedResponseStatusCode: 201,
+			expectedResponseBody:       &apikey.CreatedAPIKey{APIKey: &apikey.APIKey{ID: "1", Name: "payouts"}, Key: "wk_key"},
+		},
+		{
+			desc:                       "scope is invalid, return error",
+			mockASErr:                  apikey.ErrInvalidScope,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{apikey.ErrInvalidScope.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "wk_admin").Return(adminKey, nil)
+			mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), info).Return(tC.mockASResult, tC.mockASErr)
+
+			reqBody, _ := json.Marshal(info)
+			req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/admin/api-keys", testServer.URL), bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(apikey.Header, "wk_admin")
+
+			res, err := testServer.Client().Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerRevokeAPIKey(t *testing.T) {
+	mockAPIKeyService := createMockAPIKeyService(t)
+	h := apikey.NewHandler(mockAPIKeyService)
+	testServer := newTestServer(h.Authenticate, h.RegisterRoutes)
+	defer testServer.Close()
+
+	adminKey := &apikey.APIKey{ID: "admin", Scopes: []string{auth.ScopeAdmin}}
+
+	testCases := []struct {
+		desc                       string
+		mockASErr                  error
+		expectedResponseStatusCode int
+	}{
+		{
+			desc:                       "key exists, return no content",
+			expectedResponseStatusCode: 204,
+		},
+		{
+			desc:                       "key does not exist, return not found",
+			mockASErr:                  apikey.ErrAPIKeyNotFound,
+			expectedResponseStatusCode: 404,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "wk_admin").Return(adminKey, nil)
+			mockAPIKeyService.EXPECT().RevokeAPIKey(gomock.Any(), "1").Return(tC.mockASErr)
+
+			req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/admin/api-keys/1", testServer.URL), nil)
+			req.Header.Set(apikey.Header, "wk_admin")
+
+			res, err := testServer.Client().Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestHandlerAuthenticate(t *testing.T) {
+	mockAPIKeyService := createMockAPIKeyService(t)
+	h := apikey.NewHandler(mockAPIKeyService)
+	testServer := newTestServer(h.Authenticate, func(e *echo.Echo) {
+		e.GET("/wallets", func(c echo.Context) error {
+			claims, ok := auth.GetClaims(c)
+			if !ok {
+				return c.NoContent(http.StatusTeapot)
+			}
+			return c.JSON(http.StatusOK, claims)
+		}, auth.RequireScopes(auth.ScopeWalletsRead))
+	})
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenKey                   string
+		mockASResult               *apikey.APIKey
+		mockASErr                  error
+		expectedResponseStatusCode int
+	}{
+		{
+			desc:                       "key has the scope, return ok",
+			givenKey:                   "wk_key",
+			mockASResult:               &apikey.APIKey{ID: "1", Scopes: []string{auth.ScopeWalletsRead}, WalletIDs: []string{"1"}},
+			expectedResponseStatusCode: 200,
+		},
+		{
+			desc:                       "key lacks the scope, return forbidden",
+			givenKey:                   "wk_key",
+			mockASResult:               &apikey.APIKey{ID: "1", Scopes: []string{auth.ScopeWalletsWrite}},
+			expectedResponseStatusCode: 403,
+		},
+		{
+			desc:                       "key is invalid, return unauthorized",
+			givenKey:                   "wk_key",
+			mockASErr:                  apikey.ErrInvalidAPIKey,
+			expectedResponseStatusCode: 401,
+		},
+		{
+			desc:                       "key is missing, leave it to the next middleware",
+			expectedResponseStatusCode: 401,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.givenKey != "" {
+				mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), tC.givenKey).Return(tC.mockASResult, tC.mockASErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/wallets", testServer.URL), nil)
+			if tC.givenKey != "" {
+				req.Header.Set(apikey.Header, tC.givenKey)
+			}
+
+			res, err := testServer.Client().Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			if res.StatusCode == http.StatusOK {
+				var claims auth.Claims
+				_ = json.NewDecoder(res.Body).Decode(&claims)
+				assert.Equal(t, tC.mockASResult.Scopes, claims.Scopes)
+				assert.Equal(t, tC.mockASResult.WalletIDs, claims.WalletIDs)
+			}
+		})
+	}
+}
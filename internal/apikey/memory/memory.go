@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Memory struct {
+	mu   sync.RWMutex
+	keys map[string]*apikey.APIKey
+}
+
+func NewMemory() *Memory {
+	return &Memory{keys: make(map[string]*apikey.APIKey)}
+}
+
+func (m *Memory) Create(ctx context.Context, key *apikey.APIKey) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *key
+	stored.ID = primitive.NewObjectID().Hex()
+	m.keys[stored.ID] = &stored
+
+	return stored.ID, nil
+}
+
+func (m *Memory) ReadByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			found := *key
+			return &found, nil
+		}
+	}
+
+	return nil, apikey.ErrAPIKeyNotFound
+}
+
+func (m *Memory) ReadAll(ctx context.Context) ([]*apikey.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []*apikey.APIKey{}
+	for _, key := range m.keys {
+		found := *key
+		keys = append(keys, &found)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (m *Memory) Revoke(ctx context.Context, id string) error {
+	return m.update(id, func(key *apikey.APIKey) { key.Revoked = true })
+}
+
+func (m *Memory) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	return m.update(id, func(key *apikey.APIKey) { key.LastUsedAt = &at })
+}
+
+func (m *Memory) update(id string, fn func(*apikey.APIKey)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return apikey.ErrAPIKeyNotFound
+	}
+	fn(key)
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package apikey
+
+import "time"
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	WalletIDs  []string   `json:"walletIds,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Revoked    bool       `json:"revoked"`
+	// SigningKeyID, when set, rejects requests made with the key unless they
+	// are signed with the partner key of that id.
+	SigningKeyID string `json:"signingKeyId,omitempty"`
+}
+
+// CreatedAPIKey carries the plain key, which is only ever shown on creation.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyCreationInfo struct {
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	WalletIDs    []string `json:"walletIds"`
+	SigningKeyID string   `json:"signingKeyId"`
+}
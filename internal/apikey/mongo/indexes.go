@@ -0,0 +1,18 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var indexes = []mongo.IndexModel{
+	{Keys: bson.D{bson.E{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+func (m *Mongo) CreateIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoAPIKey struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"name"`
+	Prefix     string             `bson:"prefix"`
+	Hash       string             `bson:"hash"`
+	Scopes     []string           `bson:"scopes"`
+	WalletIDs  []string           `bson:"wallet_ids"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	LastUsedAt *time.Time         `bson:"last_used_at"`
+	Revoked    bool               `bson:"revoked"`
+	// SigningKeyID is empty on keys created before it was added.
+	SigningKeyID string `bson:"signing_key_id,omitempty"`
+}
@@ -0,0 +1,124 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Create(ctx context.Context, key *apikey.APIKey) (string, error) {
+	mongoAPIKey := newMongoAPIKeyFromAPIKey(key)
+	_, err := m.collection.InsertOne(ctx, mongoAPIKey)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return mongoAPIKey.ID.Hex(), nil
+}
+
+func (m *Mongo) ReadByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	var mongoAPIKey mongoAPIKey
+	err := m.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&mongoAPIKey)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, apikey.ErrAPIKeyNotFound
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newAPIKeyFromMongoAPIKey(&mongoAPIKey), nil
+}
+
+func (m *Mongo) ReadAll(ctx context.Context) ([]*apikey.APIKey, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoAPIKeys []mongoAPIKey
+	if err = cursor.All(ctx, &mongoAPIKeys); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	keys := []*apikey.APIKey{}
+	for _, mongoAPIKey := range mongoAPIKeys {
+		keys = append(keys, newAPIKeyFromMongoAPIKey(&mongoAPIKey))
+	}
+
+	return keys, nil
+}
+
+func (m *Mongo) Revoke(ctx context.Context, id string) error {
+	return m.update(ctx, id, bson.M{"revoked": true})
+}
+
+func (m *Mongo) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	return m.update(ctx, id, bson.M{"last_used_at": at})
+}
+
+func (m *Mongo) update(ctx context.Context, id string, fields bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apikey.ErrAPIKeyNotFound
+	}
+
+	result, err := m.collection.UpdateByID(ctx, objectID, bson.M{"$set": fields})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return apikey.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func newMongoAPIKeyFromAPIKey(key *apikey.APIKey) *mongoAPIKey {
+	return &mongoAPIKey{
+		ID:           primitive.NewObjectID(),
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		Hash:         key.Hash,
+		Scopes:       key.Scopes,
+		WalletIDs:    key.WalletIDs,
+		CreatedAt:    key.CreatedAt,
+		LastUsedAt:   key.LastUsedAt,
+		Revoked:      key.Revoked,
+		SigningKeyID: key.SigningKeyID,
+	}
+}
+
+func newAPIKeyFromMongoAPIKey(mongoAPIKey *mongoAPIKey) *apikey.APIKey {
+	return &apikey.APIKey{
+		ID:           mongoAPIKey.ID.Hex(),
+		Name:         mongoAPIKey.Name,
+		Prefix:       mongoAPIKey.Prefix,
+		Hash:         mongoAPIKey.Hash,
+		Scopes:       mongoAPIKey.Scopes,
+		WalletIDs:    mongoAPIKey.WalletIDs,
+		CreatedAt:    mongoAPIKey.CreatedAt,
+		LastUsedAt:   mongoAPIKey.LastUsedAt,
+		Revoked:      mongoAPIKey.Revoked,
+		SigningKeyID: mongoAPIKey.SigningKeyID,
+	}
+}
@@ -0,0 +1,131 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+)
+
+const (
+	keyPrefix = "wk_"
+	// lastUsedPrecision limits how often a busy key writes its last use.
+	lastUsedPrecision = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("no api key with the given id exists")
+	ErrMissingName    = errors.New("api key name is required")
+	ErrMissingScopes  = errors.New("api key needs at least one scope")
+	ErrInvalidScope   = errors.New("api key scope is invalid")
+	ErrInvalidAPIKey  = errors.New("api key is invalid or revoked")
+	// ErrUnknownSigningKeyID is returned for signing key ids of no
+	// configured partner, including all of them while signing is disabled.
+	ErrUnknownSigningKeyID = errors.New("signing key id is not a configured partner key")
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) (string, error)
+	ReadByHash(ctx context.Context, hash string) (*APIKey, error)
+	ReadAll(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	UpdateLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+type service struct {
+	ar      APIKeyRepository
+	signing config.SigningConf
+}
+
+func NewService(ar APIKeyRepository, signing config.SigningConf) *service {
+	return &service{ar, signing}
+}
+
+func (s *service) CreateAPIKey(ctx context.Context, info *APIKeyCreationInfo) (*CreatedAPIKey, error) {
+	if strings.TrimSpace(info.Name) == "" {
+		return nil, ErrMissingName
+	}
+
+	if len(info.Scopes) == 0 {
+		return nil, ErrMissingScopes
+	}
+
+	for _, scope := range info.Scopes {
+		if !auth.IsDelegable(scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	if info.SigningKeyID != "" && !s.signing.HasPartner(info.SigningKeyID) {
+		return nil, ErrUnknownSigningKeyID
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	plainKey := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &APIKey{
+		Name:         info.Name,
+		Prefix:       plainKey[:len(keyPrefix)+6],
+		Hash:         hashKey(plainKey),
+		Scopes:       info.Scopes,
+		WalletIDs:    info.WalletIDs,
+		CreatedAt:    time.Now(),
+		SigningKeyID: info.SigningKeyID,
+	}
+
+	id, err := s.ar.Create(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = id
+
+	return &CreatedAPIKey{key, plainKey}, nil
+}
+
+func (s *service) GetAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	return s.ar.ReadAll(ctx)
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, id string) error {
+	return s.ar.Revoke(ctx, id)
+}
+
+func (s *service) Authenticate(ctx context.Context, plainKey string) (*APIKey, error) {
+	key, err := s.ar.ReadByHash(ctx, hashKey(plainKey))
+	if err != nil && errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	if key.Revoked {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		if err = s.ar.UpdateLastUsed(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// hashKey does not need a slow hash since keys are random and long enough
+// not to be guessed.
+func hashKey(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
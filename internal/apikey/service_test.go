@@ -0,0 +1,167 @@
+package apikey_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"github.com/gokcelb/wallet-api/internal/apikey/mock"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockAPIKeyRepository(t *testing.T) *mock.MockAPIKeyRepository {
+	return mock.NewMockAPIKeyRepository(gomock.NewController(t))
+}
+
+func getSigningConf() config.SigningConf {
+	return config.SigningConf{Enabled: true, Partners: []config.PartnerConf{{KeyID: "partner", Secret: "secret"}}}
+}
+
+func hash(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestServiceCreateAPIKey(t *testing.T) {
+	mockAPIKeyRepository := createMockAPIKeyRepository(t)
+	s := apikey.NewService(mockAPIKeyRepository, getSigningConf())
+
+	info := &apikey.APIKeyCreationInfo{
+		Name:         "payouts",
+		Scopes:       []string{auth.ScopeWalletsRead, auth.ScopeTransactionsWrite},
+		WalletIDs:    []string{"1"},
+		SigningKeyID: "partner",
+	}
+
+	var stored *apikey.APIKey
+	mockAPIKeyRepository.EXPECT().
+		Create(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key *apikey.APIKey) (string, error) {
+			stored = key
+			return "1", nil
+		})
+
+	key, err := s.CreateAPIKey(context.TODO(), info)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", key.ID)
+	assert.True(t, strings.HasPrefix(key.Key, "wk_"))
+	assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+	assert.Equal(t, hash(key.Key), stored.Hash)
+	assert.NotContains(t, stored.Hash, key.Key)
+	assert.Equal(t, info.Scopes, stored.Scopes)
+	assert.Equal(t, info.WalletIDs, stored.WalletIDs)
+	assert.Equal(t, info.SigningKeyID, stored.SigningKeyID)
+}
+
+func TestServiceCreateAPIKeyInvalid(t *testing.T) {
+	s := apikey.NewService(createMockAPIKeyRepository(t), getSigningConf())
+
+	testCases := []struct {
+		desc        string
+		givenInfo   *apikey.APIKeyCreationInfo
+		expectedErr error
+	}{
+		{
+			desc:        "name is missing, return error",
+			givenInfo:   &apikey.APIKeyCreationInfo{Scopes: []string{auth.ScopeWalletsRead}},
+			expectedErr: apikey.ErrMissingName,
+		},
+		{
+			desc:        "scopes are missing, return error",
+			givenInfo:   &apikey.APIKeyCreationInfo{Name: "payouts"},
+			expectedErr: apikey.ErrMissingScopes,
+		},
+		{
+			desc:        "scope is unknown, return error",
+			givenInfo:   &apikey.APIKeyCreationInfo{Name: "payouts", Scopes: []string{"wallets:delete"}},
+			expectedErr: apikey.ErrInvalidScope,
+		},
+		{
+			desc:        "scope is admin, return error",
+			givenInfo:   &apikey.APIKeyCreationInfo{Name: "payouts", Scopes: []string{auth.ScopeAdmin}},
+			expectedErr: apikey.ErrInvalidScope,
+		},
+		{
+			desc:        "signing key id is unknown, return error",
+			givenInfo:   &apikey.APIKeyCreationInfo{Name: "payouts", Scopes: []string{auth.ScopeWalletsRead}, SigningKeyID: "unknown"},
+			expectedErr: apikey.ErrUnknownSigningKeyID,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			key, err := s.CreateAPIKey(context.TODO(), tC.givenInfo)
+
+			assert.Nil(t, key)
+			assert.ErrorIs(t, err, tC.expectedErr)
+		})
+	}
+}
+
+func TestServiceAuthenticate(t *testing.T) {
+	mockAPIKeyRepository := createMockAPIKeyRepository(t)
+	s := apikey.NewService(mockAPIKeyRepository, getSigningConf())
+
+	recently := time.Now().Add(-time.Second)
+	longAgo := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		desc               string
+		mockARResult       *apikey.APIKey
+		mockARErr          error
+		expectLastUsedSave bool
+		expectedErr        error
+	}{
+		{
+			desc:               "key is valid and never used, save last use",
+			mockARResult:       &apikey.APIKey{ID: "1"},
+			expectLastUsedSave: true,
+		},
+		{
+			desc:               "key was used long ago, save last use",
+			mockARResult:       &apikey.APIKey{ID: "1", LastUsedAt: &longAgo},
+			expectLastUsedSave: true,
+		},
+		{
+			desc:         "key was used recently, do not save last use",
+			mockARResult: &apikey.APIKey{ID: "1", LastUsedAt: &recently},
+		},
+		{
+			desc:         "key is revoked, return error",
+			mockARResult: &apikey.APIKey{ID: "1", Revoked: true},
+			expectedErr:  apikey.ErrInvalidAPIKey,
+		},
+		{
+			desc:        "key is unknown, return error",
+			mockARErr:   apikey.ErrAPIKeyNotFound,
+			expectedErr: apikey.ErrInvalidAPIKey,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAPIKeyRepository.EXPECT().
+				ReadByHash(context.TODO(), hash("wk_key")).
+				Return(tC.mockARResult, tC.mockARErr)
+
+			if tC.expectLastUsedSave {
+				mockAPIKeyRepository.EXPECT().
+					UpdateLastUsed(context.TODO(), "1", gomock.Any()).
+					Return(nil)
+			}
+
+			key, err := s.Authenticate(context.TODO(), "wk_key")
+
+			assert.ErrorIs(t, err, tC.expectedErr)
+			if tC.expectedErr == nil {
+				assert.Equal(t, "1", key.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package archive_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/archive"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/transaction/memory"
+	"github.com/gokcelb/wallet-api/internal/transaction/transactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	transactiontest.TestRepository(t, func(t *testing.T) transaction.TransactionRepository {
+		return archive.NewRepository(memory.NewMemory(), memory.NewMemory())
+	})
+}
+
+// createArchived creates the given types of transactions in order, the first
+// archivedCount of them archived, and returns their ids.
+func createArchived(t *testing.T, archivedCount int, types ...string) (*memory.Memory, *memory.Memory, []string) {
+	live, archived := memory.NewMemory(), memory.NewMemory()
+
+	ids := []string{}
+	var cutoff time.Time
+	for i, txnType := range types {
+		if i == archivedCount {
+			cutoff = sleepPast()
+		}
+		id, err := live.Create(context.TODO(), &transaction.Transaction{WalletID: "w1", Type: txnType, Amount: 10})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	if archivedCount == len(types) {
+		cutoff = sleepPast()
+	}
+
+	n, err := archive.NewArchiver(live, archived, archive.NewLocalLease(), config.ArchiveConf{BatchSize: 2}).Archive(context.TODO(), cutoff)
+	require.NoError(t, err)
+	require.Equal(t, archivedCount, n)
+
+	return live, archived, ids
+}
+
+func sleepPast() time.Time {
+	time.Sleep(2 * time.Millisecond)
+	defer time.Sleep(2 * time.Millisecond)
+
+	return time.Now()
+}
+
+func idsOf(txns []*transaction.Transaction) []string {
+	ids := []string{}
+	for _, txn := range txns {
+		ids = append(ids, txn.ID)
+	}
+
+	return ids
+}
+
+func TestArchiverArchive(t *testing.T) {
+	live, archived, ids := createArchived(t, 3, "deposit", "deposit", "withdrawal", "deposit", "deposit")
+
+	liveTxns, err := live.ReadByWalletID(context.TODO(), "w1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ids[4], ids[3]}, idsOf(liveTxns))
+
+	archivedTxns, err := archived.ReadByWalletID(context.TODO(), "w1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ids[2], ids[1], ids[0]}, idsOf(archivedTxns))
+
+	n, err := archive.NewArchiver(live, archived, archive.NewLocalLease(), config.ArchiveConf{}).Archive(context.TODO(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+type heldLease struct{}
+
+func (heldLease) Acquire(ctx context.Context, ttl time.Duration) error {
+	return archive.ErrLeaseHeld
+}
+
+func TestArchiverArchiveWithoutLease(t *testing.T) {
+	live, archived, _ := createArchived(t, 0, "deposit", "deposit")
+
+	n, err := archive.NewArchiver(live, archived, heldLease{}, config.ArchiveConf{}).Archive(context.TODO(), time.Now())
+
+	assert.ErrorIs(t, err, archive.ErrLeaseHeld)
+	assert.Equal(t, 0, n)
+
+	count, err := live.CountByWalletID(context.TODO(), "w1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRepositoryReadsPagesAcrossArchive(t *testing.T) {
+	live, archived, ids := createArchived(t, 3, "deposit", "deposit", "withdrawal", "deposit", "deposit")
+	r := archive.NewRepository(live, archived)
+
+	testCases := []struct {
+		pageSize int
+		expected [][]string
+	}{
+		{2, [][]string{{ids[4], ids[3]}, {ids[2], ids[1]}, {ids[0]}, {}}},
+		{3, [][]string{{ids[4], ids[3], ids[2]}, {ids[1], ids[0]}, {}}},
+		{1, [][]string{{ids[4]}, {ids[3]}, {ids[2]}, {ids[1]}, {ids[0]}, {}}},
+	}
+
+	for _, tc := range testCases {
+		for pageNo, expected := range tc.expected {
+			txns, err := r.ReadByWalletID(context.TODO(), "w1", pageNo, tc.pageSize)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, idsOf(txns), "page %d of size %d", pageNo, tc.pageSize)
+		}
+	}
+
+	txns, err := r.ReadByWalletIDFilterByType(context.TODO(), "w1", "deposit", 1, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[1], ids[0]}, idsOf(txns))
+}
+
+func TestRepositoryReadsPagesWhileArchiving(t *testing.T) {
+	live, archived, ids := createArchived(t, 1, "deposit", "deposit", "deposit")
+	r := archive.NewRepository(live, archived)
+
+	// The oldest live transaction is copied to the archive but not deleted
+	// yet.
+	oldest, err := live.Read(context.TODO(), ids[1])
+	require.NoError(t, err)
+	require.NoError(t, archived.Insert(context.TODO(), []*transaction.Transaction{oldest}))
+
+	txns, err := r.ReadByWalletID(context.TODO(), "w1", 0, 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[2], ids[1], ids[0]}, idsOf(txns))
+}
+
+func TestRepositoryMergesWhileArchiving(t *testing.T) {
+	live, archived, ids := createArchived(t, 1, "deposit", "withdrawal", "deposit")
+	r := archive.NewRepository(live, archived)
+
+	// The oldest live transaction is copied to the archive but not deleted
+	// yet.
+	oldest, err := live.Read(context.TODO(), ids[1])
+	require.NoError(t, err)
+	require.NoError(t, archived.Insert(context.TODO(), []*transaction.Transaction{oldest}))
+
+	txns, err := r.ReadByWalletIDBetween(context.TODO(), "w1", time.Time{}, time.Now())
+
+	assert.Nil(t, err)
+	assert.Equal(t, ids, idsOf(txns))
+
+	streamed := []string{}
+	err = r.StreamByWalletIDBetween(context.TODO(), "w1", time.Time{}, time.Now(), func(txn *transaction.Transaction) error {
+		streamed = append(streamed, txn.ID)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, ids, streamed)
+
+	buckets, err := r.AggregateByWalletID(context.TODO(), "w1", time.Time{}, time.Now(), transaction.IntervalMonth, time.UTC)
+
+	assert.Nil(t, err)
+	if assert.Len(t, buckets, 2) {
+		assert.Equal(t, "deposit", buckets[0].Type)
+		assert.Equal(t, int64(2), buckets[0].Count)
+		assert.Equal(t, "withdrawal", buckets[1].Type)
+		assert.Equal(t, int64(1), buckets[1].Count)
+		assert.Equal(t, 10.0, buckets[1].Total)
+	}
+
+	testCases := []struct {
+		typeFilter string
+		limit      int
+		expected   int64
+	}{
+		{"", 0, 3},
+		{"", 2, 2},
+		{"", 3, 3},
+		{"withdrawal", 0, 1},
+		{"deposit", 0, 2},
+	}
+
+	for _, tc := range testCases {
+		var count int64
+		if tc.typeFilter == "" {
+			count, err = r.CountByWalletID(context.TODO(), "w1", tc.limit)
+		} else {
+			count, err = r.CountByWalletIDFilterByType(context.TODO(), "w1", tc.typeFilter, tc.limit)
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, count, "%q limited to %d", tc.typeFilter, tc.limit)
+	}
+}
+
+func TestRepositoryCountsAcrossArchive(t *testing.T) {
+	live, archived, _ := createArchived(t, 3, "deposit", "deposit", "withdrawal", "deposit", "deposit")
+	r := archive.NewRepository(live, archived)
+
+	testCases := []struct {
+		typeFilter string
+		limit      int
+		expected   int64
+	}{
+		{"", 0, 5},
+		{"", 1, 1},
+		{"", 3, 3},
+		{"", 4, 4},
+		{"deposit", 0, 4},
+		{"withdrawal", 0, 1},
+	}
+
+	for _, tc := range testCases {
+		var count int64
+		var err error
+		if tc.typeFilter == "" {
+			count, err = r.CountByWalletID(context.TODO(), "w1", tc.limit)
+		} else {
+			count, err = r.CountByWalletIDFilterByType(context.TODO(), "w1", tc.typeFilter, tc.limit)
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, count, "%q limited to %d", tc.typeFilter, tc.limit)
+	}
+}
+
+func TestRepositoryReadsArchivedTransaction(t *testing.T) {
+	live, archived, ids := createArchived(t, 1, "deposit", "deposit")
+	r := archive.NewRepository(live, archived)
+
+	txn, err := r.Read(context.TODO(), ids[0])
+
+	assert.Nil(t, err)
+	assert.Equal(t, ids[0], txn.ID)
+
+	txns, err := r.ReadByWalletIDBetween(context.TODO(), "w1", time.Time{}, time.Now())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[0], ids[1]}, idsOf(txns))
+
+	streamed := []string{}
+	err = r.StreamByWalletIDBetween(context.TODO(), "w1", time.Time{}, time.Now(), func(txn *transaction.Transaction) error {
+		streamed = append(streamed, txn.ID)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[0], ids[1]}, streamed)
+
+	last, err := r.ReadLastByWalletIDBefore(context.TODO(), "w1", txn.CreatedAt.Add(time.Millisecond))
+
+	assert.Nil(t, err)
+	assert.Equal(t, ids[0], last.ID)
+
+	buckets, err := r.AggregateByWalletID(context.TODO(), "w1", time.Time{}, time.Now(), transaction.IntervalMonth, time.UTC)
+
+	assert.Nil(t, err)
+	if assert.Len(t, buckets, 1) {
+		assert.Equal(t, int64(2), buckets[0].Count)
+		assert.Equal(t, 20.0, buckets[0].Total)
+		assert.Equal(t, 10.0, buckets[0].Average)
+	}
+}
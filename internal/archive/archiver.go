@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/labstack/gommon/log"
+)
+
+// Store is a transaction repository that transactions can be moved out of
+// and into, which both the live and the archived transactions are kept in.
+type Store interface {
+	transaction.TransactionRepository
+	// ReadBefore returns the oldest transactions created before the given
+	// time.
+	ReadBefore(ctx context.Context, before time.Time, limit int) ([]*transaction.Transaction, error)
+	DeleteByIDs(ctx context.Context, ids []string) error
+	// Insert keeps the ids and creation times of the transactions, skipping
+	// the ones that are there already.
+	Insert(ctx context.Context, txns []*transaction.Transaction) error
+}
+
+const defaultBatchSize = 1000
+
+type archiver struct {
+	live    Store
+	archive Store
+	lease   Lease
+	conf    config.ArchiveConf
+}
+
+func NewArchiver(live, archive Store, lease Lease, conf config.ArchiveConf) *archiver {
+	return &archiver{live, archive, lease, conf}
+}
+
+// Archive moves the transactions created before the given time to the
+// archive in batches, copying each batch before deleting it, so that an
+// interrupted run loses nothing and the next one picks up where it stopped.
+// The lease is taken or extended before every batch, and archiving stops
+// with ErrLeaseHeld when another instance holds it.
+func (a *archiver) Archive(ctx context.Context, before time.Time) (int, error) {
+	batchSize := a.batchSize()
+
+	archived := 0
+	for {
+		if err := a.lease.Acquire(ctx, a.leaseTTL()); err != nil {
+			return archived, err
+		}
+
+		txns, err := a.live.ReadBefore(ctx, before, batchSize)
+		if err != nil || len(txns) == 0 {
+			return archived, err
+		}
+
+		if err = a.archive.Insert(ctx, txns); err != nil {
+			return archived, err
+		}
+
+		ids := []string{}
+		for _, txn := range txns {
+			ids = append(ids, txn.ID)
+		}
+
+		if err = a.live.DeleteByIDs(ctx, ids); err != nil {
+			return archived, err
+		}
+		archived += len(txns)
+
+		if len(txns) < batchSize {
+			return archived, nil
+		}
+	}
+}
+
+// Run archives the transactions older than the configured age on every tick
+// until ctx is done.
+func (a *archiver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		before := time.Now().AddDate(0, 0, -a.conf.MaxAgeInDays)
+		archived, err := a.Archive(ctx, before)
+		if err != nil && !errors.Is(err, ErrLeaseHeld) {
+			log.Error(err)
+		}
+		if archived > 0 {
+			log.Infof("archived %d transactions created before %s", archived, before.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *archiver) batchSize() int {
+	if a.conf.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+
+	return a.conf.BatchSize
+}
+
+func (a *archiver) leaseTTL() time.Duration {
+	if a.conf.LeaseTTLInSec <= 0 {
+		return time.Minute
+	}
+
+	return time.Second * time.Duration(a.conf.LeaseTTLInSec)
+}
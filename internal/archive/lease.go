@@ -0,0 +1,28 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrLeaseHeld = errors.New("archiving is leased by another instance")
+
+// Lease keeps the instances sharing a database from archiving at the same
+// time.
+type Lease interface {
+	// Acquire takes the lease for ttl, or extends it when the instance holds
+	// it already, returning ErrLeaseHeld while another instance holds it.
+	Acquire(ctx context.Context, ttl time.Duration) error
+}
+
+// localLease is always free, for databases that only one instance uses.
+type localLease struct{}
+
+func NewLocalLease() *localLease {
+	return &localLease{}
+}
+
+func (l *localLease) Acquire(ctx context.Context, ttl time.Duration) error {
+	return nil
+}
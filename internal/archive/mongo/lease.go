@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/archive"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// leaseID is the id of the lease document of the archiver.
+const leaseID = "archive"
+
+// Lease works like the migration lock, a document naming its owner until the
+// lease expires.
+type Lease struct {
+	collection *mongo.Collection
+	owner      string
+}
+
+func NewLease(collection *mongo.Collection) *Lease {
+	return &Lease{collection, primitive.NewObjectID().Hex()}
+}
+
+// Acquire takes the lease when nobody holds it, its owner is this instance or
+// it expired. When someone else holds it the upsert runs into the existing
+// lease document instead.
+func (l *Lease) Acquire(ctx context.Context, ttl time.Duration) error {
+	now := time.Now()
+	filter := bson.M{"_id": leaseID, "$or": bson.A{
+		bson.M{"owner": l.owner},
+		bson.M{"expires_at": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{"owner": l.owner, "expires_at": now.Add(ttl)}}
+
+	_, err := l.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return archive.ErrLeaseHeld
+	} else if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
@@ -0,0 +1,393 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
+// repository reads the live transactions first and falls back to the archived
+// ones, which are all older, when it runs out. A transaction that is being
+// archived can show up in both for a moment, which every read that merges the
+// two accounts for.
+type repository struct {
+	live    transaction.TransactionRepository
+	archive transaction.TransactionRepository
+}
+
+func NewRepository(live, archive transaction.TransactionRepository) *repository {
+	return &repository{live, archive}
+}
+
+func (r *repository) Create(ctx context.Context, txn *transaction.Transaction) (string, error) {
+	return r.live.Create(ctx, txn)
+}
+
+func (r *repository) Read(ctx context.Context, id string) (*transaction.Transaction, error) {
+	txn, err := r.live.Read(ctx, id)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		return r.archive.Read(ctx, id)
+	}
+
+	return txn, err
+}
+
+func (r *repository) ReadByWalletID(ctx context.Context, walletID string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	return r.readPage(ctx, walletID, "", pageNo, pageSize)
+}
+
+func (r *repository) ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	return r.readPage(ctx, walletID, typeFilter, pageNo, pageSize)
+}
+
+func (r *repository) CountByWalletID(ctx context.Context, walletID string, limit int) (int64, error) {
+	return r.count(ctx, walletID, "", limit)
+}
+
+func (r *repository) CountByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
+	return r.count(ctx, walletID, typeFilter, limit)
+}
+
+func (r *repository) ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error) {
+	archived, err := r.archive.ReadByWalletIDBetween(ctx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := r.live.ReadByWalletIDBetween(ctx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	archivedIDs := make(map[string]bool, len(archived))
+	for _, txn := range archived {
+		archivedIDs[txn.ID] = true
+	}
+
+	txns := archived
+	for _, txn := range live {
+		if !archivedIDs[txn.ID] {
+			txns = append(txns, txn)
+		}
+	}
+
+	sort.SliceStable(txns, func(i, j int) bool {
+		if !txns[i].CreatedAt.Equal(txns[j].CreatedAt) {
+			return txns[i].CreatedAt.Before(txns[j].CreatedAt)
+		}
+		return txns[i].ID < txns[j].ID
+	})
+
+	return txns, nil
+}
+
+func (r *repository) StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*transaction.Transaction) error) error {
+	duplicates, err := r.duplicates(ctx, walletID, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err = r.archive.StreamByWalletIDBetween(ctx, walletID, from, to, fn); err != nil {
+		return err
+	}
+
+	duplicateIDs := make(map[string]bool, len(duplicates))
+	for _, txn := range duplicates {
+		duplicateIDs[txn.ID] = true
+	}
+
+	return r.live.StreamByWalletIDBetween(ctx, walletID, from, to, func(txn *transaction.Transaction) error {
+		if duplicateIDs[txn.ID] {
+			return nil
+		}
+		return fn(txn)
+	})
+}
+
+func (r *repository) ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error) {
+	txn, err := r.live.ReadLastByWalletIDBefore(ctx, walletID, before)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		return r.archive.ReadLastByWalletIDBefore(ctx, walletID, before)
+	}
+
+	return txn, err
+}
+
+func (r *repository) AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*transaction.Bucket, error) {
+	archived, err := r.archive.AggregateByWalletID(ctx, walletID, from, to, interval, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := r.live.AggregateByWalletID(ctx, walletID, from, to, interval, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates, err := r.duplicates(ctx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return subtractBuckets(mergeBuckets(append(archived, live...)), transaction.Aggregate(duplicates, interval, loc)), nil
+}
+
+func (r *repository) DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error) {
+	archived, err := r.archive.DistinctWalletIDsBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := r.live.DistinctWalletIDsBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	walletIDs := []string{}
+	for _, walletID := range append(live, archived...) {
+		if !seen[walletID] {
+			seen[walletID] = true
+			walletIDs = append(walletIDs, walletID)
+		}
+	}
+
+	return walletIDs, nil
+}
+
+// readPage continues a page the live transactions don't fill with the newest
+// archived ones, skipping the archived ones earlier pages showed. The live
+// transactions are counted before they are read, so that the ones archived
+// in between shift the archived part of the page back rather than out of it,
+// and the ones that then show up twice are dropped.
+func (r *repository) readPage(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	liveCount, err := count(ctx, r.live, walletID, typeFilter, (pageNo+1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := readPage(ctx, r.live, walletID, typeFilter, pageNo, pageSize)
+	if err != nil || len(txns) == pageSize {
+		return txns, err
+	}
+
+	offset := pageNo*pageSize - int(liveCount)
+	if offset < 0 {
+		offset = 0
+	}
+
+	archived, err := r.readArchived(ctx, walletID, typeFilter, offset, pageSize-len(txns), pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(txns))
+	for _, txn := range txns {
+		seen[txn.ID] = true
+	}
+
+	for _, txn := range archived {
+		if !seen[txn.ID] {
+			txns = append(txns, txn)
+		}
+	}
+
+	return txns, nil
+}
+
+// readArchived reads up to limit archived transactions from offset on, with
+// the page based reads of the repository. As limit is at most pageSize, they
+// are on two pages at most.
+func (r *repository) readArchived(ctx context.Context, walletID, typeFilter string, offset, limit, pageSize int) ([]*transaction.Transaction, error) {
+	pageNo, start := offset/pageSize, offset%pageSize
+
+	txns, err := readPage(ctx, r.archive, walletID, typeFilter, pageNo, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if start > 0 && len(txns) == pageSize {
+		next, err := readPage(ctx, r.archive, walletID, typeFilter, pageNo+1, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, next...)
+	}
+
+	if start >= len(txns) {
+		return []*transaction.Transaction{}, nil
+	}
+
+	end := start + limit
+	if end > len(txns) {
+		end = len(txns)
+	}
+
+	return txns[start:end], nil
+}
+
+// count counts the archived transactions up to as many more as there are
+// duplicates, so that the limit still holds once they are taken off.
+func (r *repository) count(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
+	liveCount, err := count(ctx, r.live, walletID, typeFilter, limit)
+	if err != nil || (limit > 0 && liveCount >= int64(limit)) {
+		return liveCount, err
+	}
+
+	duplicates, err := r.duplicates(ctx, walletID, time.Time{}, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	duplicateCount := 0
+	for _, txn := range duplicates {
+		if typeFilter == "" || txn.Type == typeFilter {
+			duplicateCount++
+		}
+	}
+
+	var archiveLimit int
+	if limit > 0 {
+		archiveLimit = limit - int(liveCount) + duplicateCount
+	}
+
+	archiveCount, err := count(ctx, r.archive, walletID, typeFilter, archiveLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	total := liveCount + archiveCount - int64(duplicateCount)
+	if limit > 0 && total > int64(limit) {
+		total = int64(limit)
+	}
+
+	return total, nil
+}
+
+// duplicates returns the transactions of the wallet created in [from, to)
+// that are both live and archived, which only the ones being archived are.
+// As the oldest transactions are archived first, they are among the live ones
+// no newer than the newest archived one, of which there are about a batch.
+func (r *repository) duplicates(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error) {
+	newest, err := r.archive.ReadLastByWalletIDBefore(ctx, walletID, to)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		return []*transaction.Transaction{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	until := newest.CreatedAt.Add(time.Nanosecond)
+	if until.After(to) {
+		until = to
+	}
+
+	live, err := r.live.ReadByWalletIDBetween(ctx, walletID, from, until)
+	if err != nil || len(live) == 0 {
+		return []*transaction.Transaction{}, err
+	}
+
+	archived, err := r.archive.ReadByWalletIDBetween(ctx, walletID, live[0].CreatedAt, until)
+	if err != nil {
+		return nil, err
+	}
+
+	archivedIDs := make(map[string]bool, len(archived))
+	for _, txn := range archived {
+		archivedIDs[txn.ID] = true
+	}
+
+	duplicates := []*transaction.Transaction{}
+	for _, txn := range live {
+		if archivedIDs[txn.ID] {
+			duplicates = append(duplicates, txn)
+		}
+	}
+
+	return duplicates, nil
+}
+
+func readPage(ctx context.Context, tr transaction.TransactionRepository, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	if typeFilter == "" {
+		return tr.ReadByWalletID(ctx, walletID, pageNo, pageSize)
+	}
+
+	return tr.ReadByWalletIDFilterByType(ctx, walletID, typeFilter, pageNo, pageSize)
+}
+
+func count(ctx context.Context, tr transaction.TransactionRepository, walletID, typeFilter string, limit int) (int64, error) {
+	if typeFilter == "" {
+		return tr.CountByWalletID(ctx, walletID, limit)
+	}
+
+	return tr.CountByWalletIDFilterByType(ctx, walletID, typeFilter, limit)
+}
+
+// mergeBuckets adds up the buckets of the same period and type, which a
+// period straddling the archival cutoff has one of from each side.
+func mergeBuckets(buckets []*transaction.Bucket) []*transaction.Bucket {
+	type key struct {
+		period  int64
+		txnType string
+	}
+
+	merged := []*transaction.Bucket{}
+	byKey := make(map[key]*transaction.Bucket)
+	for _, b := range buckets {
+		k := key{b.Period.UnixNano(), b.Type}
+		if m, ok := byKey[k]; ok {
+			m.Total += b.Total
+			m.Count += b.Count
+			m.Average = m.Total / float64(m.Count)
+			continue
+		}
+
+		m := *b
+		byKey[k] = &m
+		merged = append(merged, &m)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].Period.Equal(merged[j].Period) {
+			return merged[i].Period.Before(merged[j].Period)
+		}
+		return merged[i].Type < merged[j].Type
+	})
+
+	return merged
+}
+
+// subtractBuckets takes the buckets of the duplicates off the merged ones,
+// dropping the buckets left without transactions.
+func subtractBuckets(buckets, duplicates []*transaction.Bucket) []*transaction.Bucket {
+	if len(duplicates) == 0 {
+		return buckets
+	}
+
+	type key struct {
+		period  int64
+		txnType string
+	}
+
+	byKey := make(map[key]*transaction.Bucket, len(duplicates))
+	for _, d := range duplicates {
+		byKey[key{d.Period.UnixNano(), d.Type}] = d
+	}
+
+	subtracted := []*transaction.Bucket{}
+	for _, b := range buckets {
+		if d, ok := byKey[key{b.Period.UnixNano(), b.Type}]; ok {
+			b.Total -= d.Total
+			b.Count -= d.Count
+			if b.Count <= 0 {
+				continue
+			}
+			b.Average = b.Total / float64(b.Count)
+		}
+		subtracted = append(subtracted, b)
+	}
+
+	return subtracted
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Denylist keeps revoked token ids until the tokens would have expired
+// anyway. It has to be shared by every instance of the API, or a revoked
+// token still works on the others.
+type Denylist interface {
+	Add(ctx context.Context, jti string, expiresAt time.Time) error
+	Contains(ctx context.Context, jti string) (bool, error)
+}
+
+// memoryDenylist is local to the process, and so only fit for the storage
+// drivers that run a single instance.
+type memoryDenylist struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func NewMemoryDenylist() *memoryDenylist {
+	return &memoryDenylist{entries: make(map[string]time.Time)}
+}
+
+func (d *memoryDenylist) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(time.Now())
+	d.entries[jti] = expiresAt
+	return nil
+}
+
+func (d *memoryDenylist) Contains(ctx context.Context, jti string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.entries[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (d *memoryDenylist) prune(now time.Time) {
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// publicPaths are reachable without an access token, since callers use them
+// to get one.
+var publicPaths = map[string]bool{
+	"/auth/token":   true,
+	"/auth/refresh": true,
+	jwksPath:        true,
+}
+
+const jwksPath = "/.well-known/jwks.json"
+
+// tokenErrorCodes tell clients why their token was rejected, e.g. so that they
+// only refresh expired tokens.
+var tokenErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrTokenMissing, "token_missing"},
+	{ErrTokenMalformed, "token_malformed"},
+	{ErrInvalidSignature, "invalid_signature"},
+	{ErrUnknownKeyID, "invalid_signature"},
+	{ErrUnexpectedAlgorithm, "invalid_signature"},
+	{ErrMissingClaim, "missing_claim"},
+	{ErrTokenExpired, "token_expired"},
+	{ErrTokenNotYetValid, "token_not_yet_valid"},
+	{ErrInvalidIssuer, "invalid_issuer"},
+	{ErrInvalidAudience, "invalid_audience"},
+	{ErrTokenRevoked, "token_revoked"},
+}
+
+type TokenErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var badRequestErrors = []error{
+	ErrMissingCredentials,
+	ErrMissingRefreshToken,
+}
+
+var unauthorizedErrors = []error{
+	ErrInvalidCredentials,
+	ErrInvalidRefreshToken,
+	ErrRefreshTokenReused,
+}
+
+type AuthService interface {
+	Login(ctx context.Context, credentials *Credentials) (*Token, error)
+	Refresh(ctx context.Context, refreshToken string) (*Token, error)
+	Logout(ctx context.Context, claims *Claims, refreshToken string) error
+	RevokeAll(ctx context.Context, claims *Claims) error
+}
+
+type KeyPublisher interface {
+	JWKS() *JWKSet
+}
+
+type handler struct {
+	as AuthService
+	kp KeyPublisher
+}
+
+func NewHandler(as AuthService, kp KeyPublisher) *handler {
+	return &handler{as, kp}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.POST("/auth/token", h.Login)
+	e.POST("/auth/refresh", h.Refresh)
+	e.POST("/auth/logout", h.Logout)
+	e.POST("/auth/revoke-all", h.RevokeAll)
+	e.GET(jwksPath, h.GetJWKS)
+}
+
+func (h *handler) Login(c echo.Context) error {
+	var credentials Credentials
+	if err := c.Bind(&credentials); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token, err := h.as.Login(c.Request().Context(), &credentials)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, token)
+}
+
+func (h *handler) Refresh(c echo.Context) error {
+	var info RefreshInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token, err := h.as.Refresh(c.Request().Context(), info.RefreshToken)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, token)
+}
+
+func (h *handler) Logout(c echo.Context) error {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, ErrMissingSubject.Error())
+	}
+
+	var info RefreshInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.as.Logout(c.Request().Context(), claims, info.RefreshToken); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) RevokeAll(c echo.Context) error {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, ErrMissingSubject.Error())
+	}
+
+	if err := h.as.RevokeAll(c.Request().Context(), claims); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetJWKS publishes the public keys tokens are signed with, so that other
+// services can verify them.
+func (h *handler) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.kp.JWKS())
+}
+
+// Skipper lets requests to the public authentication routes through the JWT
+// middleware.
+func Skipper(c echo.Context) bool {
+	return publicPaths[c.Path()]
+}
+
+// JWTErrorHandler answers rejected tokens with 401 and a code telling the
+// reason, instead of the generic error of the JWT middleware.
+func JWTErrorHandler(err error, c echo.Context) error {
+	if errors.Is(err, middleware.ErrJWTMissing) {
+		err = ErrTokenMissing
+	}
+
+	code := "invalid_token"
+	for _, tec := range tokenErrorCodes {
+		if errors.Is(err, tec.err) {
+			code = tec.code
+			break
+		}
+	}
+
+	if code == "token_missing" {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	} else {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, err.Error()))
+	}
+
+	return echo.NewHTTPError(http.StatusUnauthorized, TokenErrorResponse{code, err.Error()}).SetInternal(err)
+}
+
+func tokenError(err error) error {
+	if containsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if containsError(err, unauthorizedErrors) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+func containsError(err error, errList []error) bool {
+	for _, e := range errList {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,328 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/mock"
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockAuthService(t *testing.T) *mock.MockAuthService {
+	return mock.NewMockAuthService(gomock.NewController(t))
+}
+
+func newTokenService() *auth.TokenService {
+	conf := getConf().JWT
+	ks, err := auth.NewKeySet(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return auth.NewTokenService(conf, ks, auth.NewMemoryDenylist())
+}
+
+func TestHandlerLogin(t *testing.T) {
+	mockAuthService := createMockAuthService(t)
+	h := auth.NewHandler(mockAuthService, newTokenService())
+
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        auth.Skipper,
+		ParseTokenFunc: newTokenService().Decode,
+	}))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenCredentials           *auth.Credentials
+		mockASToken                *auth.Token
+		mockASErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "credentials are valid, return token",
+			givenCredentials:           &auth.Credentials{Username: "dev", Password: "password"},
+			mockASToken:                &auth.Token{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &auth.Token{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900},
+		},
+		{
+			desc:                       "credentials are missing, return error",
+			givenCredentials:           &auth.Credentials{},
+			mockASErr:                  auth.ErrMissingCredentials,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{auth.ErrMissingCredentials.Error()},
+		},
+		{
+			desc:                       "credentials are invalid, return error",
+			givenCredentials:           &auth.Credentials{Username: "dev", Password: "wrong"},
+			mockASErr:                  auth.ErrInvalidCredentials,
+			expectedResponseStatusCode: 401,
+			expectedResponseBody:       httpErr{auth.ErrInvalidCredentials.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAuthService.EXPECT().
+				Login(gomock.Any(), tC.givenCredentials).
+				Return(tC.mockASToken, tC.mockASErr)
+
+			reqBodyBytes, _ := json.Marshal(tC.givenCredentials)
+			res, err := testServer.Client().Post(
+				fmt.Sprintf("%s/auth/token", testServer.URL),
+				"application/json",
+				bytes.NewReader(reqBodyBytes),
+			)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerRefresh(t *testing.T) {
+	mockAuthService := createMockAuthService(t)
+	h := auth.NewHandler(mockAuthService, newTokenService())
+
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        auth.Skipper,
+		ParseTokenFunc: newTokenService().Decode,
+	}))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		mockASToken                *auth.Token
+		mockASErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "refresh token is valid, return new tokens",
+			mockASToken:                &auth.Token{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "new"},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &auth.Token{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "new"},
+		},
+		{
+			desc:                       "refresh token was reused, return error",
+			mockASErr:                  auth.ErrRefreshTokenReused,
+			expectedResponseStatusCode: 401,
+			expectedResponseBody:       httpErr{auth.ErrRefreshTokenReused.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockAuthService.EXPECT().Refresh(gomock.Any(), "refresh").Return(tC.mockASToken, tC.mockASErr)
+
+			reqBodyBytes, _ := json.Marshal(auth.RefreshInfo{RefreshToken: "refresh"})
+			res, err := testServer.Client().Post(
+				fmt.Sprintf("%s/auth/refresh", testServer.URL),
+				"application/json",
+				bytes.NewReader(reqBodyBytes),
+			)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerLogoutAndRevokeAll(t *testing.T) {
+	mockAuthService := createMockAuthService(t)
+	h := auth.NewHandler(mockAuthService, newTokenService())
+	ts := newTokenService()
+
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        auth.Skipper,
+		ParseTokenFunc: ts.Decode,
+	}))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	tokenString, claims, _ := ts.Create("1", auth.RoleUser)
+
+	mockAuthService.EXPECT().
+		Logout(gomock.Any(), gomock.Any(), "refresh").
+		DoAndReturn(func(_ interface{}, c *auth.Claims, _ string) error {
+			assert.Equal(t, claims.ID, c.ID)
+			return nil
+		})
+	mockAuthService.EXPECT().
+		RevokeAll(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, c *auth.Claims) error {
+			assert.Equal(t, "1", c.Subject)
+			return nil
+		})
+
+	for _, path := range []string{"/auth/logout", "/auth/revoke-all"} {
+		reqBodyBytes, _ := json.Marshal(auth.RefreshInfo{RefreshToken: "refresh"})
+		req, _ := http.NewRequest(http.MethodPost, testServer.URL+path, bytes.NewReader(reqBodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+
+		res, err := testServer.Client().Do(req)
+		if err != nil {
+			assert.Fail(t, err.Error())
+		}
+		res.Body.Close()
+
+		assert.Equal(t, 204, res.StatusCode)
+	}
+
+	res, err := testServer.Client().Post(testServer.URL+"/auth/logout", "application/json", nil)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func TestSkipper(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        auth.Skipper,
+		ParseTokenFunc: newTokenService().Decode,
+	}))
+	e.GET("/wallets/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func TestHandlerGetJWKS(t *testing.T) {
+	ts := newTokenServiceWithKeys(t, []config.JWTKeyConf{
+		{ID: "k1", Algorithm: auth.AlgorithmES256, PrivateKeyFile: writeECKey(t)},
+	})
+	h := auth.NewHandler(createMockAuthService(t), ts)
+
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        auth.Skipper,
+		ParseTokenFunc: ts.Decode,
+	}))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/.well-known/jwks.json", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(ts.JWKS())
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+	assert.Contains(t, string(resBodyBytes), `"crv":"P-256"`)
+}
+
+func TestJWTErrorHandler(t *testing.T) {
+	conf := getConf().JWT
+	ts := newTokenService()
+
+	e := echo.New()
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		ParseTokenFunc:          ts.Decode,
+		ErrorHandlerWithContext: auth.JWTErrorHandler,
+	}))
+	e.GET("/wallets/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	expiredToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{
+		Issuer:    conf.Issuer,
+		Audience:  auth.Audience{conf.Audience},
+		ExpiresAt: time.Now().Add(-time.Hour).Unix(),
+	}).SignedString([]byte(conf.Secret))
+
+	testCases := []struct {
+		desc                 string
+		givenAuthorization   string
+		expectedResponseBody auth.TokenErrorResponse
+	}{
+		{
+			desc:                 "token is missing, return error",
+			expectedResponseBody: auth.TokenErrorResponse{Code: "token_missing", Message: auth.ErrTokenMissing.Error()},
+		},
+		{
+			desc:                 "token is malformed, return error",
+			givenAuthorization:   "Bearer not-a-token",
+			expectedResponseBody: auth.TokenErrorResponse{Code: "token_malformed", Message: auth.ErrTokenMalformed.Error()},
+		},
+		{
+			desc:                 "token is expired, return error",
+			givenAuthorization:   "Bearer " + expiredToken,
+			expectedResponseBody: auth.TokenErrorResponse{Code: "token_expired", Message: auth.ErrTokenExpired.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, testServer.URL+"/wallets/1", nil)
+			if tC.givenAuthorization != "" {
+				req.Header.Set("Authorization", tC.givenAuthorization)
+			}
+
+			res, err := testServer.Client().Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, 401, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+			assert.Contains(t, res.Header.Get("WWW-Authenticate"), "Bearer")
+		})
+	}
+}
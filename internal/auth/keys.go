@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+var (
+	ErrUnknownKeyID         = errors.New("token is signed with an unknown key")
+	ErrUnexpectedAlgorithm  = errors.New("token is signed with an unexpected algorithm")
+	ErrUnsupportedAlgorithm = errors.New("signing algorithm must be RS256 or ES256")
+	ErrInvalidPrivateKey    = errors.New("private key does not match the signing algorithm")
+	ErrNoActiveKey          = errors.New("no signing key is active yet")
+	ErrMissingKeyID         = errors.New("key id is required")
+)
+
+type signingKey struct {
+	id          string
+	method      jwt.SigningMethod
+	private     interface{}
+	public      interface{}
+	activatesAt time.Time
+	// retiresAt is zero for the latest key, otherwise the time its successor
+	// activates plus the overlap window, so tokens it signed stay verifiable.
+	retiresAt time.Time
+}
+
+// KeySet holds the keys tokens are signed and verified with. Without
+// configured keys it falls back to HS256 with the shared secret.
+type KeySet struct {
+	keys []*signingKey
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+func NewKeySet(conf config.JWTConf) (*KeySet, error) {
+	if len(conf.Keys) == 0 {
+		secret := []byte(conf.Secret)
+		return &KeySet{[]*signingKey{{method: jwt.SigningMethodHS256, private: secret, public: secret}}}, nil
+	}
+
+	keys := make([]*signingKey, 0, len(conf.Keys))
+	for _, kc := range conf.Keys {
+		key, err := loadSigningKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kc.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].activatesAt.Before(keys[j].activatesAt) })
+
+	overlap := time.Minute * time.Duration(conf.KeyOverlapInMin)
+	if overlap == 0 {
+		overlap = time.Minute * time.Duration(conf.ValidityDurationInMin)
+	}
+	for i := 0; i < len(keys)-1; i++ {
+		keys[i].retiresAt = keys[i+1].activatesAt.Add(overlap)
+	}
+
+	return &KeySet{keys}, nil
+}
+
+// signingKey returns the most recently activated key.
+func (ks *KeySet) signingKey(now time.Time) (*signingKey, error) {
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if !ks.keys[i].activatesAt.After(now) {
+			return ks.keys[i], nil
+		}
+	}
+
+	return nil, ErrNoActiveKey
+}
+
+func (ks *KeySet) verificationKey(token *jwt.Token, now time.Time) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range ks.keys {
+		if key.id != kid || key.retired(now) {
+			continue
+		}
+
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrUnexpectedAlgorithm
+		}
+
+		return key.public, nil
+	}
+
+	return nil, ErrUnknownKeyID
+}
+
+// JWKS returns the public keys that are not retired, including the ones that
+// are yet to activate so that verifiers can fetch them ahead of time.
+func (ks *KeySet) JWKS() *JWKSet {
+	now := time.Now()
+	set := &JWKSet{Keys: []*JWK{}}
+	for _, key := range ks.keys {
+		if key.retired(now) {
+			continue
+		}
+
+		if jwk := newJWK(key); jwk != nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+func (k *signingKey) retired(now time.Time) bool {
+	return !k.retiresAt.IsZero() && !now.Before(k.retiresAt)
+}
+
+func loadSigningKey(kc config.JWTKeyConf) (*signingKey, error) {
+	if kc.ID == "" {
+		return nil, ErrMissingKeyID
+	}
+
+	pemBytes, err := os.ReadFile(kc.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	private, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	key := &signingKey{id: kc.ID, private: private, activatesAt: kc.ActivatesAt}
+	switch kc.Algorithm {
+	case AlgorithmRS256:
+		rsaKey, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidPrivateKey
+		}
+		key.method, key.public = jwt.SigningMethodRS256, &rsaKey.PublicKey
+	case AlgorithmES256:
+		ecKey, ok := private.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return nil, ErrInvalidPrivateKey
+		}
+		key.method, key.public = jwt.SigningMethodES256, &ecKey.PublicKey
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(der)
+}
+
+func newJWK(key *signingKey) *JWK {
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType:   "RSA",
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+			N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		return &JWK{
+			KeyType:   "EC",
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+			Curve:     public.Curve.Params().Name,
+			X:         base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size))),
+			Y:         base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size))),
+		}
+	}
+
+	// the shared secret is never published
+	return nil
+}
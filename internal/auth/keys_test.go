@@ -0,0 +1,223 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeRSAKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+}
+
+func writeECKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writePEM(t, "PRIVATE KEY", der)
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	f, err := os.CreateTemp(t.TempDir(), "key*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err = pem.Encode(f, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+func newTokenServiceWithKeys(t *testing.T, keys []config.JWTKeyConf) *auth.TokenService {
+	conf := getConf().JWT
+	conf.Keys = keys
+
+	ks, err := auth.NewKeySet(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return auth.NewTokenService(conf, ks, auth.NewMemoryDenylist())
+}
+
+func TestTokenServiceAsymmetricKeys(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		algorithm string
+		keyFile   string
+	}{
+		{desc: "rs256 key, sign and verify token", algorithm: auth.AlgorithmRS256, keyFile: writeRSAKey(t)},
+		{desc: "es256 key, sign and verify token", algorithm: auth.AlgorithmES256, keyFile: writeECKey(t)},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := newTokenServiceWithKeys(t, []config.JWTKeyConf{
+				{ID: "k1", Algorithm: tC.algorithm, PrivateKeyFile: tC.keyFile},
+			})
+
+			tokenString, _, err := ts.Create("1", auth.RoleUser)
+			assert.Nil(t, err)
+
+			token, err := ts.Decode(tokenString, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tC.algorithm, token.(*jwt.Token).Method.Alg())
+			assert.Equal(t, "k1", token.(*jwt.Token).Header["kid"])
+
+			jwks := ts.JWKS()
+			assert.Len(t, jwks.Keys, 1)
+			assert.Equal(t, "k1", jwks.Keys[0].KeyID)
+			assert.Equal(t, tC.algorithm, jwks.Keys[0].Algorithm)
+		})
+	}
+}
+
+func TestTokenServiceKeyRotation(t *testing.T) {
+	now := time.Now()
+	oldKeyFile, newKeyFile := writeRSAKey(t), writeECKey(t)
+
+	oldTS := newTokenServiceWithKeys(t, []config.JWTKeyConf{
+		{ID: "old", Algorithm: auth.AlgorithmRS256, PrivateKeyFile: oldKeyFile, ActivatesAt: now.Add(-time.Hour)},
+	})
+	oldToken, _, err := oldTS.Create("1", auth.RoleUser)
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		desc                string
+		newKeyActivatesAt   time.Time
+		expectedSigningKey  string
+		expectOldTokenValid bool
+		expectedJWKSKeys    int
+	}{
+		{
+			desc:                "new key is scheduled, sign with old key and publish both",
+			newKeyActivatesAt:   now.Add(time.Hour),
+			expectedSigningKey:  "old",
+			expectOldTokenValid: true,
+			expectedJWKSKeys:    2,
+		},
+		{
+			desc:                "new key is active within the overlap window, accept old tokens",
+			newKeyActivatesAt:   now.Add(-time.Minute),
+			expectedSigningKey:  "new",
+			expectOldTokenValid: true,
+			expectedJWKSKeys:    2,
+		},
+		{
+			desc:                "overlap window is over, reject old tokens",
+			newKeyActivatesAt:   now.Add(-time.Hour),
+			expectedSigningKey:  "new",
+			expectOldTokenValid: false,
+			expectedJWKSKeys:    1,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := newTokenServiceWithKeys(t, []config.JWTKeyConf{
+				{ID: "old", Algorithm: auth.AlgorithmRS256, PrivateKeyFile: oldKeyFile, ActivatesAt: now.Add(-2 * time.Hour)},
+				{ID: "new", Algorithm: auth.AlgorithmES256, PrivateKeyFile: newKeyFile, ActivatesAt: tC.newKeyActivatesAt},
+			})
+
+			tokenString, _, err := ts.Create("1", auth.RoleUser)
+			assert.Nil(t, err)
+
+			token, err := ts.Decode(tokenString, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tC.expectedSigningKey, token.(*jwt.Token).Header["kid"])
+
+			_, err = ts.Decode(oldToken, nil)
+			assert.Equal(t, tC.expectOldTokenValid, err == nil)
+
+			assert.Len(t, ts.JWKS().Keys, tC.expectedJWKSKeys)
+		})
+	}
+}
+
+func TestTokenServiceRejectsAlgorithmConfusion(t *testing.T) {
+	keyFile := writeRSAKey(t)
+	ts := newTokenServiceWithKeys(t, []config.JWTKeyConf{
+		{ID: "k1", Algorithm: auth.AlgorithmRS256, PrivateKeyFile: keyFile},
+	})
+
+	pemBytes, _ := os.ReadFile(keyFile)
+	block, _ := pem.Decode(pemBytes)
+	rsaKey, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+	publicDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+
+	// signs with the public key as an HMAC secret, as an attacker could
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{Subject: "1"})
+	token.Header["kid"] = "k1"
+	tokenString, _ := token.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+
+	decoded, err := ts.Decode(tokenString, nil)
+
+	assert.Nil(t, decoded)
+	assert.ErrorIs(t, err, auth.ErrUnexpectedAlgorithm)
+}
+
+func TestNewKeySetWithInvalidKey(t *testing.T) {
+	conf := getConf().JWT
+
+	testCases := []struct {
+		desc          string
+		givenKey      config.JWTKeyConf
+		expectedError error
+	}{
+		{
+			desc:          "key does not match the algorithm, return error",
+			givenKey:      config.JWTKeyConf{ID: "k1", Algorithm: auth.AlgorithmES256, PrivateKeyFile: writeRSAKey(t)},
+			expectedError: auth.ErrInvalidPrivateKey,
+		},
+		{
+			desc:          "algorithm is not supported, return error",
+			givenKey:      config.JWTKeyConf{ID: "k1", Algorithm: "PS256", PrivateKeyFile: writeRSAKey(t)},
+			expectedError: auth.ErrUnsupportedAlgorithm,
+		},
+		{
+			desc:          "key id is missing, return error",
+			givenKey:      config.JWTKeyConf{Algorithm: auth.AlgorithmRS256, PrivateKeyFile: writeRSAKey(t)},
+			expectedError: auth.ErrMissingKeyID,
+		},
+		{
+			desc:          "file is not a key, return error",
+			givenKey:      config.JWTKeyConf{ID: "k1", Algorithm: auth.AlgorithmRS256, PrivateKeyFile: filepath.Join("..", "..", "go.mod")},
+			expectedError: auth.ErrInvalidPrivateKey,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			conf.Keys = []config.JWTKeyConf{tC.givenKey}
+
+			ks, err := auth.NewKeySet(conf)
+
+			assert.Nil(t, ks)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
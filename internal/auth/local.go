@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gokcelb/wallet-api/config"
+)
+
+// localUserStore serves the users listed in the config file. It is meant for
+// development; production deployments should plug in a real UserStore.
+type localUserStore struct {
+	users map[string]*User
+}
+
+func NewLocalUserStore(users []config.UserConf) *localUserStore {
+	s := &localUserStore{users: make(map[string]*User, len(users))}
+	for _, u := range users {
+		s.users[u.Username] = &User{ID: u.ID, Username: u.Username, PasswordHash: u.PasswordHash, Role: u.Role}
+	}
+
+	return s
+}
+
+func (s *localUserStore) ReadByUsername(ctx context.Context, username string) (*User, error) {
+	user, ok := s.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
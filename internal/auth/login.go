@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tokenTypeBearer = "Bearer"
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrMissingCredentials = errors.New("username and password are required")
+	ErrInvalidCredentials = errors.New("username or password is incorrect")
+)
+
+// dummyHash is compared against when the user does not exist, so that unknown
+// usernames take as long to reject as wrong passwords.
+var dummyHash = []byte("$2a$10$pcTYweOiUiEcwqZ7eJeFR.dsxo95TdA5ax.fJiiJSZ9IaMTZMkdRG")
+
+type UserStore interface {
+	ReadByUsername(ctx context.Context, username string) (*User, error)
+}
+
+type TokenCreator interface {
+	Create(subject, role string) (string, *Claims, error)
+}
+
+type service struct {
+	us   UserStore
+	tc   TokenCreator
+	rr   RefreshTokenRepository
+	dl   Denylist
+	conf config.JWTConf
+}
+
+func NewService(us UserStore, tc TokenCreator, rr RefreshTokenRepository, dl Denylist, conf config.JWTConf) *service {
+	return &service{us, tc, rr, dl, conf}
+}
+
+func (s *service) Login(ctx context.Context, credentials *Credentials) (*Token, error) {
+	if credentials.Username == "" || credentials.Password == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	user, err := s.us.ReadByUsername(ctx, credentials.Username)
+	if err != nil && errors.Is(err, ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(credentials.Password))
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	familyID, err := newRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user.ID, user.Role, familyID)
+}
+
+// issue creates an access token along with a refresh token in the given
+// family, the chain of refresh tokens rotated out of a single login.
+func (s *service) issue(ctx context.Context, userID, role, familyID string) (*Token, error) {
+	accessToken, claims, err := s.tc.Create(userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.rr.Create(ctx, &RefreshToken{
+		Hash:                 hashRefreshToken(refreshToken),
+		UserID:               userID,
+		Role:                 role,
+		FamilyID:             familyID,
+		AccessTokenID:        claims.ID,
+		AccessTokenExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		ExpiresAt:            now.Add(time.Hour * time.Duration(s.conf.RefreshValidityDurationInHours)),
+		CreatedAt:            now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    s.conf.ValidityDurationInMin * 60,
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// passwordHash is the bcrypt hash of "password".
+const passwordHash = "$2a$10$pcTYweOiUiEcwqZ7eJeFR.dsxo95TdA5ax.fJiiJSZ9IaMTZMkdRG"
+
+func createMockUserStore(t *testing.T) *mock.MockUserStore {
+	return mock.NewMockUserStore(gomock.NewController(t))
+}
+
+func createMockTokenCreator(t *testing.T) *mock.MockTokenCreator {
+	return mock.NewMockTokenCreator(gomock.NewController(t))
+}
+
+func createMockRefreshTokenRepository(t *testing.T) *mock.MockRefreshTokenRepository {
+	return mock.NewMockRefreshTokenRepository(gomock.NewController(t))
+}
+
+func createMockDenylist(t *testing.T) *mock.MockDenylist {
+	return mock.NewMockDenylist(gomock.NewController(t))
+}
+
+func getConf() config.Conf {
+	conf, err := config.Read("../../.config/dev.json")
+	if err != nil {
+		panic(err)
+	}
+
+	return conf
+}
+
+func TestServiceLogin(t *testing.T) {
+	mockUserStore := createMockUserStore(t)
+	mockTokenCreator := createMockTokenCreator(t)
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	conf := getConf()
+	s := auth.NewService(mockUserStore, mockTokenCreator, mockRefreshTokenRepository, nil, conf.JWT)
+
+	accessTokenExpiresAt := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+	mockClaims := &auth.Claims{ID: "jti", ExpiresAt: accessTokenExpiresAt.Unix()}
+
+	mockUserStore.EXPECT().
+		ReadByUsername(context.TODO(), "dev").
+		Return(&auth.User{ID: "1", Username: "dev", PasswordHash: passwordHash, Role: auth.RoleUser}, nil)
+	mockTokenCreator.EXPECT().Create("1", auth.RoleUser).Return("token", mockClaims, nil)
+	mockRefreshTokenRepository.EXPECT().
+		Create(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, rt *auth.RefreshToken) error {
+			assert.Equal(t, "1", rt.UserID)
+			assert.Equal(t, auth.RoleUser, rt.Role)
+			assert.Equal(t, "jti", rt.AccessTokenID)
+			assert.True(t, accessTokenExpiresAt.Equal(rt.AccessTokenExpiresAt))
+			assert.NotEmpty(t, rt.FamilyID)
+			assert.NotEmpty(t, rt.Hash)
+			return nil
+		})
+
+	token, err := s.Login(context.TODO(), &auth.Credentials{Username: "dev", Password: "password"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, conf.JWT.ValidityDurationInMin*60, token.ExpiresIn)
+	assert.NotEmpty(t, token.RefreshToken)
+}
+
+func TestServiceLoginWithInvalidCredentials(t *testing.T) {
+	mockUserStore := createMockUserStore(t)
+	s := auth.NewService(mockUserStore, createMockTokenCreator(t), createMockRefreshTokenRepository(t), nil, getConf().JWT)
+
+	unexpectedErr := errors.New("unexpected error")
+
+	testCases := []struct {
+		desc             string
+		givenCredentials *auth.Credentials
+		mockUser         *auth.User
+		mockUSErr        error
+		expectUSCall     bool
+		expectedError    error
+	}{
+		{
+			desc:             "password is missing, return error",
+			givenCredentials: &auth.Credentials{Username: "dev"},
+			expectedError:    auth.ErrMissingCredentials,
+		},
+		{
+			desc:             "user does not exist, return error",
+			givenCredentials: &auth.Credentials{Username: "dev", Password: "password"},
+			mockUSErr:        auth.ErrUserNotFound,
+			expectUSCall:     true,
+			expectedError:    auth.ErrInvalidCredentials,
+		},
+		{
+			desc:             "password is wrong, return error",
+			givenCredentials: &auth.Credentials{Username: "dev", Password: "wrong"},
+			mockUser:         &auth.User{ID: "1", Username: "dev", PasswordHash: passwordHash},
+			expectUSCall:     true,
+			expectedError:    auth.ErrInvalidCredentials,
+		},
+		{
+			desc:             "user store fails, return error",
+			givenCredentials: &auth.Credentials{Username: "dev", Password: "password"},
+			mockUSErr:        unexpectedErr,
+			expectUSCall:     true,
+			expectedError:    unexpectedErr,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.expectUSCall {
+				mockUserStore.EXPECT().
+					ReadByUsername(context.TODO(), tC.givenCredentials.Username).
+					Return(tC.mockUser, tC.mockUSErr)
+			}
+
+			token, err := s.Login(context.TODO(), tC.givenCredentials)
+
+			assert.Nil(t, token)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
+
+func TestLocalUserStoreReadByUsername(t *testing.T) {
+	us := auth.NewLocalUserStore([]config.UserConf{{ID: "1", Username: "dev", PasswordHash: passwordHash}})
+
+	user, err := us.ReadByUsername(context.TODO(), "dev")
+
+	assert.Equal(t, &auth.User{ID: "1", Username: "dev", PasswordHash: passwordHash}, user)
+	assert.Nil(t, err)
+
+	user, err = us.ReadByUsername(context.TODO(), "unknown")
+
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, auth.ErrUserNotFound)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+)
+
+type Memory struct {
+	mu  sync.RWMutex
+	rts []*auth.RefreshToken
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Create(ctx context.Context, rt *auth.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *rt
+	m.rts = append(m.rts, &stored)
+
+	return nil
+}
+
+func (m *Memory) ReadByHash(ctx context.Context, hash string) (*auth.RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rt := range m.rts {
+		if rt.Hash == hash {
+			found := *rt
+			return &found, nil
+		}
+	}
+
+	return nil, auth.ErrRefreshTokenNotFound
+}
+
+func (m *Memory) ReadActiveByFamilyID(ctx context.Context, familyID string) ([]*auth.RefreshToken, error) {
+	return m.readActive(func(rt *auth.RefreshToken) bool { return rt.FamilyID == familyID }), nil
+}
+
+func (m *Memory) ReadActiveByUserID(ctx context.Context, userID string) ([]*auth.RefreshToken, error) {
+	return m.readActive(func(rt *auth.RefreshToken) bool { return rt.UserID == userID }), nil
+}
+
+func (m *Memory) MarkUsed(ctx context.Context, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rt := range m.rts {
+		if rt.Hash == hash && !rt.Used && !rt.Revoked {
+			rt.Used = true
+			return nil
+		}
+	}
+
+	return auth.ErrRefreshTokenUsed
+}
+
+func (m *Memory) RevokeFamily(ctx context.Context, familyID string) error {
+	m.revoke(func(rt *auth.RefreshToken) bool { return rt.FamilyID == familyID })
+	return nil
+}
+
+func (m *Memory) RevokeByUserID(ctx context.Context, userID string) error {
+	m.revoke(func(rt *auth.RefreshToken) bool { return rt.UserID == userID })
+	return nil
+}
+
+func (m *Memory) readActive(match func(*auth.RefreshToken) bool) []*auth.RefreshToken {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	rts := []*auth.RefreshToken{}
+	for _, rt := range m.rts {
+		if match(rt) && !rt.Revoked && rt.AccessTokenExpiresAt.After(now) {
+			found := *rt
+			rts = append(rts, &found)
+		}
+	}
+
+	return rts
+}
+
+func (m *Memory) revoke(match func(*auth.RefreshToken) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rt := range m.rts {
+		if match(rt) {
+			rt.Revoked = true
+		}
+	}
+}
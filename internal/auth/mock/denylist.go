@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/auth (interfaces: Denylist)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDenylist is a mock of Denylist interface.
+type MockDenylist struct {
+	ctrl     *gomock.Controller
+	recorder *MockDenylistMockRecorder
+}
+
+// MockDenylistMockRecorder is the mock recorder for MockDenylist.
+type MockDenylistMockRecorder struct {
+	mock *MockDenylist
+}
+
+// NewMockDenylist creates a new mock instance.
+func NewMockDenylist(ctrl *gomock.Controller) *MockDenylist {
+	mock := &MockDenylist{ctrl: ctrl}
+	mock.recorder = &MockDenylistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDenylist) EXPECT() *MockDenylistMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockDenylist) Add(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockDenylistMockRecorder) Add(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockDenylist)(nil).Add), arg0, arg1, arg2)
+}
+
+// Contains mocks base method.
+func (m *MockDenylist) Contains(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Contains", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Contains indicates an expected call of Contains.
+func (mr *MockDenylistMockRecorder) Contains(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Contains", reflect.TypeOf((*MockDenylist)(nil).Contains), arg0, arg1)
+}
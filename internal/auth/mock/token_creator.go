@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/auth (interfaces: TokenCreator)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+
+	auth "github.com/gokcelb/wallet-api/internal/auth"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokenCreator is a mock of TokenCreator interface.
+type MockTokenCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenCreatorMockRecorder
+}
+
+// MockTokenCreatorMockRecorder is the mock recorder for MockTokenCreator.
+type MockTokenCreatorMockRecorder struct {
+	mock *MockTokenCreator
+}
+
+// NewMockTokenCreator creates a new mock instance.
+func NewMockTokenCreator(ctrl *gomock.Controller) *MockTokenCreator {
+	mock := &MockTokenCreator{ctrl: ctrl}
+	mock.recorder = &MockTokenCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenCreator) EXPECT() *MockTokenCreatorMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTokenCreator) Create(arg0, arg1 string) (string, *auth.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*auth.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTokenCreatorMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTokenCreator)(nil).Create), arg0, arg1)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/auth (interfaces: UserStore)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	auth "github.com/gokcelb/wallet-api/internal/auth"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserStore is a mock of UserStore interface.
+type MockUserStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserStoreMockRecorder
+}
+
+// MockUserStoreMockRecorder is the mock recorder for MockUserStore.
+type MockUserStoreMockRecorder struct {
+	mock *MockUserStore
+}
+
+// NewMockUserStore creates a new mock instance.
+func NewMockUserStore(ctrl *gomock.Controller) *MockUserStore {
+	mock := &MockUserStore{ctrl: ctrl}
+	mock.recorder = &MockUserStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserStore) EXPECT() *MockUserStoreMockRecorder {
+	return m.recorder
+}
+
+// ReadByUsername mocks base method.
+func (m *MockUserStore) ReadByUsername(arg0 context.Context, arg1 string) (*auth.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByUsername", arg0, arg1)
+	ret0, _ := ret[0].(*auth.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByUsername indicates an expected call of ReadByUsername.
+func (mr *MockUserStoreMockRecorder) ReadByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUsername", reflect.TypeOf((*MockUserStore)(nil).ReadByUsername), arg0, arg1)
+}
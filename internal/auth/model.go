@@ -0,0 +1,39 @@
+package auth
+
+import "time"
+
+type User struct {
+	ID           string
+	Username     string
+	PasswordHash string
+	Role         string
+}
+
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type Token struct {
+	AccessToken  string `json:"accessToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshToken struct {
+	Hash                 string
+	UserID               string
+	Role                 string
+	FamilyID             string
+	AccessTokenID        string
+	AccessTokenExpiresAt time.Time
+	ExpiresAt            time.Time
+	CreatedAt            time.Time
+	Used                 bool
+	Revoked              bool
+}
+
+type RefreshInfo struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Denylist shares the revoked token ids between the instances of the API.
+// Mongo removes them some time after they expire, so expired ones are
+// filtered out when reading.
+type Denylist struct {
+	collection *mongo.Collection
+}
+
+func NewDenylist(collection *mongo.Collection) *Denylist {
+	return &Denylist{collection}
+}
+
+func (d *Denylist) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := d.collection.UpdateOne(ctx,
+		bson.M{"_id": jti},
+		bson.M{"$max": bson.M{"expires_at": expiresAt}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+func (d *Denylist) Contains(ctx context.Context, jti string) (bool, error) {
+	var entry mongoDenylistEntry
+	err := d.collection.FindOne(ctx, bson.M{"_id": jti, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	} else if err != nil {
+		log.Error(err)
+		return false, err
+	}
+
+	return true, nil
+}
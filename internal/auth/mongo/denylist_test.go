@@ -0,0 +1,45 @@
+package mongo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth/mongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestDenylist needs a server at MONGO_TEST_URI and is skipped without one.
+func TestDenylist(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	collection := client.Database("wallet-api-test").Collection(primitive.NewObjectID().Hex())
+	defer collection.Drop(context.TODO())
+
+	d := mongo.NewDenylist(collection)
+	require.NoError(t, d.CreateIndexes(context.TODO()))
+
+	require.NoError(t, d.Add(context.TODO(), "jti", time.Now().Add(time.Minute)))
+	require.NoError(t, d.Add(context.TODO(), "expired", time.Now().Add(-time.Second)))
+	// Adding again never shortens the time an id is denied for.
+	require.NoError(t, d.Add(context.TODO(), "jti", time.Now().Add(-time.Second)))
+
+	for jti, expected := range map[string]bool{"jti": true, "expired": false, "unknown": false} {
+		denied, err := d.Contains(context.TODO(), jti)
+
+		assert.NoError(t, err, jti)
+		assert.Equal(t, expected, denied, jti)
+	}
+}
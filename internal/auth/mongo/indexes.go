@@ -0,0 +1,30 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var indexes = []mongo.IndexModel{
+	{Keys: bson.D{bson.E{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+	{Keys: bson.D{bson.E{Key: "family_id", Value: 1}}},
+	{Keys: bson.D{bson.E{Key: "user_id", Value: 1}}},
+}
+
+// The TTL index has mongo remove the denied ids once their tokens expired.
+var denylistIndexes = []mongo.IndexModel{
+	{Keys: bson.D{bson.E{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+}
+
+func (m *Mongo) CreateIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (d *Denylist) CreateIndexes(ctx context.Context) error {
+	_, err := d.collection.Indexes().CreateMany(ctx, denylistIndexes)
+	return err
+}
@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoRefreshToken struct {
+	ID                   primitive.ObjectID `bson:"_id"`
+	Hash                 string             `bson:"hash"`
+	UserID               string             `bson:"user_id"`
+	Role                 string             `bson:"role"`
+	FamilyID             string             `bson:"family_id"`
+	AccessTokenID        string             `bson:"access_token_id"`
+	AccessTokenExpiresAt time.Time          `bson:"access_token_expires_at"`
+	ExpiresAt            time.Time          `bson:"expires_at"`
+	CreatedAt            time.Time          `bson:"created_at"`
+	Used                 bool               `bson:"used"`
+	Revoked              bool               `bson:"revoked"`
+}
+
+type mongoDenylistEntry struct {
+	ID        string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
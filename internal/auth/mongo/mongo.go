@@ -0,0 +1,140 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Create(ctx context.Context, rt *auth.RefreshToken) error {
+	_, err := m.collection.InsertOne(ctx, newMongoRefreshTokenFromRefreshToken(rt))
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+func (m *Mongo) ReadByHash(ctx context.Context, hash string) (*auth.RefreshToken, error) {
+	var mongoRefreshToken mongoRefreshToken
+	err := m.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&mongoRefreshToken)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, auth.ErrRefreshTokenNotFound
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newRefreshTokenFromMongoRefreshToken(&mongoRefreshToken), nil
+}
+
+func (m *Mongo) ReadActiveByFamilyID(ctx context.Context, familyID string) ([]*auth.RefreshToken, error) {
+	return m.readActive(ctx, bson.M{"family_id": familyID})
+}
+
+func (m *Mongo) ReadActiveByUserID(ctx context.Context, userID string) ([]*auth.RefreshToken, error) {
+	return m.readActive(ctx, bson.M{"user_id": userID})
+}
+
+func (m *Mongo) MarkUsed(ctx context.Context, hash string) error {
+	filter := bson.M{"hash": hash, "used": false, "revoked": false}
+	result, err := m.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"used": true}})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return auth.ErrRefreshTokenUsed
+	}
+
+	return nil
+}
+
+func (m *Mongo) RevokeFamily(ctx context.Context, familyID string) error {
+	return m.revoke(ctx, bson.M{"family_id": familyID})
+}
+
+func (m *Mongo) RevokeByUserID(ctx context.Context, userID string) error {
+	return m.revoke(ctx, bson.M{"user_id": userID})
+}
+
+func (m *Mongo) readActive(ctx context.Context, filter bson.M) ([]*auth.RefreshToken, error) {
+	filter["revoked"] = false
+	filter["access_token_expires_at"] = bson.M{"$gt": time.Now()}
+
+	cursor, err := m.collection.Find(ctx, filter)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoRefreshTokens []mongoRefreshToken
+	if err = cursor.All(ctx, &mongoRefreshTokens); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	rts := []*auth.RefreshToken{}
+	for _, mongoRefreshToken := range mongoRefreshTokens {
+		rts = append(rts, newRefreshTokenFromMongoRefreshToken(&mongoRefreshToken))
+	}
+
+	return rts, nil
+}
+
+func (m *Mongo) revoke(ctx context.Context, filter bson.M) error {
+	filter["revoked"] = false
+
+	_, err := m.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+func newMongoRefreshTokenFromRefreshToken(rt *auth.RefreshToken) *mongoRefreshToken {
+	return &mongoRefreshToken{
+		ID:                   primitive.NewObjectID(),
+		Hash:                 rt.Hash,
+		UserID:               rt.UserID,
+		Role:                 rt.Role,
+		FamilyID:             rt.FamilyID,
+		AccessTokenID:        rt.AccessTokenID,
+		AccessTokenExpiresAt: rt.AccessTokenExpiresAt,
+		ExpiresAt:            rt.ExpiresAt,
+		CreatedAt:            rt.CreatedAt,
+		Used:                 rt.Used,
+		Revoked:              rt.Revoked,
+	}
+}
+
+func newRefreshTokenFromMongoRefreshToken(mongoRefreshToken *mongoRefreshToken) *auth.RefreshToken {
+	return &auth.RefreshToken{
+		Hash:                 mongoRefreshToken.Hash,
+		UserID:               mongoRefreshToken.UserID,
+		Role:                 mongoRefreshToken.Role,
+		FamilyID:             mongoRefreshToken.FamilyID,
+		AccessTokenID:        mongoRefreshToken.AccessTokenID,
+		AccessTokenExpiresAt: mongoRefreshToken.AccessTokenExpiresAt,
+		ExpiresAt:            mongoRefreshToken.ExpiresAt,
+		CreatedAt:            mongoRefreshToken.CreatedAt,
+		Used:                 mongoRefreshToken.Used,
+		Revoked:              mongoRefreshToken.Revoked,
+	}
+}
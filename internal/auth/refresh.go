@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token was already used")
+	ErrMissingRefreshToken  = errors.New("refreshToken is required")
+	ErrInvalidRefreshToken  = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused   = errors.New("refresh token was reused, all tokens of the session are revoked")
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, rt *RefreshToken) error
+	ReadByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	// ReadActiveByFamilyID and ReadActiveByUserID return the unrevoked refresh
+	// tokens whose access token has not expired yet.
+	ReadActiveByFamilyID(ctx context.Context, familyID string) ([]*RefreshToken, error)
+	ReadActiveByUserID(ctx context.Context, userID string) ([]*RefreshToken, error)
+	// MarkUsed returns ErrRefreshTokenUsed when the token was used or revoked
+	// in the meantime.
+	MarkUsed(ctx context.Context, hash string) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeByUserID(ctx context.Context, userID string) error
+}
+
+// Refresh rotates a refresh token: the presented token is spent and a new
+// pair is issued in the same family. Presenting a spent token means it leaked,
+// so the whole family is revoked.
+func (s *service) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	if refreshToken == "" {
+		return nil, ErrMissingRefreshToken
+	}
+
+	rt, err := s.rr.ReadByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil && errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	if rt.Revoked || !time.Now().Before(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if rt.Used {
+		return nil, s.revokeFamily(ctx, rt.FamilyID)
+	}
+
+	err = s.rr.MarkUsed(ctx, rt.Hash)
+	if err != nil && errors.Is(err, ErrRefreshTokenUsed) {
+		return nil, s.revokeFamily(ctx, rt.FamilyID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, rt.UserID, rt.Role, rt.FamilyID)
+}
+
+// Logout revokes the access token of the request and, when given, the
+// session of the refresh token.
+func (s *service) Logout(ctx context.Context, claims *Claims, refreshToken string) error {
+	if err := s.dl.Add(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	rt, err := s.rr.ReadByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil && errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if rt.UserID != claims.Subject {
+		return nil
+	}
+
+	return s.rr.RevokeFamily(ctx, rt.FamilyID)
+}
+
+// RevokeAll ends every session of the user of the request.
+func (s *service) RevokeAll(ctx context.Context, claims *Claims) error {
+	if err := s.dl.Add(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return err
+	}
+
+	rts, err := s.rr.ReadActiveByUserID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	if err = s.denyAccessTokens(ctx, rts); err != nil {
+		return err
+	}
+	return s.rr.RevokeByUserID(ctx, claims.Subject)
+}
+
+func (s *service) revokeFamily(ctx context.Context, familyID string) error {
+	rts, err := s.rr.ReadActiveByFamilyID(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.denyAccessTokens(ctx, rts); err != nil {
+		return err
+	}
+	if err = s.rr.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+func (s *service) denyAccessTokens(ctx context.Context, rts []*RefreshToken) error {
+	for _, rt := range rts {
+		if err := s.dl.Add(ctx, rt.AccessTokenID, rt.AccessTokenExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hashRefreshToken is what gets stored, so that a leaked collection does not
+// hand out usable refresh tokens.
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
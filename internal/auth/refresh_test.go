@@ -0,0 +1,213 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func hash(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestServiceRefresh(t *testing.T) {
+	mockTokenCreator := createMockTokenCreator(t)
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	s := auth.NewService(nil, mockTokenCreator, mockRefreshTokenRepository, nil, getConf().JWT)
+
+	storedRefreshToken := &auth.RefreshToken{
+		Hash:      hash("refresh"),
+		UserID:    "1",
+		Role:      auth.RoleUser,
+		FamilyID:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockClaims := &auth.Claims{ID: "jti2", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	mockRefreshTokenRepository.EXPECT().ReadByHash(context.TODO(), hash("refresh")).Return(storedRefreshToken, nil)
+	mockRefreshTokenRepository.EXPECT().MarkUsed(context.TODO(), hash("refresh")).Return(nil)
+	mockTokenCreator.EXPECT().Create("1", auth.RoleUser).Return("token", mockClaims, nil)
+	mockRefreshTokenRepository.EXPECT().
+		Create(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, rt *auth.RefreshToken) error {
+			assert.Equal(t, "family", rt.FamilyID)
+			assert.Equal(t, "jti2", rt.AccessTokenID)
+			assert.NotEqual(t, hash("refresh"), rt.Hash)
+			return nil
+		})
+
+	token, err := s.Refresh(context.TODO(), "refresh")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.NotEqual(t, "refresh", token.RefreshToken)
+}
+
+func TestServiceRefreshWithReusedToken(t *testing.T) {
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	mockDenylist := createMockDenylist(t)
+	s := auth.NewService(nil, nil, mockRefreshTokenRepository, mockDenylist, getConf().JWT)
+
+	accessTokenExpiresAt := time.Now().Add(time.Minute)
+	familyTokens := []*auth.RefreshToken{
+		{FamilyID: "family", AccessTokenID: "jti1", AccessTokenExpiresAt: accessTokenExpiresAt},
+		{FamilyID: "family", AccessTokenID: "jti2", AccessTokenExpiresAt: accessTokenExpiresAt},
+	}
+
+	testCases := []struct {
+		desc         string
+		mockStored   *auth.RefreshToken
+		expectMarked bool
+	}{
+		{
+			desc:       "token was already used, revoke family",
+			mockStored: &auth.RefreshToken{Hash: hash("refresh"), FamilyID: "family", Used: true, ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		{
+			desc:         "token is used concurrently, revoke family",
+			mockStored:   &auth.RefreshToken{Hash: hash("refresh"), FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)},
+			expectMarked: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockRefreshTokenRepository.EXPECT().ReadByHash(context.TODO(), hash("refresh")).Return(tC.mockStored, nil)
+			if tC.expectMarked {
+				mockRefreshTokenRepository.EXPECT().MarkUsed(context.TODO(), hash("refresh")).Return(auth.ErrRefreshTokenUsed)
+			}
+			mockRefreshTokenRepository.EXPECT().ReadActiveByFamilyID(context.TODO(), "family").Return(familyTokens, nil)
+			mockDenylist.EXPECT().Add(context.TODO(), "jti1", accessTokenExpiresAt).Return(nil)
+			mockDenylist.EXPECT().Add(context.TODO(), "jti2", accessTokenExpiresAt).Return(nil)
+			mockRefreshTokenRepository.EXPECT().RevokeFamily(context.TODO(), "family").Return(nil)
+
+			token, err := s.Refresh(context.TODO(), "refresh")
+
+			assert.Nil(t, token)
+			assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+		})
+	}
+}
+
+func TestServiceRefreshWithInvalidToken(t *testing.T) {
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	s := auth.NewService(nil, nil, mockRefreshTokenRepository, nil, getConf().JWT)
+
+	testCases := []struct {
+		desc          string
+		givenToken    string
+		mockStored    *auth.RefreshToken
+		mockRRErr     error
+		expectedError error
+	}{
+		{
+			desc:          "token is missing, return error",
+			expectedError: auth.ErrMissingRefreshToken,
+		},
+		{
+			desc:          "token does not exist, return error",
+			givenToken:    "refresh",
+			mockRRErr:     auth.ErrRefreshTokenNotFound,
+			expectedError: auth.ErrInvalidRefreshToken,
+		},
+		{
+			desc:          "token is expired, return error",
+			givenToken:    "refresh",
+			mockStored:    &auth.RefreshToken{ExpiresAt: time.Now().Add(-time.Minute)},
+			expectedError: auth.ErrInvalidRefreshToken,
+		},
+		{
+			desc:          "token is revoked, return error",
+			givenToken:    "refresh",
+			mockStored:    &auth.RefreshToken{Revoked: true, ExpiresAt: time.Now().Add(time.Hour)},
+			expectedError: auth.ErrInvalidRefreshToken,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.givenToken != "" {
+				mockRefreshTokenRepository.EXPECT().
+					ReadByHash(context.TODO(), hash(tC.givenToken)).
+					Return(tC.mockStored, tC.mockRRErr)
+			}
+
+			token, err := s.Refresh(context.TODO(), tC.givenToken)
+
+			assert.Nil(t, token)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
+
+func TestServiceLogout(t *testing.T) {
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	mockDenylist := createMockDenylist(t)
+	s := auth.NewService(nil, nil, mockRefreshTokenRepository, mockDenylist, getConf().JWT)
+
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := &auth.Claims{ID: "jti", Subject: "1", ExpiresAt: expiresAt.Unix()}
+
+	mockDenylist.EXPECT().Add(context.TODO(), "jti", expiresAt).Return(nil)
+	mockRefreshTokenRepository.EXPECT().
+		ReadByHash(context.TODO(), hash("refresh")).
+		Return(&auth.RefreshToken{UserID: "1", FamilyID: "family"}, nil)
+	mockRefreshTokenRepository.EXPECT().RevokeFamily(context.TODO(), "family").Return(nil)
+
+	err := s.Logout(context.TODO(), claims, "refresh")
+
+	assert.Nil(t, err)
+
+	mockDenylist.EXPECT().Add(context.TODO(), "jti", expiresAt).Return(nil)
+	mockRefreshTokenRepository.EXPECT().
+		ReadByHash(context.TODO(), hash("other")).
+		Return(&auth.RefreshToken{UserID: "2", FamilyID: "other-family"}, nil)
+
+	err = s.Logout(context.TODO(), claims, "other")
+
+	assert.Nil(t, err)
+}
+
+func TestServiceRevokeAll(t *testing.T) {
+	mockRefreshTokenRepository := createMockRefreshTokenRepository(t)
+	mockDenylist := createMockDenylist(t)
+	s := auth.NewService(nil, nil, mockRefreshTokenRepository, mockDenylist, getConf().JWT)
+
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := &auth.Claims{ID: "jti", Subject: "1", ExpiresAt: expiresAt.Unix()}
+
+	mockDenylist.EXPECT().Add(context.TODO(), "jti", expiresAt).Return(nil)
+	mockRefreshTokenRepository.EXPECT().
+		ReadActiveByUserID(context.TODO(), "1").
+		Return([]*auth.RefreshToken{{AccessTokenID: "other-jti", AccessTokenExpiresAt: expiresAt}}, nil)
+	mockDenylist.EXPECT().Add(context.TODO(), "other-jti", expiresAt).Return(nil)
+	mockRefreshTokenRepository.EXPECT().RevokeByUserID(context.TODO(), "1").Return(nil)
+
+	err := s.RevokeAll(context.TODO(), claims)
+
+	assert.Nil(t, err)
+}
+
+func TestTokenServiceDecodeRevokedToken(t *testing.T) {
+	denylist := auth.NewMemoryDenylist()
+	ks, _ := auth.NewKeySet(getConf().JWT)
+	ts := auth.NewTokenService(getConf().JWT, ks, denylist)
+
+	tokenString, claims, err := ts.Create("1", auth.RoleUser)
+	assert.Nil(t, err)
+
+	token, err := ts.Decode(tokenString, nil)
+	assert.NotNil(t, token)
+	assert.Nil(t, err)
+
+	assert.Nil(t, denylist.Add(context.TODO(), claims.ID, time.Unix(claims.ExpiresAt, 0)))
+
+	token, err = ts.Decode(tokenString, nil)
+	assert.Nil(t, token)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+const (
+	ScopeWalletsRead       = "wallets:read"
+	ScopeWalletsWrite      = "wallets:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	// ScopeAnyWallet lifts the ownership check, letting staff act on wallets
+	// of every user.
+	ScopeAnyWallet = "wallets:any"
+	ScopeAdmin     = "admin"
+)
+
+var ErrInsufficientScope = errors.New("token does not grant the required scope")
+
+var roleScopes = map[string][]string{
+	RoleUser: {
+		ScopeWalletsRead,
+		ScopeWalletsWrite,
+		ScopeTransactionsRead,
+		ScopeTransactionsWrite,
+	},
+	RoleSupport: {
+		ScopeWalletsRead,
+		ScopeWalletsWrite,
+		ScopeTransactionsRead,
+		ScopeTransactionsWrite,
+		ScopeAnyWallet,
+	},
+	RoleAdmin: {
+		ScopeWalletsRead,
+		ScopeWalletsWrite,
+		ScopeTransactionsRead,
+		ScopeTransactionsWrite,
+		ScopeAnyWallet,
+		ScopeAdmin,
+	},
+}
+
+// delegableScopes can be granted to callers other than users, such as
+// services and partners. Admin is left out so that those callers cannot grant
+// themselves more.
+var delegableScopes = map[string]bool{
+	ScopeWalletsRead:       true,
+	ScopeWalletsWrite:      true,
+	ScopeTransactionsRead:  true,
+	ScopeTransactionsWrite: true,
+	ScopeAnyWallet:         true,
+}
+
+func IsDelegable(scope string) bool {
+	return delegableScopes[scope]
+}
+
+// ScopesForRole returns the scopes granted to a role, none for unknown roles.
+func ScopesForRole(role string) []string {
+	return roleScopes[role]
+}
+
+// RequireScopes rejects requests whose token lacks any of the given scopes.
+func RequireScopes(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := GetClaims(c); !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, ErrMissingSubject.Error())
+			}
+
+			for _, scope := range scopes {
+				if !HasScope(c, scope) {
+					return echo.NewHTTPError(http.StatusForbidden, ErrInsufficientScope.Error())
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func HasScope(c echo.Context, scope string) bool {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return false
+	}
+
+	for _, s := range claims.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
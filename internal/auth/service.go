@@ -1,7 +1,9 @@
 package auth
 
 import (
-	"fmt"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"time"
 
 	"github.com/gokcelb/wallet-api/config"
@@ -12,49 +14,145 @@ import (
 
 type TokenService struct {
 	conf config.JWTConf
+	ks   *KeySet
+	dl   Denylist
 }
 
-func NewTokenService(conf config.JWTConf) *TokenService {
-	return &TokenService{conf}
+func NewTokenService(conf config.JWTConf, ks *KeySet, dl Denylist) *TokenService {
+	return &TokenService{conf, ks, dl}
 }
 
-func (ts *TokenService) Create() (string, error) {
-	key := []byte(ts.conf.Secret)
+func (ts *TokenService) Create(subject, role string) (string, *Claims, error) {
+	return ts.sign(&Claims{Subject: subject, Role: role, Scopes: ScopesForRole(role)})
+}
+
+// CreateForClient creates a token for an OAuth client with the given scopes
+// only. The subject is namespaced so that it never collides with a user id.
+func (ts *TokenService) CreateForClient(clientID string, scopes, walletIDs []string, signingKeyID string) (string, *Claims, error) {
+	return ts.sign(&Claims{
+		Subject:      ClientSubjectPrefix + clientID,
+		ClientID:     clientID,
+		Scopes:       scopes,
+		WalletIDs:    walletIDs,
+		SigningKeyID: signingKeyID,
+	})
+}
+
+// RevokeClient rejects the tokens already issued to an OAuth client, until
+// the last of them would have expired anyway.
+func (ts *TokenService) RevokeClient(ctx context.Context, clientID string) error {
+	validity := time.Minute * time.Duration(ts.conf.ValidityDurationInMin)
+	skew := time.Second * time.Duration(ts.conf.ClockSkewInSec)
+	return ts.dl.Add(ctx, ClientSubjectPrefix+clientID, time.Now().Add(validity+skew))
+}
 
-	claims := &jwt.StandardClaims{
-		ExpiresAt: time.Now().
-			Add(time.Minute * time.Duration(ts.conf.ValidityDurationInMin)).Unix(),
-		IssuedAt: time.Now().Unix(),
-		Issuer:   ts.conf.Issuer,
+func (ts *TokenService) sign(claims *Claims) (string, *Claims, error) {
+	key, err := ts.ks.signingKey(time.Now())
+	if err != nil {
+		log.Error(err)
+		return "", nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString(key)
+	jti, err := newRandomID()
 	if err != nil {
 		log.Error(err)
+		return "", nil, err
 	}
 
-	return ss, err
+	now := time.Now()
+	claims.ID = jti
+	claims.Issuer = ts.conf.Issuer
+	claims.ExpiresAt = now.Add(time.Minute * time.Duration(ts.conf.ValidityDurationInMin)).Unix()
+	claims.NotBefore = now.Unix()
+	claims.IssuedAt = now.Unix()
+	if ts.conf.Audience != "" {
+		claims.Audience = Audience{ts.conf.Audience}
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+
+	ss, err := token.SignedString(key.private)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return ss, claims, err
 }
 
 func (ts *TokenService) Decode(tokenString string, ctx echo.Context) (interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return ts.ks.verificationKey(token, time.Now())
+	})
+	if err != nil {
+		log.Error("Token is invalid", err)
+		return nil, parseError(err)
+	}
+
+	claims := token.Claims.(*Claims)
+	if err = ts.validate(claims, time.Now()); err != nil {
+		log.Error("Token claims are invalid", err)
+		return nil, err
+	}
+
+	reqCtx := context.Background()
+	if ctx != nil {
+		reqCtx = ctx.Request().Context()
+	}
+
+	if err = ts.checkNotRevoked(reqCtx, claims); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (ts *TokenService) checkNotRevoked(ctx context.Context, claims *Claims) error {
+	ids := []string{}
+	if claims.ID != "" {
+		ids = append(ids, claims.ID)
+	}
+	// Revoked clients are denied by the subject their tokens share.
+	if claims.ClientID != "" {
+		ids = append(ids, ClientSubjectPrefix+claims.ClientID)
+	}
+
+	for _, id := range ids {
+		denied, err := ts.dl.Contains(ctx, id)
+		if err != nil {
+			log.Error(err)
+			return err
 		}
+		if denied {
+			return ErrTokenRevoked
+		}
+	}
 
-		return []byte(ts.conf.Secret), nil
-	})
+	return nil
+}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok {
-		log.Print("Token claims are good to go")
-	} else {
-		log.Error("Token claims are just not it", err)
+// Verify returns the claims of a valid token, for callers outside the JWT
+// middleware.
+func (ts *TokenService) Verify(tokenString string) (*Claims, error) {
+	token, err := ts.Decode(tokenString, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if !token.Valid {
-		log.Error("Token is invalid", err)
+	return token.(*jwt.Token).Claims.(*Claims), nil
+}
+
+func (ts *TokenService) JWKS() *JWKSet {
+	return ts.ks.JWKS()
+}
+
+func newRandomID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
 
-	return token, err
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
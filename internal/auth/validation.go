@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrTokenMissing     = errors.New("token is missing")
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrMissingClaim     = errors.New("token is missing a required claim")
+	ErrTokenExpired     = errors.New("token has expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("token is issued by an unexpected issuer")
+	ErrInvalidAudience  = errors.New("token is not meant for this audience")
+	ErrTokenRevoked     = errors.New("token has been revoked")
+)
+
+// validate checks the registered claims, allowing for the configured clock
+// skew between us and other issuers' clocks on the time based ones.
+func (ts *TokenService) validate(claims *Claims, now time.Time) error {
+	skew := time.Second * time.Duration(ts.conf.ClockSkewInSec)
+
+	if claims.ExpiresAt == 0 || claims.Issuer == "" {
+		return ErrMissingClaim
+	}
+
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(skew)) {
+		return ErrTokenExpired
+	}
+
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-skew)) {
+		return ErrTokenNotYetValid
+	}
+
+	if claims.IssuedAt != 0 && now.Before(time.Unix(claims.IssuedAt, 0).Add(-skew)) {
+		return ErrTokenNotYetValid
+	}
+
+	if claims.Issuer != ts.conf.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if ts.conf.Audience != "" && !claims.Audience.Contains(ts.conf.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// parseError turns the errors of the jwt package into ours, keeping the ones
+// returned from the key lookup.
+func parseError(err error) error {
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return ErrTokenMalformed
+	}
+
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return ErrTokenMalformed
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0 && ve.Inner != nil:
+		return ve.Inner
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0:
+		return ErrUnexpectedAlgorithm
+	case ve.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return ErrInvalidSignature
+	}
+
+	return ErrTokenMalformed
+}
@@ -0,0 +1,176 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenServiceDecodeValidatesClaims(t *testing.T) {
+	conf := getConf().JWT
+	ts := newTokenService()
+	now := time.Now()
+	skew := time.Second * time.Duration(conf.ClockSkewInSec)
+
+	validClaims := func() *auth.Claims {
+		return &auth.Claims{
+			Issuer:    conf.Issuer,
+			Subject:   "1",
+			Audience:  auth.Audience{conf.Audience},
+			ExpiresAt: now.Add(time.Minute).Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  now.Unix(),
+		}
+	}
+
+	testCases := []struct {
+		desc          string
+		modify        func(c *auth.Claims)
+		signingKey    string
+		expectedError error
+	}{
+		{
+			desc:   "claims are valid, return token",
+			modify: func(c *auth.Claims) {},
+		},
+		{
+			desc:   "token expired within the clock skew, return token",
+			modify: func(c *auth.Claims) { c.ExpiresAt = now.Add(-skew / 2).Unix() },
+		},
+		{
+			desc:          "token expired beyond the clock skew, return error",
+			modify:        func(c *auth.Claims) { c.ExpiresAt = now.Add(-skew - time.Second).Unix() },
+			expectedError: auth.ErrTokenExpired,
+		},
+		{
+			desc:          "token is not valid yet, return error",
+			modify:        func(c *auth.Claims) { c.NotBefore = now.Add(skew + time.Minute).Unix() },
+			expectedError: auth.ErrTokenNotYetValid,
+		},
+		{
+			desc:          "token is issued in the future, return error",
+			modify:        func(c *auth.Claims) { c.IssuedAt = now.Add(skew + time.Minute).Unix() },
+			expectedError: auth.ErrTokenNotYetValid,
+		},
+		{
+			desc:          "token has no expiry, return error",
+			modify:        func(c *auth.Claims) { c.ExpiresAt = 0 },
+			expectedError: auth.ErrMissingClaim,
+		},
+		{
+			desc:          "token is issued by someone else, return error",
+			modify:        func(c *auth.Claims) { c.Issuer = "someone-else" },
+			expectedError: auth.ErrInvalidIssuer,
+		},
+		{
+			desc:          "token is meant for another audience, return error",
+			modify:        func(c *auth.Claims) { c.Audience = auth.Audience{"other-api"} },
+			expectedError: auth.ErrInvalidAudience,
+		},
+		{
+			desc:   "token is meant for several audiences including ours, return token",
+			modify: func(c *auth.Claims) { c.Audience = auth.Audience{"other-api", conf.Audience} },
+		},
+		{
+			desc:          "token is signed with another secret, return error",
+			modify:        func(c *auth.Claims) {},
+			signingKey:    "another-secret",
+			expectedError: auth.ErrInvalidSignature,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			claims := validClaims()
+			tC.modify(claims)
+
+			signingKey := conf.Secret
+			if tC.signingKey != "" {
+				signingKey = tC.signingKey
+			}
+			tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
+
+			token, err := ts.Decode(tokenString, nil)
+
+			if tC.expectedError == nil {
+				assert.NotNil(t, token)
+				assert.Nil(t, err)
+			} else {
+				assert.Nil(t, token)
+				assert.ErrorIs(t, err, tC.expectedError)
+			}
+		})
+	}
+}
+
+func TestTokenServiceDecodeMalformedToken(t *testing.T) {
+	ts := newTokenService()
+
+	for _, tokenString := range []string{"not-a-token", "a.b.c", "eyJhbGciOiJIUzI1NiJ9.eyJhdWQiOjF9.c2ln"} {
+		token, err := ts.Decode(tokenString, nil)
+
+		assert.Nil(t, token)
+		assert.ErrorIs(t, err, auth.ErrTokenMalformed)
+	}
+}
+
+func TestAudienceUnmarshalJSON(t *testing.T) {
+	var single, multiple auth.Audience
+
+	assert.Nil(t, single.UnmarshalJSON([]byte(`"wallet-api"`)))
+	assert.Nil(t, multiple.UnmarshalJSON([]byte(`["other-api","wallet-api"]`)))
+
+	assert.Equal(t, auth.Audience{"wallet-api"}, single)
+	assert.Equal(t, auth.Audience{"other-api", "wallet-api"}, multiple)
+}
+
+func TestTokenServiceCreateForClient(t *testing.T) {
+	ts := newTokenService()
+
+	tokenString, _, err := ts.CreateForClient("client", []string{auth.ScopeWalletsRead}, []string{"1"}, "partner")
+	assert.Nil(t, err)
+
+	claims, err := ts.Verify(tokenString)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "client:client", claims.Subject)
+	assert.Equal(t, "client", claims.ClientID)
+	assert.Empty(t, claims.Role)
+	assert.Equal(t, []string{auth.ScopeWalletsRead}, claims.Scopes)
+	assert.Equal(t, []string{"1"}, claims.WalletIDs)
+	assert.Equal(t, "partner", claims.SigningKeyID)
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	auth.SetClaims(c, claims)
+	_, ok := auth.UserID(c)
+
+	assert.False(t, ok)
+
+	claims, err = ts.Verify("not-a-token")
+
+	assert.Nil(t, claims)
+	assert.ErrorIs(t, err, auth.ErrTokenMalformed)
+}
+
+func TestTokenServiceRevokeClient(t *testing.T) {
+	ts := newTokenService()
+
+	revoked, _, err := ts.CreateForClient("client", []string{auth.ScopeWalletsRead}, nil, "")
+	assert.Nil(t, err)
+	other, _, err := ts.CreateForClient("other", []string{auth.ScopeWalletsRead}, nil, "")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ts.RevokeClient(context.TODO(), "client"))
+
+	_, err = ts.Verify(revoked)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+
+	_, err = ts.Verify(other)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,145 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	dateLayout        = "2006-01-02"
+	defaultSeriesDays = 30
+	maxSeriesDays     = 366
+)
+
+var (
+	ErrInvalidTimeZone = errors.New("tz is not a valid time zone")
+	ErrInvalidAt       = errors.New("at must be in YYYY-MM-DD or RFC 3339 format")
+	ErrInvalidDate     = errors.New("from and to must be in YYYY-MM-DD format")
+	ErrPeriodTooLong   = errors.New("balance history cannot span more than 366 days")
+)
+
+type BalanceService interface {
+	GetBalanceAt(ctx context.Context, walletID string, at time.Time) (*Point, error)
+	GetDailySeries(ctx context.Context, walletID string, from, to time.Time) (*Series, error)
+}
+
+type handler struct {
+	bs BalanceService
+}
+
+func NewHandler(bs BalanceService) *handler {
+	return &handler{bs}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/wallets/:id/balance", h.GetBalanceAt, auth.RequireScopes(auth.ScopeWalletsRead))
+	e.GET("/wallets/:id/balance/history", h.GetDailySeries, auth.RequireScopes(auth.ScopeWalletsRead))
+}
+
+func (h *handler) GetBalanceAt(c echo.Context) error {
+	loc, err := parseTimeZone(c.QueryParam("tz"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	at, err := parseAt(c.QueryParam("at"), loc)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	point, err := h.bs.GetBalanceAt(c.Request().Context(), c.Param("id"), at)
+	if err != nil && errors.Is(err, wallet.ErrWalletNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, point)
+}
+
+func (h *handler) GetDailySeries(c echo.Context) error {
+	loc, err := parseTimeZone(c.QueryParam("tz"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	lastDay, err := parseDateOrDefault(c.QueryParam("to"), today, loc)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	from, err := parseDateOrDefault(c.QueryParam("from"), lastDay.AddDate(0, 0, 1-defaultSeriesDays), loc)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	to := lastDay.AddDate(0, 0, 1)
+	if to.After(from.AddDate(0, 0, maxSeriesDays)) {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrPeriodTooLong.Error())
+	}
+
+	series, err := h.bs.GetDailySeries(c.Request().Context(), c.Param("id"), from, to)
+	if err != nil && errors.Is(err, ErrInvalidPeriod) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, wallet.ErrWalletNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+func parseTimeZone(query string) (*time.Location, error) {
+	if query == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(query)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	return loc, nil
+}
+
+// parseAt reads a plain date as the end of that day, so "2022-03-31" gives
+// the closing balance of March 31st.
+func parseAt(query string, loc *time.Location) (time.Time, error) {
+	if query == "" {
+		return time.Now().In(loc), nil
+	}
+
+	if date, err := time.ParseInLocation(dateLayout, query, loc); err == nil {
+		return date.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, query)
+	if err != nil {
+		return time.Time{}, ErrInvalidAt
+	}
+
+	return t.In(loc), nil
+}
+
+func parseDateOrDefault(query string, defaultDate time.Time, loc *time.Location) (time.Time, error) {
+	if query == "" {
+		return defaultDate, nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, query, loc)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	return date, nil
+}
@@ -0,0 +1,203 @@
+package balance_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/authtest"
+	"github.com/gokcelb/wallet-api/internal/balance"
+	"github.com/gokcelb/wallet-api/internal/balance/mock"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockBalanceService(t *testing.T) *mock.MockBalanceService {
+	return mock.NewMockBalanceService(gomock.NewController(t))
+}
+
+func TestHandlerGetBalanceAt(t *testing.T) {
+	mockBalanceService := createMockBalanceService(t)
+	h := balance.NewHandler(mockBalanceService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeWalletsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	endOfMarch31 := time.Date(2022, time.April, 1, 0, 0, 0, 0, istanbul)
+	exactTime := time.Date(2022, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc                       string
+		givenQuery                 string
+		expectedAt                 time.Time
+		mockBSPoint                *balance.Point
+		mockBSErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "date is given, return balance at the end of that day",
+			givenQuery:                 "at=2022-03-31&tz=Europe/Istanbul",
+			expectedAt:                 endOfMarch31,
+			mockBSPoint:                &balance.Point{At: endOfMarch31, Balance: 100},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &balance.Point{At: endOfMarch31, Balance: 100},
+		},
+		{
+			desc:                       "timestamp is given, return balance at that time",
+			givenQuery:                 "at=2022-03-31T12:00:00Z",
+			expectedAt:                 exactTime,
+			mockBSPoint:                &balance.Point{At: exactTime, Balance: 50},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &balance.Point{At: exactTime, Balance: 50},
+		},
+		{
+			desc:                       "wallet does not exist, return error",
+			givenQuery:                 "at=2022-03-31T12:00:00Z",
+			expectedAt:                 exactTime,
+			mockBSErr:                  wallet.ErrWalletNotFound,
+			expectedResponseStatusCode: 404,
+			expectedResponseBody:       httpErr{wallet.ErrWalletNotFound.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockBalanceService.EXPECT().
+				GetBalanceAt(gomock.Any(), "1", tC.expectedAt).
+				Return(tC.mockBSPoint, tC.mockBSErr)
+
+			res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/balance?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerGetDailySeries(t *testing.T) {
+	mockBalanceService := createMockBalanceService(t)
+	h := balance.NewHandler(mockBalanceService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeWalletsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.March, 4, 0, 0, 0, 0, time.UTC)
+	mockSeries := &balance.Series{
+		WalletID: "1",
+		From:     from,
+		To:       to,
+		Points:   []*balance.Point{{At: from.AddDate(0, 0, 1), Balance: 10}},
+	}
+
+	mockBalanceService.EXPECT().GetDailySeries(gomock.Any(), "1", from, to).Return(mockSeries, nil)
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/balance/history?from=2022-03-01&to=2022-03-03", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(mockSeries)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+}
+
+func TestHandlerGetDailySeriesInvalidParams(t *testing.T) {
+	h := balance.NewHandler(createMockBalanceService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeWalletsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                 string
+		givenQuery           string
+		expectedResponseBody interface{}
+	}{
+		{
+			desc:                 "invalid date, return error",
+			givenQuery:           "from=2022-03-01T00:00:00Z",
+			expectedResponseBody: httpErr{balance.ErrInvalidDate.Error()},
+		},
+		{
+			desc:                 "period too long, return error",
+			givenQuery:           "from=2020-01-01&to=2022-01-01",
+			expectedResponseBody: httpErr{balance.ErrPeriodTooLong.Error()},
+		},
+		{
+			desc:                 "invalid time zone, return error",
+			givenQuery:           "tz=Nowhere",
+			expectedResponseBody: httpErr{balance.ErrInvalidTimeZone.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/balance/history?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, 400, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerRequiresScope(t *testing.T) {
+	h := balance.NewHandler(createMockBalanceService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsWrite))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(testServer.URL + "/wallets/1/balance")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 403, res.StatusCode)
+
+	res, err = testServer.Client().Get(testServer.URL + "/wallets/1/balance/history")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 403, res.StatusCode)
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/balance"
+)
+
+type Memory struct {
+	mu        sync.RWMutex
+	snapshots map[string][]*balance.Snapshot
+}
+
+func NewMemory() *Memory {
+	return &Memory{snapshots: make(map[string][]*balance.Snapshot)}
+}
+
+func (m *Memory) Upsert(ctx context.Context, s *balance.Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, snapshot := range m.snapshots[s.WalletID] {
+		if snapshot.At.Equal(s.At) {
+			snapshot.Balance = s.Balance
+			return nil
+		}
+	}
+
+	stored := *s
+	m.snapshots[s.WalletID] = append(m.snapshots[s.WalletID], &stored)
+
+	return nil
+}
+
+func (m *Memory) ReadLastByWalletIDUntil(ctx context.Context, walletID string, until time.Time) (*balance.Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var last *balance.Snapshot
+	for _, snapshot := range m.snapshots[walletID] {
+		if !snapshot.At.After(until) && (last == nil || snapshot.At.After(last.At)) {
+			last = snapshot
+		}
+	}
+
+	if last == nil {
+		return nil, balance.ErrSnapshotNotFound
+	}
+
+	found := *last
+	return &found, nil
+}
+
+func (m *Memory) ReadLastAt(ctx context.Context) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var last time.Time
+	for _, snapshots := range m.snapshots {
+		for _, snapshot := range snapshots {
+			if snapshot.At.After(last) {
+				last = snapshot.At
+			}
+		}
+	}
+
+	if last.IsZero() {
+		return time.Time{}, balance.ErrSnapshotNotFound
+	}
+
+	return last, nil
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/balance (interfaces: BalanceService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	balance "github.com/gokcelb/wallet-api/internal/balance"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBalanceService is a mock of BalanceService interface.
+type MockBalanceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceServiceMockRecorder
+}
+
+// MockBalanceServiceMockRecorder is the mock recorder for MockBalanceService.
+type MockBalanceServiceMockRecorder struct {
+	mock *MockBalanceService
+}
+
+// NewMockBalanceService creates a new mock instance.
+func NewMockBalanceService(ctrl *gomock.Controller) *MockBalanceService {
+	mock := &MockBalanceService{ctrl: ctrl}
+	mock.recorder = &MockBalanceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceService) EXPECT() *MockBalanceServiceMockRecorder {
+	return m.recorder
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockBalanceService) GetBalanceAt(arg0 context.Context, arg1 string, arg2 time.Time) (*balance.Point, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*balance.Point)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockBalanceServiceMockRecorder) GetBalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockBalanceService)(nil).GetBalanceAt), arg0, arg1, arg2)
+}
+
+// GetDailySeries mocks base method.
+func (m *MockBalanceService) GetDailySeries(arg0 context.Context, arg1 string, arg2, arg3 time.Time) (*balance.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailySeries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*balance.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailySeries indicates an expected call of GetDailySeries.
+func (mr *MockBalanceServiceMockRecorder) GetDailySeries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailySeries", reflect.TypeOf((*MockBalanceService)(nil).GetDailySeries), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/balance (interfaces: SnapshotRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	balance "github.com/gokcelb/wallet-api/internal/balance"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSnapshotRepository is a mock of SnapshotRepository interface.
+type MockSnapshotRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotRepositoryMockRecorder
+}
+
+// MockSnapshotRepositoryMockRecorder is the mock recorder for MockSnapshotRepository.
+type MockSnapshotRepositoryMockRecorder struct {
+	mock *MockSnapshotRepository
+}
+
+// NewMockSnapshotRepository creates a new mock instance.
+func NewMockSnapshotRepository(ctrl *gomock.Controller) *MockSnapshotRepository {
+	mock := &MockSnapshotRepository{ctrl: ctrl}
+	mock.recorder = &MockSnapshotRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotRepository) EXPECT() *MockSnapshotRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReadLastAt mocks base method.
+func (m *MockSnapshotRepository) ReadLastAt(arg0 context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLastAt", arg0)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLastAt indicates an expected call of ReadLastAt.
+func (mr *MockSnapshotRepositoryMockRecorder) ReadLastAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLastAt", reflect.TypeOf((*MockSnapshotRepository)(nil).ReadLastAt), arg0)
+}
+
+// ReadLastByWalletIDUntil mocks base method.
+func (m *MockSnapshotRepository) ReadLastByWalletIDUntil(arg0 context.Context, arg1 string, arg2 time.Time) (*balance.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLastByWalletIDUntil", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*balance.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLastByWalletIDUntil indicates an expected call of ReadLastByWalletIDUntil.
+func (mr *MockSnapshotRepositoryMockRecorder) ReadLastByWalletIDUntil(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLastByWalletIDUntil", reflect.TypeOf((*MockSnapshotRepository)(nil).ReadLastByWalletIDUntil), arg0, arg1, arg2)
+}
+
+// Upsert mocks base method.
+func (m *MockSnapshotRepository) Upsert(arg0 context.Context, arg1 *balance.Snapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockSnapshotRepositoryMockRecorder) Upsert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockSnapshotRepository)(nil).Upsert), arg0, arg1)
+}
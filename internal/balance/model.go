@@ -0,0 +1,21 @@
+package balance
+
+import "time"
+
+type Snapshot struct {
+	WalletID string
+	At       time.Time
+	Balance  float64
+}
+
+type Point struct {
+	At      time.Time
+	Balance float64
+}
+
+type Series struct {
+	WalletID string
+	From     time.Time
+	To       time.Time
+	Points   []*Point
+}
@@ -0,0 +1,14 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoSnapshot struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	WalletID string             `bson:"wallet_id"`
+	At       time.Time          `bson:"at"`
+	Balance  float64            `bson:"balance"`
+}
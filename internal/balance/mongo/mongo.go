@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/balance"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Upsert(ctx context.Context, s *balance.Snapshot) error {
+	filter := bson.M{"wallet_id": s.WalletID, "at": s.At}
+	update := bson.M{
+		"$set":         bson.M{"balance": s.Balance},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}
+
+	_, err := m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+func (m *Mongo) ReadLastByWalletIDUntil(ctx context.Context, walletID string, until time.Time) (*balance.Snapshot, error) {
+	opts := options.FindOne().SetSort(bson.D{bson.E{Key: "at", Value: -1}})
+	filter := bson.M{"wallet_id": walletID, "at": bson.M{"$lte": until}}
+
+	var mongoSnapshot mongoSnapshot
+	err := m.collection.FindOne(ctx, filter, opts).Decode(&mongoSnapshot)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, balance.ErrSnapshotNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &balance.Snapshot{
+		WalletID: mongoSnapshot.WalletID,
+		At:       mongoSnapshot.At,
+		Balance:  mongoSnapshot.Balance,
+	}, nil
+}
+
+func (m *Mongo) ReadLastAt(ctx context.Context) (time.Time, error) {
+	opts := options.FindOne().SetSort(bson.D{bson.E{Key: "at", Value: -1}})
+
+	var mongoSnapshot mongoSnapshot
+	err := m.collection.FindOne(ctx, bson.M{}, opts).Decode(&mongoSnapshot)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return time.Time{}, balance.ErrSnapshotNotFound
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	return mongoSnapshot.At, nil
+}
@@ -0,0 +1,182 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/gommon/log"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("no balance snapshot exists until the given time")
+	ErrInvalidPeriod    = errors.New("balance history start must be before its end")
+)
+
+type SnapshotRepository interface {
+	Upsert(ctx context.Context, s *Snapshot) error
+	ReadLastByWalletIDUntil(ctx context.Context, walletID string, until time.Time) (*Snapshot, error)
+	// ReadLastAt returns the time of the latest snapshot of any wallet.
+	ReadLastAt(ctx context.Context) (time.Time, error)
+}
+
+type WalletRepository interface {
+	Read(ctx context.Context, id string) (*wallet.Wallet, error)
+}
+
+type TransactionRepository interface {
+	ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error)
+	ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error)
+	DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error)
+}
+
+type service struct {
+	sr   SnapshotRepository
+	wr   WalletRepository
+	tr   TransactionRepository
+	conf config.Conf
+}
+
+func NewService(sr SnapshotRepository, wr WalletRepository, tr TransactionRepository, conf config.Conf) *service {
+	return &service{sr, wr, tr, conf}
+}
+
+// GetBalanceAt returns the balance after every transaction created before at.
+func (s *service) GetBalanceAt(ctx context.Context, walletID string, at time.Time) (*Point, error) {
+	if _, err := s.wr.Read(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.balanceAt(ctx, walletID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Point{At: at, Balance: balance}, nil
+}
+
+// GetDailySeries returns end-of-day balances for every day in [from, to),
+// where days start at midnight in the location of from.
+func (s *service) GetDailySeries(ctx context.Context, walletID string, from, to time.Time) (*Series, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.wr.Read(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	firstDayEnd := from.AddDate(0, 0, 1)
+	balance, err := s.balanceAt(ctx, walletID, firstDayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.tr.ReadByWalletIDBetween(ctx, walletID, firstDayEnd, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &Series{WalletID: walletID, From: from, To: to, Points: []*Point{}}
+	for dayEnd := firstDayEnd; !dayEnd.After(to); dayEnd = dayEnd.AddDate(0, 0, 1) {
+		for len(txns) > 0 && txns[0].CreatedAt.Before(dayEnd) {
+			balance = txns[0].BalanceAfter
+			txns = txns[1:]
+		}
+		series.Points = append(series.Points, &Point{At: dayEnd, Balance: balance})
+	}
+
+	return series, nil
+}
+
+// TakeSnapshots stores the closing balance of the day starting at dayStart
+// for every wallet that had transactions on that day.
+func (s *service) TakeSnapshots(ctx context.Context, dayStart time.Time) error {
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	walletIDs, err := s.tr.DistinctWalletIDsBetween(ctx, dayStart, dayEnd)
+	if err != nil {
+		return err
+	}
+
+	for _, walletID := range walletIDs {
+		balance, err := s.balanceAt(ctx, walletID, dayEnd)
+		if err != nil {
+			return err
+		}
+
+		if err = s.sr.Upsert(ctx, &Snapshot{WalletID: walletID, At: dayEnd, Balance: balance}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunSnapshots snapshots the UTC days since the last snapshot up to the
+// previous one on every tick until ctx is done, so that the days missed while
+// the API was down are caught up on.
+func (s *service) RunSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.TakeMissingSnapshots(ctx, time.Now()); err != nil {
+			log.Error(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TakeMissingSnapshots snapshots every UTC day from the one the last snapshot
+// ends up to the one before now, or only the one before now when there are
+// no snapshots yet.
+func (s *service) TakeMissingSnapshots(ctx context.Context, now time.Time) error {
+	yesterday := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+
+	dayStart := yesterday
+	last, err := s.sr.ReadLastAt(ctx)
+	if err == nil {
+		// Snapshots are taken at the end of their day.
+		dayStart = last.UTC()
+	} else if !errors.Is(err, ErrSnapshotNotFound) {
+		return err
+	}
+
+	for ; !dayStart.After(yesterday); dayStart = dayStart.AddDate(0, 0, 1) {
+		if err = s.TakeSnapshots(ctx, dayStart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// balanceAt is the balance after the last transaction before at. Snapshots
+// and the initial balance are only for wallets without one, like those whose
+// transactions were archived while the archive is not read.
+func (s *service) balanceAt(ctx context.Context, walletID string, at time.Time) (float64, error) {
+	last, err := s.tr.ReadLastByWalletIDBefore(ctx, walletID, at)
+	if err == nil {
+		return last.BalanceAfter, nil
+	} else if !errors.Is(err, transaction.ErrTransactionNotFound) {
+		return 0, err
+	}
+
+	snapshot, err := s.sr.ReadLastByWalletIDUntil(ctx, walletID, at)
+	if err == nil {
+		return snapshot.Balance, nil
+	} else if !errors.Is(err, ErrSnapshotNotFound) {
+		return 0, err
+	}
+
+	return s.conf.Wallet.InitialBalance, nil
+}
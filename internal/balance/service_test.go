@@ -0,0 +1,204 @@
+package balance_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/balance"
+	"github.com/gokcelb/wallet-api/internal/balance/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockSnapshotRepository(t *testing.T) *mock.MockSnapshotRepository {
+	return mock.NewMockSnapshotRepository(gomock.NewController(t))
+}
+
+func createMockWalletRepository(t *testing.T) *mock.MockWalletRepository {
+	return mock.NewMockWalletRepository(gomock.NewController(t))
+}
+
+func createMockTransactionRepository(t *testing.T) *mock.MockTransactionRepository {
+	return mock.NewMockTransactionRepository(gomock.NewController(t))
+}
+
+func getConf() config.Conf {
+	conf, err := config.Read("../../.config/dev.json")
+	if err != nil {
+		panic(err)
+	}
+
+	return conf
+}
+
+func TestServiceGetBalanceAt(t *testing.T) {
+	mockSnapshotRepository := createMockSnapshotRepository(t)
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := balance.NewService(mockSnapshotRepository, mockWalletRepository, mockTransactionRepository, getConf())
+
+	at := time.Date(2022, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc            string
+		mockTxn         *transaction.Transaction
+		mockTxnErr      error
+		mockSnapshot    *balance.Snapshot
+		mockSnapshotErr error
+		expectedBalance float64
+	}{
+		{
+			desc:            "transaction exists, use its balance after",
+			mockTxn:         &transaction.Transaction{Type: "deposit", Amount: 100, BalanceAfter: 570},
+			expectedBalance: 570,
+		},
+		{
+			desc:            "no transaction exists, use the snapshot",
+			mockTxnErr:      transaction.ErrTransactionNotFound,
+			mockSnapshot:    &balance.Snapshot{WalletID: "1", At: at.Add(-12 * time.Hour), Balance: 500},
+			expectedBalance: 500,
+		},
+		{
+			desc:            "no transaction or snapshot exists, use the initial balance",
+			mockTxnErr:      transaction.ErrTransactionNotFound,
+			mockSnapshotErr: balance.ErrSnapshotNotFound,
+			expectedBalance: getConf().Wallet.InitialBalance,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil)
+			mockTransactionRepository.EXPECT().
+				ReadLastByWalletIDBefore(context.TODO(), "1", at).
+				Return(tC.mockTxn, tC.mockTxnErr)
+			if tC.mockTxnErr != nil {
+				mockSnapshotRepository.EXPECT().
+					ReadLastByWalletIDUntil(context.TODO(), "1", at).
+					Return(tC.mockSnapshot, tC.mockSnapshotErr)
+			}
+
+			point, err := s.GetBalanceAt(context.TODO(), "1", at)
+
+			assert.Equal(t, &balance.Point{At: at, Balance: tC.expectedBalance}, point)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestServiceGetDailySeries(t *testing.T) {
+	mockSnapshotRepository := createMockSnapshotRepository(t)
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := balance.NewService(mockSnapshotRepository, mockWalletRepository, mockTransactionRepository, getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	day2 := from.AddDate(0, 0, 1)
+	to := from.AddDate(0, 0, 3)
+
+	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil)
+	mockTransactionRepository.EXPECT().
+		ReadLastByWalletIDBefore(context.TODO(), "1", day2).
+		Return(&transaction.Transaction{Type: "deposit", Amount: 100, BalanceAfter: 100}, nil)
+	mockTransactionRepository.EXPECT().
+		ReadByWalletIDBetween(context.TODO(), "1", day2, to).
+		Return([]*transaction.Transaction{
+			{Type: "deposit", Amount: 50, BalanceAfter: 150, CreatedAt: day2.Add(time.Hour)},
+			{Type: "withdrawal", Amount: 20, BalanceAfter: 130, CreatedAt: day2.Add(25 * time.Hour)},
+		}, nil)
+
+	series, err := s.GetDailySeries(context.TODO(), "1", from, to)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*balance.Point{
+		{At: day2, Balance: 100},
+		{At: day2.AddDate(0, 0, 1), Balance: 150},
+		{At: to, Balance: 130},
+	}, series.Points)
+}
+
+func TestServiceTakeSnapshots(t *testing.T) {
+	mockSnapshotRepository := createMockSnapshotRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := balance.NewService(mockSnapshotRepository, nil, mockTransactionRepository, getConf())
+
+	dayStart := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	mockTransactionRepository.EXPECT().
+		DistinctWalletIDsBetween(context.TODO(), dayStart, dayEnd).
+		Return([]string{"1"}, nil)
+	mockTransactionRepository.EXPECT().
+		ReadLastByWalletIDBefore(context.TODO(), "1", dayEnd).
+		Return(&transaction.Transaction{Type: "deposit", Amount: 40, BalanceAfter: 140}, nil)
+	mockSnapshotRepository.EXPECT().
+		Upsert(context.TODO(), &balance.Snapshot{WalletID: "1", At: dayEnd, Balance: 140}).
+		Return(nil)
+
+	err := s.TakeSnapshots(context.TODO(), dayStart)
+
+	assert.Nil(t, err)
+}
+
+func TestServiceTakeMissingSnapshots(t *testing.T) {
+	mockSnapshotRepository := createMockSnapshotRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := balance.NewService(mockSnapshotRepository, nil, mockTransactionRepository, getConf())
+
+	now := time.Date(2022, time.March, 4, 10, 0, 0, 0, time.UTC)
+	day1 := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc            string
+		mockLastAt      time.Time
+		mockLastAtErr   error
+		expectedDayEnds []time.Time
+	}{
+		{
+			desc:            "snapshots exist, take every day since the last one",
+			mockLastAt:      day1,
+			expectedDayEnds: []time.Time{day1.AddDate(0, 0, 1), day1.AddDate(0, 0, 2), day1.AddDate(0, 0, 3)},
+		},
+		{
+			desc:            "no snapshot exists, take only the previous day",
+			mockLastAtErr:   balance.ErrSnapshotNotFound,
+			expectedDayEnds: []time.Time{day1.AddDate(0, 0, 3)},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockSnapshotRepository.EXPECT().ReadLastAt(context.TODO()).Return(tC.mockLastAt, tC.mockLastAtErr)
+			for _, dayEnd := range tC.expectedDayEnds {
+				mockTransactionRepository.EXPECT().
+					DistinctWalletIDsBetween(context.TODO(), dayEnd.AddDate(0, 0, -1), dayEnd).
+					Return([]string{}, nil)
+			}
+
+			err := s.TakeMissingSnapshots(context.TODO(), now)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestServiceGetDailySeriesWithInvalidParams(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	s := balance.NewService(nil, mockWalletRepository, nil, getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	series, err := s.GetDailySeries(context.TODO(), "1", from, from)
+
+	assert.Nil(t, series)
+	assert.ErrorIs(t, err, balance.ErrInvalidPeriod)
+
+	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, wallet.ErrWalletNotFound)
+
+	series, err = s.GetDailySeries(context.TODO(), "1", from, from.AddDate(0, 0, 1))
+
+	assert.Nil(t, series)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotFound)
+}
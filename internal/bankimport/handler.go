@@ -0,0 +1,92 @@
+package bankimport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+var ErrMissingFile = errors.New("bank statement file must be sent in the file form field")
+
+var badRequestErrors = []error{
+	ErrInvalidFormat,
+	ErrMalformedFile,
+	ErrMissingCSVColumn,
+	ErrMissingBankReference,
+}
+
+type ImportService interface {
+	Import(ctx context.Context, format string, r io.Reader) (*Result, error)
+	GetReviewEntries(ctx context.Context, pageNo, pageSize int) ([]*Entry, error)
+}
+
+type handler struct {
+	is ImportService
+}
+
+func NewHandler(is ImportService) *handler {
+	return &handler{is}
+}
+
+// RegisterRoutes registers the imports under /admin, since an import credits
+// any wallet its lines refer to.
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	g := e.Group("/admin", auth.RequireScopes(auth.ScopeAdmin))
+	g.POST("/imports", h.Import)
+	g.GET("/imports/review", h.GetReviewEntries)
+}
+
+func (h *handler) Import(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrMissingFile.Error())
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	defer file.Close()
+
+	result, err := h.is.Import(c.Request().Context(), c.QueryParam("format"), file)
+	if err != nil && wallet.ContainsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) GetReviewEntries(c echo.Context) error {
+	pageNo, err := queryIntOrDefault(c.QueryParam("pageNo"), wallet.DefaultPageNo)
+	if err != nil || pageNo < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, wallet.ErrInvalidPageNo.Error())
+	}
+
+	pageSize, err := queryIntOrDefault(c.QueryParam("pageSize"), wallet.DefaultPageSize)
+	if err != nil || pageSize < 1 || pageSize > wallet.MaxPageSize {
+		return echo.NewHTTPError(http.StatusBadRequest, wallet.ErrPageSizeOutOfRange.Error())
+	}
+
+	entries, err := h.is.GetReviewEntries(c.Request().Context(), pageNo, pageSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+func queryIntOrDefault(query string, defaultValue int) (int, error) {
+	if query == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(query)
+}
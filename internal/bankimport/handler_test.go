@@ -0,0 +1,195 @@
+package bankimport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/authtest"
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"github.com/gokcelb/wallet-api/internal/bankimport/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockImportService(t *testing.T) *mock.MockImportService {
+	return mock.NewMockImportService(gomock.NewController(t))
+}
+
+// newTestServer serves the routes of h to a caller with the given scopes.
+func newTestServer(h interface{ RegisterRoutes(e *echo.Echo) }, scopes []string) *httptest.Server {
+	e := echo.New()
+	e.Use(authtest.WithScopes(scopes...))
+	h.RegisterRoutes(e)
+
+	return httptest.NewServer(e.Server.Handler)
+}
+
+func TestHandlerImport(t *testing.T) {
+	mockImportService := createMockImportService(t)
+	h := bankimport.NewHandler(mockImportService)
+
+	testServer := newTestServer(h, auth.ScopesForRole(auth.RoleAdmin))
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenFormat                string
+		mockISResult               *bankimport.Result
+		mockISErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "file is valid, return import result",
+			givenFormat:                bankimport.FormatCSV,
+			mockISResult:               &bankimport.Result{Matched: 1, Entries: []*bankimport.Entry{{ID: "1"}}},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &bankimport.Result{Matched: 1, Entries: []*bankimport.Entry{{ID: "1"}}},
+		},
+		{
+			desc:                       "file is malformed, return error",
+			givenFormat:                bankimport.FormatOFX,
+			mockISErr:                  bankimport.ErrMalformedFile,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{bankimport.ErrMalformedFile.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockImportService.EXPECT().
+				Import(gomock.Any(), tC.givenFormat, gomock.Any()).
+				DoAndReturn(func(_, _ interface{}, r io.Reader) (*bankimport.Result, error) {
+					content, _ := io.ReadAll(r)
+					assert.Equal(t, "file content", string(content))
+					return tC.mockISResult, tC.mockISErr
+				})
+
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			fw, _ := mw.CreateFormFile("file", "statement")
+			_, _ = fw.Write([]byte("file content"))
+			_ = mw.Close()
+
+			res, err := testServer.Client().Post(
+				fmt.Sprintf("%s/admin/imports?format=%s", testServer.URL, tC.givenFormat),
+				mw.FormDataContentType(),
+				&body,
+			)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerImportWithoutFile(t *testing.T) {
+	h := bankimport.NewHandler(createMockImportService(t))
+
+	testServer := newTestServer(h, auth.ScopesForRole(auth.RoleAdmin))
+	defer testServer.Close()
+
+	res, err := testServer.Client().Post(fmt.Sprintf("%s/admin/imports?format=csv", testServer.URL), "text/csv", nil)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(httpErr{bankimport.ErrMissingFile.Error()})
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+}
+
+func TestHandlerGetReviewEntries(t *testing.T) {
+	mockImportService := createMockImportService(t)
+	h := bankimport.NewHandler(mockImportService)
+
+	testServer := newTestServer(h, auth.ScopesForRole(auth.RoleAdmin))
+	defer testServer.Close()
+
+	mockEntries := []*bankimport.Entry{{ID: "1", BankReference: "B1", Status: bankimport.StatusReview}}
+
+	mockImportService.EXPECT().
+		GetReviewEntries(gomock.Any(), 1, 5).
+		Return(mockEntries, nil)
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/admin/imports/review?pageNo=1&pageSize=5", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(mockEntries)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+
+	res, err = testServer.Client().Get(fmt.Sprintf("%s/admin/imports/review?pageSize=500", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func TestHandlerRequiresAdmin(t *testing.T) {
+	h := bankimport.NewHandler(createMockImportService(t))
+
+	testCases := []struct {
+		desc        string
+		givenScopes []string
+	}{
+		{desc: "user", givenScopes: auth.ScopesForRole(auth.RoleUser)},
+		{desc: "support", givenScopes: auth.ScopesForRole(auth.RoleSupport)},
+		{desc: "service with every delegable scope", givenScopes: []string{
+			auth.ScopeWalletsRead,
+			auth.ScopeWalletsWrite,
+			auth.ScopeTransactionsRead,
+			auth.ScopeTransactionsWrite,
+			auth.ScopeAnyWallet,
+		}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			testServer := newTestServer(h, tC.givenScopes)
+			defer testServer.Close()
+
+			res, err := testServer.Client().Post(fmt.Sprintf("%s/admin/imports?format=csv", testServer.URL), "text/csv", nil)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, 403, res.StatusCode)
+
+			res, err = testServer.Client().Get(fmt.Sprintf("%s/admin/imports/review", testServer.URL))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, 403, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]*bankimport.Entry
+}
+
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]*bankimport.Entry)}
+}
+
+func (m *Memory) Create(ctx context.Context, entry *bankimport.Entry) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.entries {
+		if e.BankReference == entry.BankReference {
+			return "", bankimport.ErrDuplicateBankReference
+		}
+	}
+
+	stored := *entry
+	stored.ID = primitive.NewObjectID().Hex()
+	stored.CreatedAt = time.Now()
+	stored.AttemptedAt = stored.CreatedAt
+	m.entries[stored.ID] = &stored
+
+	return stored.ID, nil
+}
+
+func (m *Memory) Retry(ctx context.Context, bankReference string, stalePendingBefore time.Time) (*bankimport.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.entries {
+		if e.BankReference != bankReference {
+			continue
+		}
+
+		stale := e.Status == bankimport.StatusPending && e.AttemptedAt.Before(stalePendingBefore)
+		if e.Status != bankimport.StatusFailed && !stale {
+			break
+		}
+
+		e.Status, e.Reason, e.AttemptedAt = bankimport.StatusPending, "", time.Now()
+		found := *e
+		return &found, nil
+	}
+
+	return nil, bankimport.ErrDuplicateBankReference
+}
+
+func (m *Memory) Update(ctx context.Context, entry *bankimport.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[entry.ID]
+	if !ok {
+		return nil
+	}
+	e.Status = entry.Status
+	e.WalletID = entry.WalletID
+	e.TransactionID = entry.TransactionID
+	e.Reason = entry.Reason
+
+	return nil
+}
+
+func (m *Memory) ReadByStatus(ctx context.Context, status string, pageNo, pageSize int) ([]*bankimport.Entry, error) {
+	m.mu.RLock()
+	entries := []*bankimport.Entry{}
+	for _, e := range m.entries {
+		if e.Status == status {
+			found := *e
+			entries = append(entries, &found)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	start := pageNo * pageSize
+	if start >= len(entries) {
+		return []*bankimport.Entry{}, nil
+	}
+
+	end := start + pageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	return entries[start:end], nil
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/bankimport (interfaces: EntryRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	bankimport "github.com/gokcelb/wallet-api/internal/bankimport"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEntryRepository is a mock of EntryRepository interface.
+type MockEntryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEntryRepositoryMockRecorder
+}
+
+// MockEntryRepositoryMockRecorder is the mock recorder for MockEntryRepository.
+type MockEntryRepositoryMockRecorder struct {
+	mock *MockEntryRepository
+}
+
+// NewMockEntryRepository creates a new mock instance.
+func NewMockEntryRepository(ctrl *gomock.Controller) *MockEntryRepository {
+	mock := &MockEntryRepository{ctrl: ctrl}
+	mock.recorder = &MockEntryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEntryRepository) EXPECT() *MockEntryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockEntryRepository) Create(arg0 context.Context, arg1 *bankimport.Entry) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockEntryRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEntryRepository)(nil).Create), arg0, arg1)
+}
+
+// ReadByStatus mocks base method.
+func (m *MockEntryRepository) ReadByStatus(arg0 context.Context, arg1 string, arg2, arg3 int) ([]*bankimport.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*bankimport.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByStatus indicates an expected call of ReadByStatus.
+func (mr *MockEntryRepositoryMockRecorder) ReadByStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByStatus", reflect.TypeOf((*MockEntryRepository)(nil).ReadByStatus), arg0, arg1, arg2, arg3)
+}
+
+// Retry mocks base method.
+func (m *MockEntryRepository) Retry(arg0 context.Context, arg1 string, arg2 time.Time) (*bankimport.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retry", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*bankimport.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Retry indicates an expected call of Retry.
+func (mr *MockEntryRepositoryMockRecorder) Retry(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retry", reflect.TypeOf((*MockEntryRepository)(nil).Retry), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockEntryRepository) Update(arg0 context.Context, arg1 *bankimport.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockEntryRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockEntryRepository)(nil).Update), arg0, arg1)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/bankimport (interfaces: ImportService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	bankimport "github.com/gokcelb/wallet-api/internal/bankimport"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockImportService is a mock of ImportService interface.
+type MockImportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportServiceMockRecorder
+}
+
+// MockImportServiceMockRecorder is the mock recorder for MockImportService.
+type MockImportServiceMockRecorder struct {
+	mock *MockImportService
+}
+
+// NewMockImportService creates a new mock instance.
+func NewMockImportService(ctrl *gomock.Controller) *MockImportService {
+	mock := &MockImportService{ctrl: ctrl}
+	mock.recorder = &MockImportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportService) EXPECT() *MockImportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetReviewEntries mocks base method.
+func (m *MockImportService) GetReviewEntries(arg0 context.Context, arg1, arg2 int) ([]*bankimport.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewEntries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*bankimport.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewEntries indicates an expected call of GetReviewEntries.
+func (mr *MockImportServiceMockRecorder) GetReviewEntries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewEntries", reflect.TypeOf((*MockImportService)(nil).GetReviewEntries), arg0, arg1, arg2)
+}
+
+// Import mocks base method.
+func (m *MockImportService) Import(arg0 context.Context, arg1 string, arg2 io.Reader) (*bankimport.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*bankimport.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockImportServiceMockRecorder) Import(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockImportService)(nil).Import), arg0, arg1, arg2)
+}
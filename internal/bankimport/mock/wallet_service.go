@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/bankimport (interfaces: WalletService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	wallet "github.com/gokcelb/wallet-api/internal/wallet"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWalletService is a mock of WalletService interface.
+type MockWalletService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletServiceMockRecorder
+}
+
+// MockWalletServiceMockRecorder is the mock recorder for MockWalletService.
+type MockWalletServiceMockRecorder struct {
+	mock *MockWalletService
+}
+
+// NewMockWalletService creates a new mock instance.
+func NewMockWalletService(ctrl *gomock.Controller) *MockWalletService {
+	mock := &MockWalletService{ctrl: ctrl}
+	mock.recorder = &MockWalletServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateTransaction mocks base method.
+func (m *MockWalletService) CreateTransaction(arg0 context.Context, arg1 *wallet.TransactionCreationInfo) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransaction", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransaction indicates an expected call of CreateTransaction.
+func (mr *MockWalletServiceMockRecorder) CreateTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockWalletService)(nil).CreateTransaction), arg0, arg1)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletService) GetWallet(arg0 context.Context, arg1 string) (*wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", arg0, arg1)
+	ret0, _ := ret[0].(*wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletServiceMockRecorder) GetWallet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletService)(nil).GetWallet), arg0, arg1)
+}
@@ -0,0 +1,43 @@
+package bankimport
+
+import "time"
+
+const (
+	StatusPending = "pending"
+	StatusMatched = "matched"
+	StatusReview  = "review"
+	// StatusFailed marks entries whose deposit failed for reasons other than
+	// the wallet rejecting it, to be retried when the file is imported again.
+	StatusFailed = "failed"
+)
+
+type Line struct {
+	BankReference string
+	Reference     string
+	Amount        float64
+	Currency      string
+	BookedAt      time.Time
+}
+
+type Entry struct {
+	ID            string
+	BankReference string
+	Reference     string
+	Amount        float64
+	Currency      string
+	BookedAt      time.Time
+	Status        string
+	WalletID      string
+	TransactionID string
+	Reason        string
+	CreatedAt     time.Time
+	AttemptedAt   time.Time
+}
+
+type Result struct {
+	Matched    int
+	Review     int
+	Duplicates int
+	Ignored    int
+	Entries    []*Entry
+}
@@ -0,0 +1,22 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoEntry struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	BankReference string             `bson:"bank_reference"`
+	Reference     string             `bson:"reference"`
+	Amount        float64            `bson:"amount"`
+	Currency      string             `bson:"currency"`
+	BookedAt      time.Time          `bson:"booked_at"`
+	Status        string             `bson:"status"`
+	WalletID      string             `bson:"wallet_id"`
+	TransactionID string             `bson:"transaction_id"`
+	Reason        string             `bson:"reason"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	AttemptedAt   time.Time          `bson:"attempted_at"`
+}
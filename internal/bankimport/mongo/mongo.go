@@ -0,0 +1,149 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Create(ctx context.Context, entry *bankimport.Entry) (string, error) {
+	mongoEntry := newMongoEntryFromEntry(entry)
+	filter := bson.M{"bank_reference": mongoEntry.BankReference}
+	opts := options.Update().SetUpsert(true)
+
+	result, err := m.collection.UpdateOne(ctx, filter, bson.M{"$setOnInsert": mongoEntry}, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", bankimport.ErrDuplicateBankReference
+	} else if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	if result.UpsertedCount == 0 {
+		return "", bankimport.ErrDuplicateBankReference
+	}
+
+	return mongoEntry.ID.Hex(), nil
+}
+
+// Retry takes entries from before attempted_at was kept as attempted when
+// they were created.
+func (m *Mongo) Retry(ctx context.Context, bankReference string, stalePendingBefore time.Time) (*bankimport.Entry, error) {
+	filter := bson.M{
+		"bank_reference": bankReference,
+		"$or": bson.A{
+			bson.M{"status": bankimport.StatusFailed},
+			bson.M{"status": bankimport.StatusPending, "attempted_at": bson.M{"$lt": stalePendingBefore}},
+			bson.M{
+				"status":       bankimport.StatusPending,
+				"attempted_at": bson.M{"$exists": false},
+				"created_at":   bson.M{"$lt": stalePendingBefore},
+			},
+		},
+	}
+	update := bson.M{"$set": bson.M{"status": bankimport.StatusPending, "reason": "", "attempted_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var mongoEntry mongoEntry
+	err := m.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&mongoEntry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, bankimport.ErrDuplicateBankReference
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newEntryFromMongoEntry(&mongoEntry), nil
+}
+
+func (m *Mongo) Update(ctx context.Context, entry *bankimport.Entry) error {
+	objectID, err := primitive.ObjectIDFromHex(entry.ID)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	_, err = m.collection.UpdateByID(ctx, objectID, bson.M{"$set": bson.M{
+		"status":         entry.Status,
+		"wallet_id":      entry.WalletID,
+		"transaction_id": entry.TransactionID,
+		"reason":         entry.Reason,
+	}})
+	return err
+}
+
+func (m *Mongo) ReadByStatus(ctx context.Context, status string, pageNo, pageSize int) ([]*bankimport.Entry, error) {
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}}).
+		SetSkip(int64(pageNo * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := m.collection.Find(ctx, bson.M{"status": status}, opts)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoEntries []mongoEntry
+	if err = cursor.All(ctx, &mongoEntries); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	entries := []*bankimport.Entry{}
+	for _, mongoEntry := range mongoEntries {
+		entries = append(entries, newEntryFromMongoEntry(&mongoEntry))
+	}
+
+	return entries, nil
+}
+
+func newMongoEntryFromEntry(entry *bankimport.Entry) *mongoEntry {
+	now := time.Now()
+	return &mongoEntry{
+		ID:            primitive.NewObjectID(),
+		BankReference: entry.BankReference,
+		Reference:     entry.Reference,
+		Amount:        entry.Amount,
+		Currency:      entry.Currency,
+		BookedAt:      entry.BookedAt,
+		Status:        entry.Status,
+		WalletID:      entry.WalletID,
+		TransactionID: entry.TransactionID,
+		Reason:        entry.Reason,
+		CreatedAt:     now,
+		AttemptedAt:   now,
+	}
+}
+
+func newEntryFromMongoEntry(mongoEntry *mongoEntry) *bankimport.Entry {
+	return &bankimport.Entry{
+		ID:            mongoEntry.ID.Hex(),
+		BankReference: mongoEntry.BankReference,
+		Reference:     mongoEntry.Reference,
+		Amount:        mongoEntry.Amount,
+		Currency:      mongoEntry.Currency,
+		BookedAt:      mongoEntry.BookedAt,
+		Status:        mongoEntry.Status,
+		WalletID:      mongoEntry.WalletID,
+		TransactionID: mongoEntry.TransactionID,
+		Reason:        mongoEntry.Reason,
+		CreatedAt:     mongoEntry.CreatedAt,
+		AttemptedAt:   mongoEntry.AttemptedAt,
+	}
+}
@@ -0,0 +1,252 @@
+package bankimport
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCAMT053 = "camt053"
+
+	dateLayout       = "2006-01-02"
+	ofxDateLayout    = "20060102"
+	ofxDateLayoutLen = len(ofxDateLayout)
+	ofxTimeLayout    = "20060102150405"
+	ofxTimeLayoutLen = len(ofxTimeLayout)
+	camtCredit       = "CRDT"
+	camtDebit        = "DBIT"
+)
+
+var (
+	ErrInvalidFormat    = errors.New("format must be csv, ofx or camt053")
+	ErrMalformedFile    = errors.New("bank statement file is malformed")
+	ErrMissingCSVColumn = errors.New("csv file must have bankReference, reference, amount and bookedAt columns")
+)
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxFieldPattern       = regexp.MustCompile(`(?is)<([A-Z0-9.]+)>([^<]*)`)
+	ofxCurrencyPattern    = regexp.MustCompile(`(?is)<CURDEF>([^<]*)`)
+)
+
+func Parse(format string, r io.Reader) ([]*Line, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatOFX:
+		return parseOFX(r)
+	case FormatCAMT053:
+		return parseCAMT053(r)
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
+
+func parseCSV(r io.Reader) ([]*Line, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, ErrMalformedFile
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"bankreference", "reference", "amount", "bookedat"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrMissingCSVColumn
+		}
+	}
+
+	lines := []*Line{}
+	for _, record := range records[1:] {
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[columns["amount"]]), 64)
+		if err != nil {
+			return nil, ErrMalformedFile
+		}
+
+		bookedAt, err := parseCSVDate(strings.TrimSpace(record[columns["bookedat"]]))
+		if err != nil {
+			return nil, ErrMalformedFile
+		}
+
+		line := &Line{
+			BankReference: strings.TrimSpace(record[columns["bankreference"]]),
+			Reference:     strings.TrimSpace(record[columns["reference"]]),
+			Amount:        amount,
+			BookedAt:      bookedAt,
+		}
+		if i, ok := columns["currency"]; ok {
+			line.Currency = strings.TrimSpace(record[i])
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func parseCSVDate(value string) (time.Time, error) {
+	if date, err := time.Parse(dateLayout, value); err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseOFX reads STMTTRN blocks from both SGML (OFX 1.x) and XML (OFX 2.x)
+// files, which is why it does not rely on leaf elements being closed.
+func parseOFX(r io.Reader) ([]*Line, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var currency string
+	if match := ofxCurrencyPattern.FindSubmatch(content); match != nil {
+		currency = strings.TrimSpace(string(match[1]))
+	}
+
+	blocks := ofxTransactionPattern.FindAllSubmatch(content, -1)
+	if blocks == nil && !strings.Contains(strings.ToUpper(string(content)), "<OFX>") {
+		return nil, ErrMalformedFile
+	}
+
+	lines := []*Line{}
+	for _, block := range blocks {
+		fields := map[string]string{}
+		for _, field := range ofxFieldPattern.FindAllSubmatch(block[1], -1) {
+			fields[strings.ToUpper(string(field[1]))] = strings.TrimSpace(string(field[2]))
+		}
+
+		amount, err := strconv.ParseFloat(fields["TRNAMT"], 64)
+		if err != nil {
+			return nil, ErrMalformedFile
+		}
+
+		bookedAt, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			return nil, ErrMalformedFile
+		}
+
+		lines = append(lines, &Line{
+			BankReference: fields["FITID"],
+			Reference:     strings.TrimSpace(fields["NAME"] + " " + fields["MEMO"]),
+			Amount:        amount,
+			Currency:      currency,
+			BookedAt:      bookedAt,
+		})
+	}
+
+	return lines, nil
+}
+
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) >= ofxTimeLayoutLen {
+		if t, err := time.Parse(ofxTimeLayout, value[:ofxTimeLayoutLen]); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(value) < ofxDateLayoutLen {
+		return time.Time{}, ErrMalformedFile
+	}
+
+	return time.Parse(ofxDateLayout, value[:ofxDateLayoutLen])
+}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Amount struct {
+		Currency string `xml:"Ccy,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"Amt"`
+	Indicator   string `xml:"CdtDbtInd"`
+	BookingDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	ServicerRef string `xml:"AcctSvcrRef"`
+	Details     []struct {
+		ServicerRef string   `xml:"Refs>AcctSvcrRef"`
+		EndToEndID  string   `xml:"Refs>EndToEndId"`
+		Remittance  []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+func parseCAMT053(r io.Reader) ([]*Line, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, ErrMalformedFile
+	}
+
+	lines := []*Line{}
+	for _, stmt := range doc.Statements {
+		for _, entry := range stmt.Entries {
+			line, err := newLineFromCAMTEntry(&entry)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+func newLineFromCAMTEntry(entry *camtEntry) (*Line, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(entry.Amount.Value), 64)
+	if err != nil {
+		return nil, ErrMalformedFile
+	}
+	if entry.Indicator == camtDebit {
+		amount = -amount
+	} else if entry.Indicator != camtCredit {
+		return nil, ErrMalformedFile
+	}
+
+	var bookedAt time.Time
+	if entry.BookingDate.DateTime != "" {
+		bookedAt, err = time.Parse(time.RFC3339, entry.BookingDate.DateTime)
+	} else {
+		bookedAt, err = time.Parse(dateLayout, entry.BookingDate.Date)
+	}
+	if err != nil {
+		return nil, ErrMalformedFile
+	}
+
+	line := &Line{
+		BankReference: entry.ServicerRef,
+		Amount:        amount,
+		Currency:      entry.Amount.Currency,
+		BookedAt:      bookedAt,
+	}
+
+	var references []string
+	for _, details := range entry.Details {
+		if line.BankReference == "" {
+			line.BankReference = details.ServicerRef
+		}
+		references = append(references, details.Remittance...)
+		if details.EndToEndID != "" && details.EndToEndID != "NOTPROVIDED" {
+			references = append(references, details.EndToEndID)
+		}
+	}
+	line.Reference = strings.Join(references, " ")
+
+	return line, nil
+}
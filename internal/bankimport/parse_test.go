@@ -0,0 +1,171 @@
+package bankimport_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"github.com/stretchr/testify/assert"
+)
+
+const csvFile = `bankReference,reference,amount,currency,bookedAt
+B1,WALLET 63f000000000000000000001,150.50,TRY,2022-03-01
+B2,rent,-300,TRY,2022-03-02T10:00:00Z
+`
+
+const ofxSGMLFile = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>TRY
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20220301120000.000[+3:TRT]
+<TRNAMT>150.50
+<FITID>B1
+<NAME>JOHN DOE
+<MEMO>63f000000000000000000001
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXMLFile = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>TRY</CURDEF><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20220302</DTPOSTED><TRNAMT>-20.00</TRNAMT><FITID>B2</FITID><NAME>fee</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+
+const camt053File = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <Amt Ccy="TRY">150.50</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2022-03-01</Dt></BookgDt>
+        <AcctSvcrRef>B1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RmtInf><Ustrd>top up 63f000000000000000000001</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="TRY">20.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><DtTm>2022-03-02T10:00:00Z</DtTm></BookgDt>
+        <NtryDtls><TxDtls><Refs><AcctSvcrRef>B2</AcctSvcrRef><EndToEndId>E2E</EndToEndId></Refs></TxDtls></NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestParse(t *testing.T) {
+	march1 := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	march2 := time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		givenFormat   string
+		givenFile     string
+		expectedLines []*bankimport.Line
+	}{
+		{
+			desc:        "csv file, return lines",
+			givenFormat: bankimport.FormatCSV,
+			givenFile:   csvFile,
+			expectedLines: []*bankimport.Line{
+				{BankReference: "B1", Reference: "WALLET 63f000000000000000000001", Amount: 150.5, Currency: "TRY", BookedAt: march1},
+				{BankReference: "B2", Reference: "rent", Amount: -300, Currency: "TRY", BookedAt: march2.Add(10 * time.Hour)},
+			},
+		},
+		{
+			desc:        "sgml ofx file, return lines",
+			givenFormat: bankimport.FormatOFX,
+			givenFile:   ofxSGMLFile,
+			expectedLines: []*bankimport.Line{
+				{BankReference: "B1", Reference: "JOHN DOE 63f000000000000000000001", Amount: 150.5, Currency: "TRY", BookedAt: march1.Add(12 * time.Hour)},
+			},
+		},
+		{
+			desc:        "xml ofx file, return lines",
+			givenFormat: bankimport.FormatOFX,
+			givenFile:   ofxXMLFile,
+			expectedLines: []*bankimport.Line{
+				{BankReference: "B2", Reference: "fee", Amount: -20, Currency: "TRY", BookedAt: march2},
+			},
+		},
+		{
+			desc:        "camt053 file, return lines",
+			givenFormat: bankimport.FormatCAMT053,
+			givenFile:   camt053File,
+			expectedLines: []*bankimport.Line{
+				{BankReference: "B1", Reference: "top up 63f000000000000000000001", Amount: 150.5, Currency: "TRY", BookedAt: march1},
+				{BankReference: "B2", Reference: "E2E", Amount: -20, Currency: "TRY", BookedAt: march2.Add(10 * time.Hour)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			lines, err := bankimport.Parse(tC.givenFormat, strings.NewReader(tC.givenFile))
+
+			assert.Equal(t, tC.expectedLines, lines)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestParseWithInvalidFile(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		givenFormat   string
+		givenFile     string
+		expectedError error
+	}{
+		{
+			desc:          "unsupported format, return error",
+			givenFormat:   "mt940",
+			givenFile:     "",
+			expectedError: bankimport.ErrInvalidFormat,
+		},
+		{
+			desc:          "csv without required columns, return error",
+			givenFormat:   bankimport.FormatCSV,
+			givenFile:     "reference,amount\nx,1\n",
+			expectedError: bankimport.ErrMissingCSVColumn,
+		},
+		{
+			desc:          "csv with invalid amount, return error",
+			givenFormat:   bankimport.FormatCSV,
+			givenFile:     "bankReference,reference,amount,bookedAt\nB1,x,ten,2022-03-01\n",
+			expectedError: bankimport.ErrMalformedFile,
+		},
+		{
+			desc:          "not an ofx file, return error",
+			givenFormat:   bankimport.FormatOFX,
+			givenFile:     "hello",
+			expectedError: bankimport.ErrMalformedFile,
+		},
+		{
+			desc:          "camt053 with unknown indicator, return error",
+			givenFormat:   bankimport.FormatCAMT053,
+			givenFile:     `<Document><BkToCstmrStmt><Stmt><Ntry><Amt>1</Amt><CdtDbtInd>X</CdtDbtInd></Ntry></Stmt></BkToCstmrStmt></Document>`,
+			expectedError: bankimport.ErrMalformedFile,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			lines, err := bankimport.Parse(tC.givenFormat, strings.NewReader(tC.givenFile))
+
+			assert.Nil(t, lines)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
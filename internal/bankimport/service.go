@@ -0,0 +1,199 @@
+package bankimport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/gommon/log"
+)
+
+var (
+	ErrDuplicateBankReference = errors.New("bank reference was already imported")
+	ErrMissingBankReference   = errors.New("bank statement line has no bank reference")
+)
+
+const (
+	reasonNoMatchingWallet = "no wallet matches the reference"
+	reasonUnsupportedCcy   = "currency is not supported"
+)
+
+// PendingTimeout is how long an entry can stay pending before the import
+// working on it is taken to have died and the entry can be retried.
+const PendingTimeout = 5 * time.Minute
+
+type EntryRepository interface {
+	Create(ctx context.Context, entry *Entry) (string, error)
+	// Retry claims the entry with the given bank reference for another
+	// attempt if it failed, or if it has been pending since before
+	// stalePendingBefore, setting it back to pending. It fails with
+	// ErrDuplicateBankReference for every other entry.
+	Retry(ctx context.Context, bankReference string, stalePendingBefore time.Time) (*Entry, error)
+	Update(ctx context.Context, entry *Entry) error
+	ReadByStatus(ctx context.Context, status string, pageNo, pageSize int) ([]*Entry, error)
+}
+
+type WalletService interface {
+	GetWallet(ctx context.Context, id string) (*wallet.Wallet, error)
+	CreateTransaction(ctx context.Context, info *wallet.TransactionCreationInfo) (string, error)
+}
+
+type service struct {
+	er       EntryRepository
+	ws       WalletService
+	currency string
+}
+
+func NewService(er EntryRepository, ws WalletService, currency string) *service {
+	return &service{er, ws, currency}
+}
+
+func (s *service) Import(ctx context.Context, format string, r io.Reader) (*Result, error) {
+	lines, err := Parse(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if line.BankReference == "" {
+			return nil, ErrMissingBankReference
+		}
+	}
+
+	result := &Result{Entries: []*Entry{}}
+	for _, line := range lines {
+		// only incoming transfers become deposits
+		if line.Amount <= 0 {
+			result.Ignored++
+			continue
+		}
+
+		entry, err := s.importLine(ctx, line)
+		if errors.Is(err, ErrDuplicateBankReference) {
+			result.Duplicates++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if entry.Status == StatusMatched {
+			result.Matched++
+		} else {
+			result.Review++
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+func (s *service) GetReviewEntries(ctx context.Context, pageNo, pageSize int) ([]*Entry, error) {
+	return s.er.ReadByStatus(ctx, StatusReview, pageNo, pageSize)
+}
+
+// importLine marks the entry failed when the deposit errors, so that
+// importing the file again retries it instead of skipping it as a duplicate.
+// The deposit is stored with the id of the entry, so a retry of one that went
+// through after all finds it instead of depositing again.
+func (s *service) importLine(ctx context.Context, line *Line) (*Entry, error) {
+	entry, err := s.claimEntry(ctx, line)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.deposit(ctx, entry); err != nil {
+		entry.Status, entry.Reason = StatusFailed, err.Error()
+		if updateErr := s.er.Update(ctx, entry); updateErr != nil {
+			log.Error(updateErr)
+		}
+		return nil, err
+	}
+
+	return entry, s.er.Update(ctx, entry)
+}
+
+func (s *service) claimEntry(ctx context.Context, line *Line) (*Entry, error) {
+	entry := &Entry{
+		BankReference: line.BankReference,
+		Reference:     line.Reference,
+		Amount:        line.Amount,
+		Currency:      line.Currency,
+		BookedAt:      line.BookedAt,
+		Status:        StatusPending,
+	}
+
+	id, err := s.er.Create(ctx, entry)
+	if errors.Is(err, ErrDuplicateBankReference) {
+		return s.er.Retry(ctx, line.BankReference, time.Now().Add(-PendingTimeout))
+	} else if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+
+	return entry, nil
+}
+
+func (s *service) deposit(ctx context.Context, entry *Entry) error {
+	if entry.Currency != "" && s.currency != "" && !strings.EqualFold(entry.Currency, s.currency) {
+		entry.Status, entry.Reason = StatusReview, reasonUnsupportedCcy
+		return nil
+	}
+
+	w, err := s.matchWallet(ctx, entry.Reference)
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		entry.Status, entry.Reason = StatusReview, reasonNoMatchingWallet
+		return nil
+	}
+	entry.WalletID = w.ID
+
+	txnID, err := s.ws.CreateTransaction(ctx, &wallet.TransactionCreationInfo{
+		ID:              entry.ID,
+		WalletID:        w.ID,
+		TransactionType: wallet.Deposit,
+		Amount:          entry.Amount,
+	})
+	if errors.Is(err, wallet.ErrDuplicateTransaction) {
+		txnID, err = entry.ID, nil
+	}
+	if err != nil && isRejectedDeposit(err) {
+		entry.Status, entry.Reason = StatusReview, err.Error()
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	entry.Status, entry.TransactionID = StatusMatched, txnID
+	return nil
+}
+
+// matchWallet looks for a wallet id among the words of the reference, since
+// banks usually put the payer's free text around it.
+func (s *service) matchWallet(ctx context.Context, reference string) (*wallet.Wallet, error) {
+	for _, candidate := range strings.Fields(reference) {
+		w, err := s.ws.GetWallet(ctx, strings.Trim(candidate, ".,;:/"))
+		if errors.Is(err, wallet.ErrWalletNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	}
+
+	return nil, nil
+}
+
+func isRejectedDeposit(err error) bool {
+	return wallet.ContainsError(err, []error{
+		wallet.ErrAboveMaximumBalanceLimit,
+		wallet.ErrAboveMaximumTransactionLimit,
+		wallet.ErrBelowMinimumTransactionLimit,
+		wallet.ErrWalletBalanceUpdateFailed,
+	})
+}
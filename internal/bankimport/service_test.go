@@ -0,0 +1,224 @@
+package bankimport_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"github.com/gokcelb/wallet-api/internal/bankimport/mock"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockEntryRepository(t *testing.T) *mock.MockEntryRepository {
+	return mock.NewMockEntryRepository(gomock.NewController(t))
+}
+
+func createMockWalletService(t *testing.T) *mock.MockWalletService {
+	return mock.NewMockWalletService(gomock.NewController(t))
+}
+
+func TestServiceImport(t *testing.T) {
+	mockEntryRepository := createMockEntryRepository(t)
+	mockWalletService := createMockWalletService(t)
+	s := bankimport.NewService(mockEntryRepository, mockWalletService, "TRY")
+
+	file := `bankReference,reference,amount,currency,bookedAt
+B1,payment for 63f000000000000000000001.,100,TRY,2022-03-01
+B2,unknown payer,50,TRY,2022-03-01
+B3,63f000000000000000000001,75,TRY,2022-03-01
+B4,63f000000000000000000001,-20,TRY,2022-03-01
+B5,63f000000000000000000001,10,EUR,2022-03-01
+`
+	matchedWallet := &wallet.Wallet{ID: "63f000000000000000000001"}
+
+	gomock.InOrder(
+		mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("1", nil),
+		mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("2", nil),
+		mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("", bankimport.ErrDuplicateBankReference),
+		mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("5", nil),
+	)
+	mockEntryRepository.EXPECT().
+		Retry(context.TODO(), "B3", gomock.Any()).
+		Return(nil, bankimport.ErrDuplicateBankReference)
+	mockEntryRepository.EXPECT().Update(context.TODO(), gomock.Any()).Return(nil).Times(3)
+
+	mockWalletService.EXPECT().GetWallet(context.TODO(), "payment").Return(nil, wallet.ErrWalletNotFound)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), "for").Return(nil, wallet.ErrWalletNotFound)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), "63f000000000000000000001").Return(matchedWallet, nil)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), "unknown").Return(nil, wallet.ErrWalletNotFound)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), "payer").Return(nil, wallet.ErrWalletNotFound)
+
+	mockWalletService.EXPECT().
+		CreateTransaction(context.TODO(), &wallet.TransactionCreationInfo{
+			ID:              "1",
+			WalletID:        matchedWallet.ID,
+			TransactionType: wallet.Deposit,
+			Amount:          100,
+		}).
+		Return("txn1", nil)
+
+	result, err := s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, 2, result.Review)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Equal(t, 1, result.Ignored)
+	assert.Len(t, result.Entries, 3)
+
+	assert.Equal(t, bankimport.StatusMatched, result.Entries[0].Status)
+	assert.Equal(t, "txn1", result.Entries[0].TransactionID)
+	assert.Equal(t, matchedWallet.ID, result.Entries[0].WalletID)
+	assert.Equal(t, bankimport.StatusReview, result.Entries[1].Status)
+	assert.Equal(t, "B2", result.Entries[1].BankReference)
+	assert.Equal(t, bankimport.StatusReview, result.Entries[2].Status)
+	assert.Equal(t, "B5", result.Entries[2].BankReference)
+}
+
+func TestServiceImportRejectedDeposit(t *testing.T) {
+	mockEntryRepository := createMockEntryRepository(t)
+	mockWalletService := createMockWalletService(t)
+	s := bankimport.NewService(mockEntryRepository, mockWalletService, "TRY")
+
+	file := "bankReference,reference,amount,bookedAt\nB1,63f000000000000000000001,100000,2022-03-01\n"
+
+	mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("1", nil)
+	mockWalletService.EXPECT().
+		GetWallet(context.TODO(), "63f000000000000000000001").
+		Return(&wallet.Wallet{ID: "63f000000000000000000001"}, nil)
+	mockWalletService.EXPECT().
+		CreateTransaction(context.TODO(), gomock.Any()).
+		Return("", wallet.ErrAboveMaximumBalanceLimit)
+	mockEntryRepository.EXPECT().
+		Update(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *bankimport.Entry) error {
+			assert.Equal(t, bankimport.StatusReview, entry.Status)
+			assert.Equal(t, wallet.ErrAboveMaximumBalanceLimit.Error(), entry.Reason)
+			return nil
+		})
+
+	result, err := s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Review)
+}
+
+func TestServiceImportFailedDepositIsRetried(t *testing.T) {
+	mockEntryRepository := createMockEntryRepository(t)
+	mockWalletService := createMockWalletService(t)
+	s := bankimport.NewService(mockEntryRepository, mockWalletService, "TRY")
+
+	file := "bankReference,reference,amount,bookedAt\nB1,63f000000000000000000001,100,2022-03-01\n"
+	matchedWallet := &wallet.Wallet{ID: "63f000000000000000000001"}
+	depositErr := errors.New("connection reset")
+
+	mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("1", nil)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), matchedWallet.ID).Return(matchedWallet, nil)
+	mockWalletService.EXPECT().CreateTransaction(context.TODO(), gomock.Any()).Return("", depositErr)
+	mockEntryRepository.EXPECT().
+		Update(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *bankimport.Entry) error {
+			assert.Equal(t, "1", entry.ID)
+			assert.Equal(t, bankimport.StatusFailed, entry.Status)
+			assert.Equal(t, depositErr.Error(), entry.Reason)
+			return nil
+		})
+
+	result, err := s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, depositErr)
+
+	mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("", bankimport.ErrDuplicateBankReference)
+	mockEntryRepository.EXPECT().
+		Retry(context.TODO(), "B1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, stalePendingBefore time.Time) (*bankimport.Entry, error) {
+			assert.WithinDuration(t, time.Now().Add(-bankimport.PendingTimeout), stalePendingBefore, time.Second)
+			return &bankimport.Entry{
+				ID:            "1",
+				BankReference: "B1",
+				Reference:     matchedWallet.ID,
+				Amount:        100,
+				Status:        bankimport.StatusPending,
+			}, nil
+		})
+	mockWalletService.EXPECT().GetWallet(context.TODO(), matchedWallet.ID).Return(matchedWallet, nil)
+	mockWalletService.EXPECT().CreateTransaction(context.TODO(), gomock.Any()).Return("txn1", nil)
+	mockEntryRepository.EXPECT().Update(context.TODO(), gomock.Any()).Return(nil)
+
+	result, err = s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, 0, result.Duplicates)
+	assert.Equal(t, "txn1", result.Entries[0].TransactionID)
+}
+
+func TestServiceImportRetriedDepositThatWentThrough(t *testing.T) {
+	mockEntryRepository := createMockEntryRepository(t)
+	mockWalletService := createMockWalletService(t)
+	s := bankimport.NewService(mockEntryRepository, mockWalletService, "TRY")
+
+	file := "bankReference,reference,amount,bookedAt\nB1,63f000000000000000000001,100,2022-03-01\n"
+	matchedWallet := &wallet.Wallet{ID: "63f000000000000000000001"}
+
+	mockEntryRepository.EXPECT().Create(context.TODO(), gomock.Any()).Return("", bankimport.ErrDuplicateBankReference)
+	mockEntryRepository.EXPECT().
+		Retry(context.TODO(), "B1", gomock.Any()).
+		Return(&bankimport.Entry{
+			ID:            "63f000000000000000000002",
+			BankReference: "B1",
+			Reference:     matchedWallet.ID,
+			Amount:        100,
+			Status:        bankimport.StatusPending,
+		}, nil)
+	mockWalletService.EXPECT().GetWallet(context.TODO(), matchedWallet.ID).Return(matchedWallet, nil)
+	mockWalletService.EXPECT().
+		CreateTransaction(context.TODO(), &wallet.TransactionCreationInfo{
+			ID:              "63f000000000000000000002",
+			WalletID:        matchedWallet.ID,
+			TransactionType: wallet.Deposit,
+			Amount:          100,
+		}).
+		Return("", wallet.ErrDuplicateTransaction)
+	mockEntryRepository.EXPECT().Update(context.TODO(), gomock.Any()).Return(nil)
+
+	result, err := s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, bankimport.StatusMatched, result.Entries[0].Status)
+	assert.Equal(t, "63f000000000000000000002", result.Entries[0].TransactionID)
+}
+
+func TestServiceImportWithoutBankReference(t *testing.T) {
+	s := bankimport.NewService(createMockEntryRepository(t), createMockWalletService(t), "TRY")
+
+	file := "bankReference,reference,amount,bookedAt\n,63f000000000000000000001,100,2022-03-01\n"
+
+	result, err := s.Import(context.TODO(), bankimport.FormatCSV, strings.NewReader(file))
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, bankimport.ErrMissingBankReference)
+}
+
+func TestServiceGetReviewEntries(t *testing.T) {
+	mockEntryRepository := createMockEntryRepository(t)
+	s := bankimport.NewService(mockEntryRepository, nil, "TRY")
+
+	mockEntries := []*bankimport.Entry{{ID: "1", BankReference: "B1", Status: bankimport.StatusReview}}
+
+	mockEntryRepository.EXPECT().
+		ReadByStatus(context.TODO(), bankimport.StatusReview, wallet.DefaultPageNo, wallet.DefaultPageSize).
+		Return(mockEntries, nil)
+
+	entries, err := s.GetReviewEntries(context.TODO(), wallet.DefaultPageNo, wallet.DefaultPageSize)
+
+	assert.Equal(t, mockEntries, entries)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/challenge"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Memory struct {
+	mu         sync.RWMutex
+	challenges map[string]*challenge.Challenge
+}
+
+func NewMemory() *Memory {
+	return &Memory{challenges: make(map[string]*challenge.Challenge)}
+}
+
+func (m *Memory) Create(ctx context.Context, c *challenge.Challenge) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *c
+	stored.ID = primitive.NewObjectID().Hex()
+	stored.FailedAt = append([]time.Time(nil), c.FailedAt...)
+	m.challenges[stored.ID] = &stored
+
+	return stored.ID, nil
+}
+
+func (m *Memory) Read(ctx context.Context, id string) (*challenge.Challenge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.challenges[id]
+	if !ok {
+		return nil, challenge.ErrChallengeNotFound
+	}
+
+	found := *c
+	found.FailedAt = append([]time.Time(nil), c.FailedAt...)
+	return &found, nil
+}
+
+func (m *Memory) ReadLatestByWalletID(ctx context.Context, walletID string) (*challenge.Challenge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var latest *challenge.Challenge
+	for _, c := range m.challenges {
+		if c.WalletID == walletID && (latest == nil || c.CreatedAt.After(latest.CreatedAt)) {
+			latest = c
+		}
+	}
+
+	if latest == nil {
+		return nil, challenge.ErrChallengeNotFound
+	}
+
+	found := *latest
+	found.FailedAt = append([]time.Time(nil), latest.FailedAt...)
+	return &found, nil
+}
+
+func (m *Memory) IncrementAttempts(ctx context.Context, id string) (int, error) {
+	var attempts int
+	err := m.updatePending(id, func(c *challenge.Challenge) {
+		c.Attempts++
+		attempts = c.Attempts
+	})
+
+	return attempts, err
+}
+
+func (m *Memory) AddFailedAttempt(ctx context.Context, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.challenges[id]
+	if !ok {
+		return challenge.ErrChallengeNotFound
+	}
+	c.FailedAt = append(c.FailedAt, at)
+
+	return nil
+}
+
+func (m *Memory) CountFailedAttempts(ctx context.Context, walletID string, since time.Time) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var failures int
+	for _, c := range m.challenges {
+		if c.WalletID != walletID {
+			continue
+		}
+
+		for _, at := range c.FailedAt {
+			if !at.Before(since) {
+				failures++
+			}
+		}
+	}
+
+	return failures, nil
+}
+
+func (m *Memory) Complete(ctx context.Context, id string) error {
+	return m.updatePending(id, func(c *challenge.Challenge) {
+		c.Status = challenge.StatusCompleted
+	})
+}
+
+func (m *Memory) Lock(ctx context.Context, id string, until time.Time) error {
+	return m.updatePending(id, func(c *challenge.Challenge) {
+		c.Status = challenge.StatusLocked
+		c.LockedUntil = until
+	})
+}
+
+func (m *Memory) Reopen(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.challenges[id]
+	if !ok {
+		return challenge.ErrChallengeNotFound
+	}
+
+	if c.Status != challenge.StatusCompleted {
+		return challenge.ErrChallengeNotCompleted
+	}
+	c.Status = challenge.StatusPending
+
+	return nil
+}
+
+func (m *Memory) updatePending(id string, fn func(*challenge.Challenge)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.challenges[id]
+	if !ok {
+		return challenge.ErrChallengeNotFound
+	}
+
+	if c.Status != challenge.StatusPending {
+		return challenge.ErrChallengeNotPending
+	}
+	fn(c)
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/challenge (interfaces: ChallengeRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	challenge "github.com/gokcelb/wallet-api/internal/challenge"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockChallengeRepository is a mock of ChallengeRepository interface.
+type MockChallengeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockChallengeRepositoryMockRecorder
+}
+
+// MockChallengeRepositoryMockRecorder is the mock recorder for MockChallengeRepository.
+type MockChallengeRepositoryMockRecorder struct {
+	mock *MockChallengeRepository
+}
+
+// NewMockChallengeRepository creates a new mock instance.
+func NewMockChallengeRepository(ctrl *gomock.Controller) *MockChallengeRepository {
+	mock := &MockChallengeRepository{ctrl: ctrl}
+	mock.recorder = &MockChallengeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChallengeRepository) EXPECT() *MockChallengeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFailedAttempt mocks base method.
+func (m *MockChallengeRepository) AddFailedAttempt(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailedAttempt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFailedAttempt indicates an expected call of AddFailedAttempt.
+func (mr *MockChallengeRepositoryMockRecorder) AddFailedAttempt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailedAttempt", reflect.TypeOf((*MockChallengeRepository)(nil).AddFailedAttempt), arg0, arg1, arg2)
+}
+
+// Complete mocks base method.
+func (m *MockChallengeRepository) Complete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockChallengeRepositoryMockRecorder) Complete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockChallengeRepository)(nil).Complete), arg0, arg1)
+}
+
+// CountFailedAttempts mocks base method.
+func (m *MockChallengeRepository) CountFailedAttempts(arg0 context.Context, arg1 string, arg2 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFailedAttempts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFailedAttempts indicates an expected call of CountFailedAttempts.
+func (mr *MockChallengeRepositoryMockRecorder) CountFailedAttempts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFailedAttempts", reflect.TypeOf((*MockChallengeRepository)(nil).CountFailedAttempts), arg0, arg1, arg2)
+}
+
+// Create mocks base method.
+func (m *MockChallengeRepository) Create(arg0 context.Context, arg1 *challenge.Challenge) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockChallengeRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChallengeRepository)(nil).Create), arg0, arg1)
+}
+
+// IncrementAttempts mocks base method.
+func (m *MockChallengeRepository) IncrementAttempts(arg0 context.Context, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementAttempts", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementAttempts indicates an expected call of IncrementAttempts.
+func (mr *MockChallengeRepositoryMockRecorder) IncrementAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementAttempts", reflect.TypeOf((*MockChallengeRepository)(nil).IncrementAttempts), arg0, arg1)
+}
+
+// Lock mocks base method.
+func (m *MockChallengeRepository) Lock(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockChallengeRepositoryMockRecorder) Lock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockChallengeRepository)(nil).Lock), arg0, arg1, arg2)
+}
+
+// Read mocks base method.
+func (m *MockChallengeRepository) Read(arg0 context.Context, arg1 string) (*challenge.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", arg0, arg1)
+	ret0, _ := ret[0].(*challenge.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockChallengeRepositoryMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockChallengeRepository)(nil).Read), arg0, arg1)
+}
+
+// ReadLatestByWalletID mocks base method.
+func (m *MockChallengeRepository) ReadLatestByWalletID(arg0 context.Context, arg1 string) (*challenge.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLatestByWalletID", arg0, arg1)
+	ret0, _ := ret[0].(*challenge.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLatestByWalletID indicates an expected call of ReadLatestByWalletID.
+func (mr *MockChallengeRepositoryMockRecorder) ReadLatestByWalletID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLatestByWalletID", reflect.TypeOf((*MockChallengeRepository)(nil).ReadLatestByWalletID), arg0, arg1)
+}
+
+// Reopen mocks base method.
+func (m *MockChallengeRepository) Reopen(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reopen", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reopen indicates an expected call of Reopen.
+func (mr *MockChallengeRepositoryMockRecorder) Reopen(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reopen", reflect.TypeOf((*MockChallengeRepository)(nil).Reopen), arg0, arg1)
+}
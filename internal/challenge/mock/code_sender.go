@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/challenge (interfaces: CodeSender)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCodeSender is a mock of CodeSender interface.
+type MockCodeSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeSenderMockRecorder
+}
+
+// MockCodeSenderMockRecorder is the mock recorder for MockCodeSender.
+type MockCodeSenderMockRecorder struct {
+	mock *MockCodeSender
+}
+
+// NewMockCodeSender creates a new mock instance.
+func NewMockCodeSender(ctrl *gomock.Controller) *MockCodeSender {
+	mock := &MockCodeSender{ctrl: ctrl}
+	mock.recorder = &MockCodeSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeSender) EXPECT() *MockCodeSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockCodeSender) Send(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockCodeSenderMockRecorder) Send(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeSender)(nil).Send), arg0, arg1, arg2)
+}
@@ -0,0 +1,26 @@
+package challenge
+
+import "time"
+
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusLocked    = "locked"
+)
+
+// Challenge holds a transaction back until the user proves it with the
+// one-time code sent to them.
+type Challenge struct {
+	ID              string    `json:"id"`
+	WalletID        string    `json:"walletId"`
+	TransactionType string    `json:"type"`
+	Amount          float64   `json:"amount"`
+	CodeHash        string    `json:"-"`
+	Attempts        int       `json:"attempts"`
+	Status          string    `json:"status"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	LockedUntil     time.Time `json:"-"`
+	CreatedAt       time.Time `json:"createdAt"`
+	// FailedAt holds when each incorrect code was entered.
+	FailedAt []time.Time `json:"-"`
+}
@@ -0,0 +1,17 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var indexes = []mongo.IndexModel{
+	{Keys: bson.D{bson.E{Key: "wallet_id", Value: 1}, bson.E{Key: "created_at", Value: -1}}},
+}
+
+func (m *Mongo) CreateIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
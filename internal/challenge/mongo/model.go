@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoChallenge struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	WalletID        string             `bson:"wallet_id"`
+	TransactionType string             `bson:"transaction_type"`
+	Amount          float64            `bson:"amount"`
+	CodeHash        string             `bson:"code_hash"`
+	Attempts        int                `bson:"attempts"`
+	Status          string             `bson:"status"`
+	ExpiresAt       time.Time          `bson:"expires_at"`
+	LockedUntil     time.Time          `bson:"locked_until"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	FailedAt        []time.Time        `bson:"failed_at,omitempty"`
+}
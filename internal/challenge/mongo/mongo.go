@@ -0,0 +1,221 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/challenge"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Create(ctx context.Context, c *challenge.Challenge) (string, error) {
+	mongoChallenge := newMongoChallengeFromChallenge(c)
+	_, err := m.collection.InsertOne(ctx, mongoChallenge)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return mongoChallenge.ID.Hex(), nil
+}
+
+func (m *Mongo) Read(ctx context.Context, id string) (*challenge.Challenge, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, challenge.ErrChallengeNotFound
+	}
+
+	return m.readOne(ctx, bson.M{"_id": objectID}, options.FindOne())
+}
+
+func (m *Mongo) ReadLatestByWalletID(ctx context.Context, walletID string) (*challenge.Challenge, error) {
+	return m.readOne(ctx, bson.M{"wallet_id": walletID}, options.FindOne().SetSort(bson.M{"created_at": -1}))
+}
+
+func (m *Mongo) IncrementAttempts(ctx context.Context, id string) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, challenge.ErrChallengeNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "status": challenge.StatusPending}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var mongoChallenge mongoChallenge
+	err = m.collection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"attempts": 1}}, opts).Decode(&mongoChallenge)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, challenge.ErrChallengeNotPending
+	} else if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	return mongoChallenge.Attempts, nil
+}
+
+func (m *Mongo) AddFailedAttempt(ctx context.Context, id string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return challenge.ErrChallengeNotFound
+	}
+
+	result, err := m.collection.UpdateByID(ctx, objectID, bson.M{"$push": bson.M{"failed_at": at}})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return challenge.ErrChallengeNotFound
+	}
+
+	return nil
+}
+
+// CountFailedAttempts only looks at challenges that had not expired by
+// since, as codes cannot be entered on expired ones.
+func (m *Mongo) CountFailedAttempts(ctx context.Context, walletID string, since time.Time) (int, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{bson.E{Key: "$match", Value: bson.M{
+			"wallet_id":  walletID,
+			"expires_at": bson.M{"$gte": since},
+		}}},
+		bson.D{bson.E{Key: "$project", Value: bson.M{
+			"failures": bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$failed_at", bson.A{}}},
+				"as":    "at",
+				"cond":  bson.M{"$gte": bson.A{"$$at", since}},
+			}}},
+		}}},
+		bson.D{bson.E{Key: "$group", Value: bson.M{
+			"_id":      nil,
+			"failures": bson.M{"$sum": "$failures"},
+		}}},
+	}
+
+	cursor, err := m.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	var results []struct {
+		Failures int `bson:"failures"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	return results[0].Failures, nil
+}
+
+func (m *Mongo) Complete(ctx context.Context, id string) error {
+	return m.updatePending(ctx, id, bson.M{"status": challenge.StatusCompleted})
+}
+
+func (m *Mongo) Lock(ctx context.Context, id string, until time.Time) error {
+	return m.updatePending(ctx, id, bson.M{"status": challenge.StatusLocked, "locked_until": until})
+}
+
+func (m *Mongo) Reopen(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return challenge.ErrChallengeNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "status": challenge.StatusCompleted}
+	result, err := m.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": challenge.StatusPending}})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return challenge.ErrChallengeNotCompleted
+	}
+
+	return nil
+}
+
+func (m *Mongo) readOne(ctx context.Context, filter bson.M, opts *options.FindOneOptions) (*challenge.Challenge, error) {
+	var mongoChallenge mongoChallenge
+	err := m.collection.FindOne(ctx, filter, opts).Decode(&mongoChallenge)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, challenge.ErrChallengeNotFound
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newChallengeFromMongoChallenge(&mongoChallenge), nil
+}
+
+func (m *Mongo) updatePending(ctx context.Context, id string, fields bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return challenge.ErrChallengeNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "status": challenge.StatusPending}
+	result, err := m.collection.UpdateOne(ctx, filter, bson.M{"$set": fields})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return challenge.ErrChallengeNotPending
+	}
+
+	return nil
+}
+
+func newMongoChallengeFromChallenge(c *challenge.Challenge) *mongoChallenge {
+	return &mongoChallenge{
+		ID:              primitive.NewObjectID(),
+		WalletID:        c.WalletID,
+		TransactionType: c.TransactionType,
+		Amount:          c.Amount,
+		CodeHash:        c.CodeHash,
+		Attempts:        c.Attempts,
+		Status:          c.Status,
+		ExpiresAt:       c.ExpiresAt,
+		LockedUntil:     c.LockedUntil,
+		CreatedAt:       c.CreatedAt,
+		FailedAt:        c.FailedAt,
+	}
+}
+
+func newChallengeFromMongoChallenge(mongoChallenge *mongoChallenge) *challenge.Challenge {
+	return &challenge.Challenge{
+		ID:              mongoChallenge.ID.Hex(),
+		WalletID:        mongoChallenge.WalletID,
+		TransactionType: mongoChallenge.TransactionType,
+		Amount:          mongoChallenge.Amount,
+		CodeHash:        mongoChallenge.CodeHash,
+		Attempts:        mongoChallenge.Attempts,
+		Status:          mongoChallenge.Status,
+		ExpiresAt:       mongoChallenge.ExpiresAt,
+		LockedUntil:     mongoChallenge.LockedUntil,
+		CreatedAt:       mongoChallenge.CreatedAt,
+		FailedAt:        mongoChallenge.FailedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package challenge
+
+import (
+	"context"
+
+	"github.com/labstack/gommon/log"
+)
+
+// logSender writes codes to the log instead of delivering them. It is meant
+// for development; production deployments should plug in a real CodeSender.
+type logSender struct{}
+
+func NewLogSender() *logSender {
+	return &logSender{}
+}
+
+func (s *logSender) Send(ctx context.Context, walletID, code string) error {
+	log.Infof("one-time code for wallet %s: %s", walletID, code)
+	return nil
+}
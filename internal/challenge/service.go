@@ -0,0 +1,220 @@
+package challenge
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const codeDigits = 6
+
+var (
+	ErrChallengeNotFound     = errors.New("no challenge with the given id exists")
+	ErrChallengeExpired      = errors.New("challenge has expired")
+	ErrChallengeNotPending   = errors.New("challenge is already completed")
+	ErrInvalidCode           = errors.New("challenge code is incorrect")
+	ErrLockedOut             = errors.New("too many incorrect codes, try again later")
+	ErrChallengeNotCompleted = errors.New("challenge is not completed")
+)
+
+type ChallengeRepository interface {
+	Create(ctx context.Context, c *Challenge) (string, error)
+	Read(ctx context.Context, id string) (*Challenge, error)
+	ReadLatestByWalletID(ctx context.Context, walletID string) (*Challenge, error)
+	// IncrementAttempts returns the attempts made on a pending challenge,
+	// including this one.
+	IncrementAttempts(ctx context.Context, id string) (int, error)
+	AddFailedAttempt(ctx context.Context, id string, at time.Time) error
+	// CountFailedAttempts counts the incorrect codes entered on any challenge
+	// of the wallet since the given time.
+	CountFailedAttempts(ctx context.Context, walletID string, since time.Time) (int, error)
+	// Complete and Lock only apply to pending challenges, returning
+	// ErrChallengeNotPending otherwise.
+	Complete(ctx context.Context, id string) error
+	Lock(ctx context.Context, id string, until time.Time) error
+	// Reopen returns a completed challenge to pending, returning
+	// ErrChallengeNotCompleted otherwise.
+	Reopen(ctx context.Context, id string) error
+}
+
+// CodeSender delivers one-time codes to the owner of a wallet, e.g. by SMS.
+type CodeSender interface {
+	Send(ctx context.Context, walletID, code string) error
+}
+
+type service struct {
+	cr   ChallengeRepository
+	cs   CodeSender
+	conf config.StepUpConf
+}
+
+func NewService(cr ChallengeRepository, cs CodeSender, conf config.StepUpConf) *service {
+	return &service{cr, cs, conf}
+}
+
+func (s *service) CreateChallenge(ctx context.Context, walletID, txnType string, amount float64) (*Challenge, error) {
+	if err := s.checkLockout(ctx, walletID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &Challenge{
+		WalletID:        walletID,
+		TransactionType: txnType,
+		Amount:          amount,
+		CodeHash:        string(codeHash),
+		Status:          StatusPending,
+		ExpiresAt:       now.Add(time.Minute * time.Duration(s.conf.ValidityDurationInMin)),
+		CreatedAt:       now,
+	}
+
+	id, err := s.cr.Create(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	c.ID = id
+
+	if err = s.cs.Send(ctx, walletID, code); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// VerifyChallenge completes the challenge if the code is right. The attempt
+// that uses up the allowed attempts, on the challenge or across the recent
+// challenges of the wallet, locks the challenge, which keeps new challenges
+// from being created for the wallet until the lockout ends.
+func (s *service) VerifyChallenge(ctx context.Context, id, walletID, code string) (*Challenge, error) {
+	c, err := s.cr.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.WalletID != walletID {
+		return nil, ErrChallengeNotFound
+	}
+
+	now := time.Now()
+	if c.Status == StatusLocked {
+		return nil, ErrLockedOut
+	} else if c.Status != StatusPending {
+		return nil, ErrChallengeNotPending
+	}
+
+	if now.After(c.ExpiresAt) {
+		return nil, ErrChallengeExpired
+	}
+
+	if err = s.checkLockout(ctx, walletID, now); err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.cr.IncrementAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Attempts = attempts
+
+	if attempts > s.conf.MaxAttempts {
+		return nil, ErrLockedOut
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(c.CodeHash), []byte(code)); err != nil {
+		if err = s.cr.AddFailedAttempt(ctx, id, now); err != nil {
+			return nil, err
+		}
+
+		failures, err := s.cr.CountFailedAttempts(ctx, walletID, now.Add(-s.failureWindow()))
+		if err != nil {
+			return nil, err
+		}
+
+		if attempts < s.conf.MaxAttempts && failures < s.conf.MaxAttempts {
+			return nil, ErrInvalidCode
+		}
+
+		err = s.cr.Lock(ctx, id, now.Add(time.Minute*time.Duration(s.conf.LockoutDurationInMin)))
+		if err != nil && !errors.Is(err, ErrChallengeNotPending) {
+			return nil, err
+		}
+		return nil, ErrLockedOut
+	}
+
+	if err = s.cr.Complete(ctx, id); err != nil {
+		return nil, err
+	}
+	c.Status = StatusCompleted
+
+	return c, nil
+}
+
+// ReleaseChallenge returns a completed challenge to pending, so that its code
+// can be entered again when the transaction it held back was not created.
+func (s *service) ReleaseChallenge(ctx context.Context, id string) error {
+	return s.cr.Reopen(ctx, id)
+}
+
+func (s *service) checkLockout(ctx context.Context, walletID string, now time.Time) error {
+	latest, err := s.cr.ReadLatestByWalletID(ctx, walletID)
+	if err != nil && errors.Is(err, ErrChallengeNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if latest.Status == StatusLocked && now.Before(latest.LockedUntil) {
+		return ErrLockedOut
+	}
+
+	// Attempts are also counted across challenges, or else new challenges
+	// would give fresh attempts at guessing.
+	failures, err := s.cr.CountFailedAttempts(ctx, walletID, now.Add(-s.failureWindow()))
+	if err != nil {
+		return err
+	}
+
+	if failures >= s.conf.MaxAttempts {
+		return ErrLockedOut
+	}
+
+	return nil
+}
+
+func (s *service) failureWindow() time.Duration {
+	if s.conf.FailureWindowInMin <= 0 {
+		return time.Minute * time.Duration(s.conf.LockoutDurationInMin)
+	}
+
+	return time.Minute * time.Duration(s.conf.FailureWindowInMin)
+}
+
+func newCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
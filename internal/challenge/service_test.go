@@ -0,0 +1,259 @@
+package challenge_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/challenge"
+	"github.com/gokcelb/wallet-api/internal/challenge/memory"
+	"github.com/gokcelb/wallet-api/internal/challenge/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func createMockChallengeRepository(t *testing.T) *mock.MockChallengeRepository {
+	return mock.NewMockChallengeRepository(gomock.NewController(t))
+}
+
+func createMockCodeSender(t *testing.T) *mock.MockCodeSender {
+	return mock.NewMockCodeSender(gomock.NewController(t))
+}
+
+func getConf() config.StepUpConf {
+	return config.StepUpConf{ThresholdAmount: 1000, ValidityDurationInMin: 5, MaxAttempts: 3, LockoutDurationInMin: 30}
+}
+
+func newPendingChallenge(code string) *challenge.Challenge {
+	codeHash, _ := bcrypt.GenerateFromPassword([]byte(code), bcrypt.MinCost)
+	return &challenge.Challenge{
+		ID:              "c1",
+		WalletID:        "1",
+		TransactionType: "withdrawal",
+		Amount:          2000,
+		CodeHash:        string(codeHash),
+		Status:          challenge.StatusPending,
+		ExpiresAt:       time.Now().Add(time.Minute),
+	}
+}
+
+func TestServiceCreateChallenge(t *testing.T) {
+	mockChallengeRepository := createMockChallengeRepository(t)
+	mockCodeSender := createMockCodeSender(t)
+	s := challenge.NewService(mockChallengeRepository, mockCodeSender, getConf())
+
+	var stored *challenge.Challenge
+	var sentCode string
+
+	mockChallengeRepository.EXPECT().
+		ReadLatestByWalletID(context.TODO(), "1").
+		Return(&challenge.Challenge{Status: challenge.StatusCompleted}, nil)
+	mockChallengeRepository.EXPECT().
+		CountFailedAttempts(context.TODO(), "1", gomock.Any()).
+		Return(2, nil)
+	mockChallengeRepository.EXPECT().
+		Create(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c *challenge.Challenge) (string, error) {
+			stored = c
+			return "c1", nil
+		})
+	mockCodeSender.EXPECT().
+		Send(context.TODO(), "1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, code string) error {
+			sentCode = code
+			return nil
+		})
+
+	c, err := s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "c1", c.ID)
+	assert.Equal(t, challenge.StatusPending, c.Status)
+	assert.Len(t, sentCode, 6)
+	assert.NotContains(t, stored.CodeHash, sentCode)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(stored.CodeHash), []byte(sentCode)))
+}
+
+func TestServiceCreateChallengeWhileLockedOut(t *testing.T) {
+	mockChallengeRepository := createMockChallengeRepository(t)
+	s := challenge.NewService(mockChallengeRepository, createMockCodeSender(t), getConf())
+
+	mockChallengeRepository.EXPECT().
+		ReadLatestByWalletID(context.TODO(), "1").
+		Return(&challenge.Challenge{Status: challenge.StatusLocked, LockedUntil: time.Now().Add(time.Minute)}, nil)
+
+	c, err := s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+
+	assert.Nil(t, c)
+	assert.ErrorIs(t, err, challenge.ErrLockedOut)
+
+	mockChallengeRepository.EXPECT().
+		ReadLatestByWalletID(context.TODO(), "1").
+		Return(&challenge.Challenge{Status: challenge.StatusPending}, nil)
+	mockChallengeRepository.EXPECT().
+		CountFailedAttempts(context.TODO(), "1", gomock.Any()).
+		Return(3, nil)
+
+	c, err = s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+
+	assert.Nil(t, c)
+	assert.ErrorIs(t, err, challenge.ErrLockedOut)
+}
+
+func TestServiceVerifyChallenge(t *testing.T) {
+	mockChallengeRepository := createMockChallengeRepository(t)
+	s := challenge.NewService(mockChallengeRepository, createMockCodeSender(t), getConf())
+
+	expired := newPendingChallenge("123456")
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	locked := newPendingChallenge("123456")
+	locked.Status = challenge.StatusLocked
+	completed := newPendingChallenge("123456")
+	completed.Status = challenge.StatusCompleted
+
+	testCases := []struct {
+		desc          string
+		givenWalletID string
+		givenCode     string
+		mockCRResult  *challenge.Challenge
+		mockAttempts  int
+		mockFailures  int
+		expectLock    bool
+		expectedErr   error
+	}{
+		{
+			desc:          "code is correct, complete challenge",
+			givenWalletID: "1",
+			givenCode:     "123456",
+			mockCRResult:  newPendingChallenge("123456"),
+			mockAttempts:  1,
+		},
+		{
+			desc:          "code is incorrect, return error",
+			givenWalletID: "1",
+			givenCode:     "000000",
+			mockCRResult:  newPendingChallenge("123456"),
+			mockAttempts:  1,
+			expectedErr:   challenge.ErrInvalidCode,
+		},
+		{
+			desc:          "last attempt is incorrect, lock challenge",
+			givenWalletID: "1",
+			givenCode:     "000000",
+			mockCRResult:  newPendingChallenge("123456"),
+			mockAttempts:  3,
+			mockFailures:  2,
+			expectLock:    true,
+			expectedErr:   challenge.ErrLockedOut,
+		},
+		{
+			desc:          "incorrect codes across challenges use up the attempts, lock challenge",
+			givenWalletID: "1",
+			givenCode:     "000000",
+			mockCRResult:  newPendingChallenge("123456"),
+			mockAttempts:  1,
+			mockFailures:  2,
+			expectLock:    true,
+			expectedErr:   challenge.ErrLockedOut,
+		},
+		{
+			desc:          "challenge is expired, return error",
+			givenWalletID: "1",
+			givenCode:     "123456",
+			mockCRResult:  expired,
+			expectedErr:   challenge.ErrChallengeExpired,
+		},
+		{
+			desc:          "challenge is locked, return error",
+			givenWalletID: "1",
+			givenCode:     "123456",
+			mockCRResult:  locked,
+			expectedErr:   challenge.ErrLockedOut,
+		},
+		{
+			desc:          "challenge is completed, return error",
+			givenWalletID: "1",
+			givenCode:     "123456",
+			mockCRResult:  completed,
+			expectedErr:   challenge.ErrChallengeNotPending,
+		},
+		{
+			desc:          "challenge belongs to another wallet, return error",
+			givenWalletID: "2",
+			givenCode:     "123456",
+			mockCRResult:  newPendingChallenge("123456"),
+			expectedErr:   challenge.ErrChallengeNotFound,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockChallengeRepository.EXPECT().Read(context.TODO(), "c1").Return(tC.mockCRResult, nil)
+			if tC.mockAttempts > 0 {
+				mockChallengeRepository.EXPECT().ReadLatestByWalletID(context.TODO(), "1").Return(tC.mockCRResult, nil)
+				mockChallengeRepository.EXPECT().CountFailedAttempts(context.TODO(), "1", gomock.Any()).Return(tC.mockFailures, nil)
+				mockChallengeRepository.EXPECT().IncrementAttempts(context.TODO(), "c1").Return(tC.mockAttempts, nil)
+			}
+			if tC.givenCode != "123456" {
+				mockChallengeRepository.EXPECT().AddFailedAttempt(context.TODO(), "c1", gomock.Any()).Return(nil)
+				mockChallengeRepository.EXPECT().CountFailedAttempts(context.TODO(), "1", gomock.Any()).Return(tC.mockFailures+1, nil)
+			}
+			if tC.expectLock {
+				mockChallengeRepository.EXPECT().Lock(context.TODO(), "c1", gomock.Any()).Return(nil)
+			}
+			if tC.expectedErr == nil {
+				mockChallengeRepository.EXPECT().Complete(context.TODO(), "c1").Return(nil)
+			}
+
+			c, err := s.VerifyChallenge(context.TODO(), "c1", tC.givenWalletID, tC.givenCode)
+
+			assert.ErrorIs(t, err, tC.expectedErr)
+			if tC.expectedErr == nil {
+				assert.Equal(t, challenge.StatusCompleted, c.Status)
+			} else {
+				assert.Nil(t, c)
+			}
+		})
+	}
+}
+
+func TestServiceVerifyChallengeAcrossChallenges(t *testing.T) {
+	mockCodeSender := createMockCodeSender(t)
+	s := challenge.NewService(memory.NewMemory(), mockCodeSender, getConf())
+
+	var sentCode string
+	mockCodeSender.EXPECT().
+		Send(context.TODO(), "1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, code string) error {
+			sentCode = code
+			return nil
+		}).
+		AnyTimes()
+	wrongCode := func() string {
+		if sentCode == "000000" {
+			return "111111"
+		}
+		return "000000"
+	}
+
+	first, err := s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = s.VerifyChallenge(context.TODO(), first.ID, "1", wrongCode())
+		assert.ErrorIs(t, err, challenge.ErrInvalidCode)
+	}
+
+	second, err := s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+	assert.Nil(t, err)
+
+	_, err = s.VerifyChallenge(context.TODO(), second.ID, "1", wrongCode())
+	assert.ErrorIs(t, err, challenge.ErrLockedOut)
+
+	_, err = s.VerifyChallenge(context.TODO(), second.ID, "1", sentCode)
+	assert.ErrorIs(t, err, challenge.ErrLockedOut)
+
+	_, err = s.CreateChallenge(context.TODO(), "1", "withdrawal", 2000)
+	assert.ErrorIs(t, err, challenge.ErrLockedOut)
+}
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+const (
+	camtCredit = "CRDT"
+	camtDebit  = "DBIT"
+)
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDateTime struct {
+	DateTime string `xml:"DtTm"`
+}
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+	Servicer string   `xml:"Svcr>FinInstnId>Othr>Id,omitempty"`
+}
+
+type camtBalance struct {
+	XMLName   xml.Name     `xml:"Bal"`
+	Type      string       `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount   `xml:"Amt"`
+	Indicator string       `xml:"CdtDbtInd"`
+	Date      camtDateTime `xml:"Dt"`
+}
+
+type camtEntry struct {
+	XMLName         xml.Name     `xml:"Ntry"`
+	Reference       string       `xml:"NtryRef"`
+	Amount          camtAmount   `xml:"Amt"`
+	Indicator       string       `xml:"CdtDbtInd"`
+	Status          string       `xml:"Sts"`
+	BookingDate     camtDateTime `xml:"BookgDt"`
+	ValueDate       camtDateTime `xml:"ValDt"`
+	ServicerRef     string       `xml:"AcctSvcrRef"`
+	TransactionCode string       `xml:"BkTxCd>Prtry>Cd"`
+	CodeIssuer      string       `xml:"BkTxCd>Prtry>Issr"`
+	DetailsRef      string       `xml:"NtryDtls>TxDtls>Refs>AcctSvcrRef"`
+}
+
+type camt053Encoder struct {
+	enc    *xml.Encoder
+	header *Header
+}
+
+func NewCAMT053Encoder(w io.Writer) *camt053Encoder {
+	return &camt053Encoder{enc: xml.NewEncoder(w)}
+}
+
+func (e *camt053Encoder) Begin(h *Header) error {
+	e.header = h
+	statementID := fmt.Sprintf("%s-%s", h.WalletID, h.GeneratedAt.UTC().Format(ofxTimeLayout))
+
+	tokens := []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)},
+		xml.CharData("\n"),
+		startElement("Document", xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}),
+		startElement("BkToCstmrStmt"),
+	}
+	if err := encodeTokens(e.enc, tokens); err != nil {
+		return err
+	}
+
+	err := e.enc.Encode(camtGroupHeader{MessageID: statementID, CreatedAt: h.GeneratedAt.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+
+	tokens = []xml.Token{startElement("Stmt")}
+	tokens = append(tokens, textElement("Id", statementID)...)
+	tokens = append(tokens, textElement("CreDtTm", h.GeneratedAt.UTC().Format(time.RFC3339))...)
+	tokens = append(tokens, startElement("FrToDt"))
+	tokens = append(tokens, textElement("FrDtTm", h.From.UTC().Format(time.RFC3339))...)
+	tokens = append(tokens, textElement("ToDtTm", h.To.UTC().Format(time.RFC3339))...)
+	tokens = append(tokens, endElement("FrToDt"))
+	if err = encodeTokens(e.enc, tokens); err != nil {
+		return err
+	}
+
+	elements := []interface{}{
+		camtAccount{ID: h.WalletID, Currency: h.Currency, Servicer: h.BankID},
+		e.balance("OPBD", h.OpeningBalance, h.From),
+		e.balance("CLBD", h.ClosingBalance, h.To),
+	}
+	for _, element := range elements {
+		if err = e.enc.Encode(element); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *camt053Encoder) Encode(txn *transaction.Transaction) error {
+	indicator := camtCredit
+	if txn.Type == wallet.Withdrawal {
+		indicator = camtDebit
+	}
+	bookedAt := camtDateTime{txn.CreatedAt.UTC().Format(time.RFC3339)}
+
+	return e.enc.Encode(camtEntry{
+		Reference:       txn.ID,
+		Amount:          camtAmount{e.header.Currency, formatAmount(txn.Amount)},
+		Indicator:       indicator,
+		Status:          "BOOK",
+		BookingDate:     bookedAt,
+		ValueDate:       bookedAt,
+		ServicerRef:     txn.ID,
+		TransactionCode: txn.Type,
+		CodeIssuer:      e.header.BankID,
+		DetailsRef:      txn.ID,
+	})
+}
+
+func (e *camt053Encoder) End() error {
+	err := encodeTokens(e.enc, []xml.Token{endElement("Stmt"), endElement("BkToCstmrStmt"), endElement("Document")})
+	if err != nil {
+		return err
+	}
+
+	return e.enc.Flush()
+}
+
+func (e *camt053Encoder) balance(code string, amount float64, at time.Time) camtBalance {
+	indicator := camtCredit
+	if amount < 0 {
+		indicator = camtDebit
+	}
+
+	return camtBalance{
+		Type:      code,
+		Amount:    camtAmount{e.header.Currency, formatAmount(math.Abs(amount))},
+		Indicator: indicator,
+		Date:      camtDateTime{at.UTC().Format(time.RFC3339)},
+	}
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
+type csvEncoder struct {
+	w        *csv.Writer
+	currency string
+}
+
+func NewCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Begin(h *Header) error {
+	e.currency = h.Currency
+	return e.w.Write([]string{"id", "walletId", "createdAt", "type", "amount", "currency", "balanceBefore", "balanceAfter"})
+}
+
+func (e *csvEncoder) Encode(txn *transaction.Transaction) error {
+	return e.w.Write([]string{
+		txn.ID,
+		txn.WalletID,
+		txn.CreatedAt.UTC().Format(time.RFC3339),
+		txn.Type,
+		formatAmount(txn.Amount),
+		e.currency,
+		formatAmount(txn.BalanceBefore),
+		formatAmount(txn.BalanceAfter),
+	})
+}
+
+func (e *csvEncoder) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
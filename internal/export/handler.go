@@ -0,0 +1,108 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+var badRequestErrors = []error{
+	ErrInvalidFormat,
+	ErrInvalidPeriod,
+}
+
+var contentTypes = map[string]string{
+	FormatCSV:     "text/csv",
+	FormatOFX:     "application/x-ofx",
+	FormatCAMT053: echo.MIMEApplicationXML,
+}
+
+var fileExtensions = map[string]string{
+	FormatCSV:     "csv",
+	FormatOFX:     "ofx",
+	FormatCAMT053: "xml",
+}
+
+type ExportService interface {
+	Export(ctx context.Context, walletID, format string, from, to time.Time, w io.Writer) error
+}
+
+type handler struct {
+	es ExportService
+}
+
+func NewHandler(es ExportService) *handler {
+	return &handler{es}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/wallets/:id/export", h.Export, auth.RequireScopes(auth.ScopeTransactionsRead))
+}
+
+func (h *handler) Export(c echo.Context) error {
+	loc, err := transaction.ParseLocation(c.QueryParam("tz"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	from := time.Unix(0, 0).In(loc)
+	if query := c.QueryParam("from"); query != "" {
+		if from, err = transaction.ParseFrom(query, loc); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	to := time.Now().In(loc)
+	if query := c.QueryParam("to"); query != "" {
+		if to, err = transaction.ParseTo(query, loc); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	walletID, format := c.Param("id"), c.QueryParam("format")
+	w := &attachmentWriter{
+		res:         c.Response(),
+		contentType: contentTypes[format],
+		filename:    fmt.Sprintf("transactions-%s.%s", walletID, fileExtensions[format]),
+	}
+
+	err = h.es.Export(c.Request().Context(), walletID, format, from, to, w)
+	if err != nil && c.Response().Committed {
+		// the body is already being streamed, so the status can no longer change
+		log.Error(err)
+		return nil
+	} else if err != nil && wallet.ContainsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, wallet.ErrWalletNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+type attachmentWriter struct {
+	res         *echo.Response
+	contentType string
+	filename    string
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.res.Committed {
+		w.res.Header().Set(echo.HeaderContentType, w.contentType)
+		w.res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", w.filename))
+		w.res.WriteHeader(http.StatusOK)
+	}
+
+	return w.res.Write(p)
+}
@@ -0,0 +1,175 @@
+package export_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/authtest"
+	"github.com/gokcelb/wallet-api/internal/export"
+	"github.com/gokcelb/wallet-api/internal/export/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockExportService(t *testing.T) *mock.MockExportService {
+	return mock.NewMockExportService(gomock.NewController(t))
+}
+
+func TestHandlerExport(t *testing.T) {
+	mockExportService := createMockExportService(t)
+	h := export.NewHandler(mockExportService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc                       string
+		givenFormat                string
+		mockESBody                 string
+		mockESErr                  error
+		expectedResponseStatusCode int
+		expectedContentType        string
+		expectedResponseBody       string
+	}{
+		{
+			desc:                       "csv format, stream csv attachment",
+			givenFormat:                export.FormatCSV,
+			mockESBody:                 "id,walletId\n1,1\n",
+			expectedResponseStatusCode: 200,
+			expectedContentType:        "text/csv",
+			expectedResponseBody:       "id,walletId\n1,1\n",
+		},
+		{
+			desc:                       "camt053 format, stream xml attachment",
+			givenFormat:                export.FormatCAMT053,
+			mockESBody:                 "<Document></Document>",
+			expectedResponseStatusCode: 200,
+			expectedContentType:        echo.MIMEApplicationXML,
+			expectedResponseBody:       "<Document></Document>",
+		},
+		{
+			desc:                       "format is not supported, return error",
+			givenFormat:                "pdf",
+			mockESErr:                  export.ErrInvalidFormat,
+			expectedResponseStatusCode: 400,
+			expectedContentType:        echo.MIMEApplicationJSONCharsetUTF8,
+			expectedResponseBody:       mustMarshal(httpErr{export.ErrInvalidFormat.Error()}),
+		},
+		{
+			desc:                       "wallet does not exist, return error",
+			givenFormat:                export.FormatOFX,
+			mockESErr:                  wallet.ErrWalletNotFound,
+			expectedResponseStatusCode: 404,
+			expectedContentType:        echo.MIMEApplicationJSONCharsetUTF8,
+			expectedResponseBody:       mustMarshal(httpErr{wallet.ErrWalletNotFound.Error()}),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockExportService.EXPECT().
+				Export(gomock.Any(), "1", tC.givenFormat, from, to, gomock.Any()).
+				DoAndReturn(func(_, _, _, _, _ interface{}, w io.Writer) error {
+					if tC.mockESErr != nil {
+						return tC.mockESErr
+					}
+					_, err := io.WriteString(w, tC.mockESBody)
+					return err
+				})
+
+			res, err := testServer.Client().Get(fmt.Sprintf(
+				"%s/wallets/1/export?format=%s&from=2022-03-01&to=2022-03-31",
+				testServer.URL,
+				tC.givenFormat,
+			))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.Equal(t, tC.expectedContentType, res.Header.Get(echo.HeaderContentType))
+			assert.Equal(t, tC.expectedResponseBody, string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerExportInTimeZone(t *testing.T) {
+	mockExportService := createMockExportService(t)
+	h := export.NewHandler(mockExportService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, istanbul)
+	to := time.Date(2022, time.April, 1, 0, 0, 0, 0, istanbul)
+
+	mockExportService.EXPECT().
+		Export(gomock.Any(), "1", export.FormatCSV, from, to, gomock.Any()).
+		Return(nil)
+
+	res, err := testServer.Client().Get(testServer.URL + "/wallets/1/export?format=csv&from=2022-03-01&to=2022-03-31&tz=Europe/Istanbul")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = testServer.Client().Get(testServer.URL + "/wallets/1/export?format=csv&tz=Mars/Olympus")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, mustMarshal(httpErr{transaction.ErrInvalidTimeZone.Error()}), string(resBodyBytes))
+}
+
+func mustMarshal(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b) + "\n"
+}
+
+func TestHandlerRequiresScope(t *testing.T) {
+	h := export.NewHandler(createMockExportService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsWrite))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(testServer.URL + "/wallets/1/export")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 403, res.StatusCode)
+}
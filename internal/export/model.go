@@ -0,0 +1,24 @@
+package export
+
+import (
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
+type Header struct {
+	WalletID       string
+	BankID         string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	GeneratedAt    time.Time
+}
+
+type Encoder interface {
+	Begin(h *Header) error
+	Encode(txn *transaction.Transaction) error
+	End() error
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+const ofxTimeLayout = "20060102150405"
+
+type ofxTransaction struct {
+	XMLName  xml.Name `xml:"STMTTRN"`
+	TrnType  string   `xml:"TRNTYPE"`
+	DtPosted string   `xml:"DTPOSTED"`
+	TrnAmt   string   `xml:"TRNAMT"`
+	FITID    string   `xml:"FITID"`
+	Name     string   `xml:"NAME"`
+}
+
+type ofxEncoder struct {
+	enc    *xml.Encoder
+	header *Header
+}
+
+func NewOFXEncoder(w io.Writer) *ofxEncoder {
+	return &ofxEncoder{enc: xml.NewEncoder(w)}
+}
+
+func (e *ofxEncoder) Begin(h *Header) error {
+	e.header = h
+
+	tokens := []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8" standalone="no"`)},
+		xml.CharData("\n"),
+		xml.ProcInst{
+			Target: "OFX",
+			Inst:   []byte(`OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`),
+		},
+		xml.CharData("\n"),
+		startElement("OFX"),
+		startElement("SIGNONMSGSRSV1"),
+		startElement("SONRS"),
+	}
+	tokens = append(tokens, ofxStatus()...)
+	tokens = append(tokens, textElement("DTSERVER", h.GeneratedAt.UTC().Format(ofxTimeLayout))...)
+	tokens = append(tokens, textElement("LANGUAGE", "ENG")...)
+	tokens = append(tokens,
+		endElement("SONRS"),
+		endElement("SIGNONMSGSRSV1"),
+		startElement("BANKMSGSRSV1"),
+		startElement("STMTTRNRS"),
+	)
+	tokens = append(tokens, textElement("TRNUID", "0")...)
+	tokens = append(tokens, ofxStatus()...)
+	tokens = append(tokens, startElement("STMTRS"))
+	tokens = append(tokens, textElement("CURDEF", h.Currency)...)
+	tokens = append(tokens, startElement("BANKACCTFROM"))
+	tokens = append(tokens, textElement("BANKID", h.BankID)...)
+	tokens = append(tokens, textElement("ACCTID", h.WalletID)...)
+	tokens = append(tokens, textElement("ACCTTYPE", "CHECKING")...)
+	tokens = append(tokens, endElement("BANKACCTFROM"), startElement("BANKTRANLIST"))
+	tokens = append(tokens, textElement("DTSTART", h.From.UTC().Format(ofxTimeLayout))...)
+	tokens = append(tokens, textElement("DTEND", h.To.UTC().Format(ofxTimeLayout))...)
+
+	return encodeTokens(e.enc, tokens)
+}
+
+func (e *ofxEncoder) Encode(txn *transaction.Transaction) error {
+	trnType, amount := "CREDIT", txn.Amount
+	if txn.Type == wallet.Withdrawal {
+		trnType, amount = "DEBIT", -txn.Amount
+	}
+
+	return e.enc.Encode(ofxTransaction{
+		TrnType:  trnType,
+		DtPosted: txn.CreatedAt.UTC().Format(ofxTimeLayout),
+		TrnAmt:   formatAmount(amount),
+		FITID:    txn.ID,
+		Name:     txn.Type,
+	})
+}
+
+func (e *ofxEncoder) End() error {
+	tokens := []xml.Token{endElement("BANKTRANLIST"), startElement("LEDGERBAL")}
+	tokens = append(tokens, textElement("BALAMT", formatAmount(e.header.ClosingBalance))...)
+	tokens = append(tokens, textElement("DTASOF", e.header.To.UTC().Format(ofxTimeLayout))...)
+	tokens = append(tokens,
+		endElement("LEDGERBAL"),
+		endElement("STMTRS"),
+		endElement("STMTTRNRS"),
+		endElement("BANKMSGSRSV1"),
+		endElement("OFX"),
+	)
+
+	if err := encodeTokens(e.enc, tokens); err != nil {
+		return err
+	}
+
+	return e.enc.Flush()
+}
+
+func ofxStatus() []xml.Token {
+	tokens := []xml.Token{startElement("STATUS")}
+	tokens = append(tokens, textElement("CODE", "0")...)
+	tokens = append(tokens, textElement("SEVERITY", "INFO")...)
+	return append(tokens, endElement("STATUS"))
+}
+
+func startElement(name string, attrs ...xml.Attr) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}
+}
+
+func endElement(name string) xml.EndElement {
+	return xml.EndElement{Name: xml.Name{Local: name}}
+}
+
+func textElement(name, text string) []xml.Token {
+	return []xml.Token{startElement(name), xml.CharData(text), endElement(name)}
+}
+
+func encodeTokens(enc *xml.Encoder, tokens []xml.Token) error {
+	for _, token := range tokens {
+		if err := enc.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCAMT053 = "camt053"
+)
+
+var (
+	ErrInvalidFormat = errors.New("format must be csv, ofx or camt053")
+	ErrInvalidPeriod = errors.New("export period start must be before its end")
+)
+
+type WalletRepository interface {
+	Read(ctx context.Context, id string) (*wallet.Wallet, error)
+}
+
+type TransactionRepository interface {
+	ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error)
+	StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*transaction.Transaction) error) error
+}
+
+type service struct {
+	wr   WalletRepository
+	tr   TransactionRepository
+	conf config.Conf
+}
+
+func NewService(wr WalletRepository, tr TransactionRepository, conf config.Conf) *service {
+	return &service{wr, tr, conf}
+}
+
+func (s *service) Export(ctx context.Context, walletID, format string, from, to time.Time, w io.Writer) error {
+	enc, err := newEncoder(format, w)
+	if err != nil {
+		return err
+	}
+
+	if !from.Before(to) {
+		return ErrInvalidPeriod
+	}
+
+	if _, err = s.wr.Read(ctx, walletID); err != nil {
+		return err
+	}
+
+	openingBalance, err := s.balanceBefore(ctx, walletID, from)
+	if err != nil {
+		return err
+	}
+
+	closingBalance, err := s.balanceBefore(ctx, walletID, to)
+	if err != nil {
+		return err
+	}
+
+	header := &Header{
+		WalletID:       walletID,
+		BankID:         s.conf.Export.BankID,
+		Currency:       s.conf.Export.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		ClosingBalance: closingBalance,
+		GeneratedAt:    time.Now(),
+	}
+	if err = enc.Begin(header); err != nil {
+		return err
+	}
+
+	if err = s.tr.StreamByWalletIDBetween(ctx, walletID, from, to, enc.Encode); err != nil {
+		return err
+	}
+
+	return enc.End()
+}
+
+func (s *service) balanceBefore(ctx context.Context, walletID string, before time.Time) (float64, error) {
+	last, err := s.tr.ReadLastByWalletIDBefore(ctx, walletID, before)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		return s.conf.Wallet.InitialBalance, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return last.BalanceAfter, nil
+}
+
+func newEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVEncoder(w), nil
+	case FormatOFX:
+		return NewOFXEncoder(w), nil
+	case FormatCAMT053:
+		return NewCAMT053Encoder(w), nil
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
@@ -0,0 +1,188 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/export"
+	"github.com/gokcelb/wallet-api/internal/export/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockWalletRepository(t *testing.T) *mock.MockWalletRepository {
+	return mock.NewMockWalletRepository(gomock.NewController(t))
+}
+
+func createMockTransactionRepository(t *testing.T) *mock.MockTransactionRepository {
+	return mock.NewMockTransactionRepository(gomock.NewController(t))
+}
+
+func getConf() config.Conf {
+	conf, err := config.Read("../../.config/dev.json")
+	if err != nil {
+		panic(err)
+	}
+
+	return conf
+}
+
+func TestServiceExport(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := export.NewService(mockWalletRepository, mockTransactionRepository, getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockTxns := []*transaction.Transaction{
+		{
+			ID:            "1",
+			WalletID:      "1",
+			Type:          "deposit",
+			Amount:        200,
+			BalanceBefore: 100,
+			BalanceAfter:  300,
+			CreatedAt:     from.Add(time.Hour),
+		},
+		{
+			ID:            "2",
+			WalletID:      "1",
+			Type:          "withdrawal",
+			Amount:        50,
+			BalanceBefore: 300,
+			BalanceAfter:  250,
+			CreatedAt:     from.Add(2 * time.Hour),
+		},
+	}
+
+	testCases := []struct {
+		desc             string
+		givenFormat      string
+		expectedContains []string
+	}{
+		{
+			desc:        "csv format, write csv rows",
+			givenFormat: export.FormatCSV,
+			expectedContains: []string{
+				"id,walletId,createdAt,type,amount,currency,balanceBefore,balanceAfter\n",
+				"1,1,2022-03-01T01:00:00Z,deposit,200.00,TRY,100.00,300.00\n",
+				"2,1,2022-03-01T02:00:00Z,withdrawal,50.00,TRY,300.00,250.00\n",
+			},
+		},
+		{
+			desc:        "ofx format, write ofx statement",
+			givenFormat: export.FormatOFX,
+			expectedContains: []string{
+				`<?OFX OFXHEADER="200" VERSION="220"`,
+				"<CURDEF>TRY</CURDEF>",
+				"<ACCTID>1</ACCTID>",
+				"<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20220301010000</DTPOSTED>" +
+					"<TRNAMT>200.00</TRNAMT><FITID>1</FITID><NAME>deposit</NAME></STMTTRN>",
+				"<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20220301020000</DTPOSTED><TRNAMT>-50.00</TRNAMT>",
+				"<LEDGERBAL><BALAMT>250.00</BALAMT><DTASOF>20220401000000</DTASOF></LEDGERBAL>",
+			},
+		},
+		{
+			desc:        "camt053 format, write camt.053 document",
+			givenFormat: export.FormatCAMT053,
+			expectedContains: []string{
+				`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">`,
+				"<Acct><Id><Othr><Id>1</Id></Othr></Id><Ccy>TRY</Ccy>",
+				`<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="TRY">100.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+				`<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="TRY">250.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+				`<Ntry><NtryRef>1</NtryRef><Amt Ccy="TRY">200.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>`,
+				`<Ntry><NtryRef>2</NtryRef><Amt Ccy="TRY">50.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>`,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil)
+
+			mockTransactionRepository.EXPECT().
+				ReadLastByWalletIDBefore(context.TODO(), "1", from).
+				Return(&transaction.Transaction{BalanceAfter: 100}, nil)
+
+			mockTransactionRepository.EXPECT().
+				ReadLastByWalletIDBefore(context.TODO(), "1", to).
+				Return(mockTxns[1], nil)
+
+			mockTransactionRepository.EXPECT().
+				StreamByWalletIDBetween(context.TODO(), "1", from, to, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _, _ time.Time, fn func(*transaction.Transaction) error) error {
+					for _, txn := range mockTxns {
+						if err := fn(txn); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+
+			var buf bytes.Buffer
+			err := s.Export(context.TODO(), "1", tC.givenFormat, from, to, &buf)
+
+			assert.Nil(t, err)
+			for _, expected := range tC.expectedContains {
+				assert.Contains(t, buf.String(), expected)
+			}
+			if tC.givenFormat != export.FormatCSV {
+				assert.Nil(t, xml.NewDecoder(strings.NewReader(buf.String())).Decode(new(interface{})))
+			}
+		})
+	}
+}
+
+func TestServiceExportWithInvalidParams(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	s := export.NewService(mockWalletRepository, nil, getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		givenFormat   string
+		givenTo       time.Time
+		mockRepoErr   error
+		expectedError error
+	}{
+		{
+			desc:          "format is not supported, return error",
+			givenFormat:   "pdf",
+			givenTo:       from.AddDate(0, 1, 0),
+			expectedError: export.ErrInvalidFormat,
+		},
+		{
+			desc:          "period end is before its start, return error",
+			givenFormat:   export.FormatCSV,
+			givenTo:       from.AddDate(0, -1, 0),
+			expectedError: export.ErrInvalidPeriod,
+		},
+		{
+			desc:          "wallet does not exist, return error",
+			givenFormat:   export.FormatCSV,
+			givenTo:       from.AddDate(0, 1, 0),
+			mockRepoErr:   wallet.ErrWalletNotFound,
+			expectedError: wallet.ErrWalletNotFound,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.mockRepoErr != nil {
+				mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, tC.mockRepoErr)
+			}
+
+			var buf bytes.Buffer
+			err := s.Export(context.TODO(), "1", tC.givenFormat, from, tC.givenTo, &buf)
+
+			assert.ErrorIs(t, err, tC.expectedError)
+			assert.Empty(t, buf.String())
+		})
+	}
+}
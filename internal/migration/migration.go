@@ -0,0 +1,262 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrDuplicateVersion = errors.New("migration version is used more than once")
+	ErrLocked           = errors.New("migrations are locked by another instance")
+	ErrLockLost         = errors.New("migration lock could not be extended")
+)
+
+// lockID is the id of the lock document, which lives in the migrations
+// collection next to the records of the applied migrations.
+const lockID = "lock"
+
+type Migration struct {
+	Version int
+	Name    string
+	// Pending counts the documents Up would change, for dry runs.
+	Pending func(ctx context.Context, db *mongo.Database) (int64, error)
+	// Up has to be idempotent, since it runs again when we stop after it but
+	// before recording it.
+	Up func(ctx context.Context, db *mongo.Database) error
+}
+
+type Result struct {
+	Version int
+	Name    string
+	Pending int64
+	Applied bool
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+type migrator struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	conf       config.MigrationConf
+	migrations []Migration
+	owner      string
+}
+
+func NewMigrator(db *mongo.Database, collection string, conf config.MigrationConf, migrations []Migration) *migrator {
+	return &migrator{db, db.Collection(collection), conf, migrations, primitive.NewObjectID().Hex()}
+}
+
+// Run applies the migrations that haven't been applied yet in version order,
+// holding a lock so that only one instance migrates at a time. Losing the lock
+// stops the migrations. A dry run only counts what each pending migration
+// would change.
+func (m *migrator) Run(ctx context.Context, dryRun bool) ([]*Result, error) {
+	migrations, err := sortByVersion(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return m.dryRun(ctx, migrations)
+	}
+
+	if err = m.waitForLock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.unlock()
+
+	migrateCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.heartbeat(migrateCtx, cancel)
+
+	results, err := m.migrate(migrateCtx, migrations)
+	if err != nil && migrateCtx.Err() != nil && ctx.Err() == nil {
+		return results, fmt.Errorf("%w: %v", ErrLockLost, err)
+	}
+
+	return results, err
+}
+
+func (m *migrator) migrate(ctx context.Context, migrations []Migration) ([]*Result, error) {
+	applied, err := m.readApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*Result{}
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		if err = migration.Up(ctx, m.db); err != nil {
+			return results, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		// Up is idempotent, so a record from another instance only means it
+		// got there first.
+		_, err = m.collection.InsertOne(ctx, record{migration.Version, migration.Name, time.Now()})
+		if mongo.IsDuplicateKeyError(err) {
+			log.Infof("migration %d %s was already applied", migration.Version, migration.Name)
+			continue
+		} else if err != nil {
+			return results, err
+		}
+
+		log.Infof("applied migration %d %s", migration.Version, migration.Name)
+		results = append(results, &Result{Version: migration.Version, Name: migration.Name, Applied: true})
+	}
+
+	return results, nil
+}
+
+func (m *migrator) dryRun(ctx context.Context, migrations []Migration) ([]*Result, error) {
+	applied, err := m.readApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*Result{}
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		pending, err := migration.Pending(ctx, m.db)
+		if err != nil {
+			return nil, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		results = append(results, &Result{Version: migration.Version, Name: migration.Name, Pending: pending})
+	}
+
+	return results, nil
+}
+
+func (m *migrator) readApplied(ctx context.Context) (map[int]bool, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{"_id": bson.M{"$ne": lockID}})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var records []record
+	if err = cursor.All(ctx, &records); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	applied := make(map[int]bool)
+	for _, r := range records {
+		applied[r.Version] = true
+	}
+
+	return applied, nil
+}
+
+// waitForLock retries taking the lock until it is free or the configured wait
+// is over.
+func (m *migrator) waitForLock(ctx context.Context) error {
+	deadline := time.Now().Add(time.Second * time.Duration(m.conf.LockWaitInSec))
+	for {
+		err := m.lock(ctx)
+		if !errors.Is(err, ErrLocked) || time.Now().After(deadline) {
+			return err
+		}
+
+		log.Info("waiting for another instance to finish migrating")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// lock takes the lock when nobody holds it or the lease of its holder ran
+// out. When someone else holds it the upsert runs into the existing lock
+// document instead.
+func (m *migrator) lock(ctx context.Context) error {
+	now := time.Now()
+	filter := bson.M{"_id": lockID, "$or": bson.A{
+		bson.M{"owner": m.owner},
+		bson.M{"expires_at": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{"owner": m.owner, "expires_at": now.Add(m.lockTTL())}}
+
+	_, err := m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	} else if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+// heartbeat extends the lease of the lock until ctx is done. If it can't, it
+// cancels the migrations, since another instance may take the lock once the
+// lease runs out.
+func (m *migrator) heartbeat(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(m.lockTTL() / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.lock(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.Error("could not extend the migration lock", err)
+					cancel()
+				}
+				return
+			}
+		}
+	}
+}
+
+func (m *migrator) unlock() {
+	_, err := m.collection.DeleteOne(context.Background(), bson.M{"_id": lockID, "owner": m.owner})
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (m *migrator) lockTTL() time.Duration {
+	if m.conf.LockTTLInSec <= 0 {
+		return time.Minute
+	}
+
+	return time.Second * time.Duration(m.conf.LockTTLInSec)
+}
+
+func sortByVersion(migrations []Migration) ([]Migration, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, sorted[i].Version)
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,170 @@
+package migration_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/migration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMigratorRunDuplicateVersion(t *testing.T) {
+	// Connecting is lazy, and the versions are checked before any query.
+	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI("mongodb://localhost:1"))
+	require.NoError(t, err)
+
+	migrations := []migration.Migration{
+		{Version: 1, Name: "first"},
+		{Version: 2, Name: "second"},
+		{Version: 1, Name: "third"},
+	}
+	m := migration.NewMigrator(client.Database("test"), "migrations", config.MigrationConf{}, migrations)
+
+	_, err = m.Run(context.TODO(), false)
+
+	assert.ErrorIs(t, err, migration.ErrDuplicateVersion)
+}
+
+// newTestDatabase needs a server at MONGO_TEST_URI and skips the test without
+// one.
+func newTestDatabase(t *testing.T) *mongo.Database {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+
+	db := client.Database("wallet-api-test-" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		db.Drop(context.TODO())
+		client.Disconnect(context.TODO())
+	})
+
+	return db
+}
+
+func TestMigratorRun(t *testing.T) {
+	db := newTestDatabase(t)
+	conf := config.CollectionConf{Wallet: "wallets", Transaction: "transactions"}
+	_, err := db.Collection("wallets").InsertMany(context.TODO(), []interface{}{
+		bson.M{"user_id": "1"},
+		bson.M{"user_id": "2", "schema_version": 1},
+	})
+	require.NoError(t, err)
+
+	m := migration.NewMigrator(db, "migrations", config.MigrationConf{LockTTLInSec: 60}, migration.Migrations(conf))
+
+	results, err := m.Run(context.TODO(), true)
+	require.NoError(t, err)
+	assert.Equal(t, []*migration.Result{
+		{Version: 1, Name: "tag_wallets_with_schema_version", Pending: 1},
+		{Version: 2, Name: "tag_transactions_with_schema_version", Pending: 0},
+	}, results)
+
+	count, err := db.Collection("wallets").CountDocuments(context.TODO(), bson.M{"schema_version": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "a dry run changes nothing")
+
+	results, err = m.Run(context.TODO(), false)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	count, err = db.Collection("wallets").CountDocuments(context.TODO(), bson.M{"schema_version": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	results, err = m.Run(context.TODO(), false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMigratorRunLocked(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Collection("migrations").InsertOne(context.TODO(), bson.M{
+		"_id":        "lock",
+		"owner":      "another instance",
+		"expires_at": time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	applied := false
+	migrations := []migration.Migration{{
+		Version: 1,
+		Name:    "first",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			applied = true
+			return nil
+		},
+	}}
+	m := migration.NewMigrator(db, "migrations", config.MigrationConf{LockTTLInSec: 60}, migrations)
+
+	_, err = m.Run(context.TODO(), false)
+
+	assert.ErrorIs(t, err, migration.ErrLocked)
+	assert.False(t, applied)
+}
+
+func TestMigratorRunAlreadyRecorded(t *testing.T) {
+	db := newTestDatabase(t)
+
+	migrations := []migration.Migration{{
+		Version: 1,
+		Name:    "first",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Another instance records the migration while this one applies it.
+			_, err := db.Collection("migrations").InsertOne(ctx, bson.M{"_id": 1, "name": "first", "applied_at": time.Now()})
+			return err
+		},
+	}}
+	m := migration.NewMigrator(db, "migrations", config.MigrationConf{LockTTLInSec: 60}, migrations)
+
+	results, err := m.Run(context.TODO(), false)
+
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMigratorRunLockLost(t *testing.T) {
+	db := newTestDatabase(t)
+
+	migrations := []migration.Migration{{
+		Version: 1,
+		Name:    "first",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Another instance takes the lock, as if the lease had run out.
+			_, err := db.Collection("migrations").UpdateOne(ctx, bson.M{"_id": "lock"}, bson.M{"$set": bson.M{
+				"owner":      "another instance",
+				"expires_at": time.Now().Add(time.Minute),
+			}})
+			if err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(10 * time.Second):
+				return nil
+			}
+		},
+	}}
+	m := migration.NewMigrator(db, "migrations", config.MigrationConf{LockTTLInSec: 3}, migrations)
+
+	_, err := m.Run(context.TODO(), false)
+
+	assert.ErrorIs(t, err, migration.ErrLockLost)
+
+	count, err := db.Collection("migrations").CountDocuments(context.TODO(), bson.M{"_id": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/gokcelb/wallet-api/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations lists every migration we have. Once a migration has shipped,
+// leave it as it is and add a new one instead.
+func Migrations(conf config.CollectionConf) []Migration {
+	return []Migration{
+		setSchemaVersion(1, "tag_wallets_with_schema_version", conf.Wallet, 1),
+		setSchemaVersion(2, "tag_transactions_with_schema_version", conf.Transaction, 1),
+	}
+}
+
+// setSchemaVersion tags the documents of collection below schemaVersion with
+// it, for when a new version changes nothing in the documents themselves.
+func setSchemaVersion(version int, name, collection string, schemaVersion int) Migration {
+	// $lt would skip the documents without a schema_version.
+	filter := bson.M{"schema_version": bson.M{"$not": bson.M{"$gte": schemaVersion}}}
+
+	return Migration{
+		Version: version,
+		Name:    name,
+		Pending: func(ctx context.Context, db *mongo.Database) (int64, error) {
+			return db.Collection(collection).CountDocuments(ctx, filter)
+		},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(collection).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"schema_version": schemaVersion}})
+			return err
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	tokenPath      = "/oauth/token"
+	introspectPath = "/oauth/introspect"
+)
+
+// publicPaths authenticate clients with their own credentials instead of an
+// access token.
+var publicPaths = map[string]bool{
+	tokenPath:      true,
+	introspectPath: true,
+}
+
+// oauthErrors maps our errors to the error codes of RFC 6749 section 5.2.
+var oauthErrors = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{ErrInvalidRequest, "invalid_request", http.StatusBadRequest},
+	{ErrUnsupportedGrantType, "unsupported_grant_type", http.StatusBadRequest},
+	{ErrInvalidScope, "invalid_scope", http.StatusBadRequest},
+	{ErrInvalidClient, "invalid_client", http.StatusUnauthorized},
+}
+
+var badRequestErrors = []error{
+	ErrMissingName,
+	ErrMissingScopes,
+	ErrInvalidScope,
+	ErrUnknownSigningKeyID,
+}
+
+type OAuthService interface {
+	RegisterClient(ctx context.Context, info *ClientRegistrationInfo) (*RegisteredClient, error)
+	GetClients(ctx context.Context) ([]*Client, error)
+	RevokeClient(ctx context.Context, id string) error
+	Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error)
+	Introspect(ctx context.Context, clientID, clientSecret, token string) (*Introspection, error)
+}
+
+type handler struct {
+	oas OAuthService
+}
+
+func NewHandler(oas OAuthService) *handler {
+	return &handler{oas}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.POST(tokenPath, h.Token)
+	e.POST(introspectPath, h.Introspect)
+
+	g := e.Group("/admin/oauth/clients", auth.RequireScopes(auth.ScopeAdmin))
+	g.POST("", h.RegisterClient)
+	g.GET("", h.GetClients)
+	g.DELETE("/:id", h.RevokeClient)
+}
+
+// Skipper lets requests to the token and introspection endpoints through the
+// JWT middleware.
+func Skipper(c echo.Context) bool {
+	return publicPaths[c.Path()]
+}
+
+func (h *handler) Token(c echo.Context) error {
+	clientID, clientSecret := clientCredentials(c)
+	req := &TokenRequest{
+		GrantType:    c.FormValue("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scope:        c.FormValue("scope"),
+	}
+
+	res, err := h.oas.Token(c.Request().Context(), req)
+	if err != nil {
+		return oauthError(c, err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *handler) Introspect(c echo.Context) error {
+	clientID, clientSecret := clientCredentials(c)
+
+	res, err := h.oas.Introspect(c.Request().Context(), clientID, clientSecret, c.FormValue("token"))
+	if err != nil {
+		return oauthError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *handler) RegisterClient(c echo.Context) error {
+	var info ClientRegistrationInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	client, err := h.oas.RegisterClient(c.Request().Context(), &info)
+	if err != nil && containsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusCreated, client)
+}
+
+func (h *handler) GetClients(c echo.Context) error {
+	clients, err := h.oas.GetClients(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, clients)
+}
+
+func (h *handler) RevokeClient(c echo.Context) error {
+	err := h.oas.RevokeClient(c.Request().Context(), c.Param("id"))
+	if err != nil && errors.Is(err, ErrClientNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// clientCredentials prefers HTTP basic authentication, falling back to the
+// credentials in the request body as RFC 6749 section 2.3.1 allows.
+func clientCredentials(c echo.Context) (string, string) {
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		return clientID, clientSecret
+	}
+
+	return c.FormValue("client_id"), c.FormValue("client_secret")
+}
+
+func oauthError(c echo.Context, err error) error {
+	for _, oe := range oauthErrors {
+		if errors.Is(err, oe.err) {
+			if oe.status == http.StatusUnauthorized {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+			}
+			return c.JSON(oe.status, ErrorResponse{oe.code, err.Error()})
+		}
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+func containsError(err error, errList []error) bool {
+	for _, e := range errList {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package oauth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	"github.com/gokcelb/wallet-api/internal/oauth/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockOAuthService(t *testing.T) *mock.MockOAuthService {
+	return mock.NewMockOAuthService(gomock.NewController(t))
+}
+
+func TestHandlerToken(t *testing.T) {
+	mockOAuthService := createMockOAuthService(t)
+	h := oauth.NewHandler(mockOAuthService)
+
+	e := echo.New()
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenBasicAuth             bool
+		expectedRequest            *oauth.TokenRequest
+		mockOSResult               *oauth.TokenResponse
+		mockOSErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "credentials are in the header, return token",
+			givenBasicAuth:             true,
+			expectedRequest:            &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:read"},
+			mockOSResult:               &oauth.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, Scope: "wallets:read"},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &oauth.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, Scope: "wallets:read"},
+		},
+		{
+			desc:                       "credentials are in the body, return token",
+			expectedRequest:            &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:read"},
+			mockOSResult:               &oauth.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, Scope: "wallets:read"},
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       &oauth.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 900, Scope: "wallets:read"},
+		},
+		{
+			desc:                       "client is invalid, return invalid_client",
+			givenBasicAuth:             true,
+			expectedRequest:            &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:read"},
+			mockOSErr:                  oauth.ErrInvalidClient,
+			expectedResponseStatusCode: 401,
+			expectedResponseBody:       oauth.ErrorResponse{"invalid_client", oauth.ErrInvalidClient.Error()},
+		},
+		{
+			desc:                       "scope is invalid, return invalid_scope",
+			givenBasicAuth:             true,
+			expectedRequest:            &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:read"},
+			mockOSErr:                  oauth.ErrInvalidScope,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       oauth.ErrorResponse{"invalid_scope", oauth.ErrInvalidScope.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockOAuthService.EXPECT().Token(gomock.Any(), tC.expectedRequest).Return(tC.mockOSResult, tC.mockOSErr)
+
+			form := url.Values{"grant_type": {"client_credentials"}, "scope": {"wallets:read"}}
+			if !tC.givenBasicAuth {
+				form.Set("client_id", "1")
+				form.Set("client_secret", "secret")
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/oauth/token", testServer.URL), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tC.givenBasicAuth {
+				req.SetBasicAuth("1", "secret")
+			}
+
+			res, err := testServer.Client().Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+			if res.StatusCode == 200 {
+				assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+			}
+			if res.StatusCode == 401 {
+				assert.NotEmpty(t, res.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestHandlerIntrospect(t *testing.T) {
+	mockOAuthService := createMockOAuthService(t)
+	h := oauth.NewHandler(mockOAuthService)
+
+	e := echo.New()
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	mockIntrospection := &oauth.Introspection{Active: true, ClientID: "1", Scope: "wallets:read"}
+	mockOAuthService.EXPECT().Introspect(gomock.Any(), "1", "secret", "token").Return(mockIntrospection, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/oauth/introspect", testServer.URL), strings.NewReader("token=token"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("1", "secret")
+
+	res, err := testServer.Client().Do(req)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(mockIntrospection)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+}
+
+func TestSkipper(t *testing.T) {
+	e := echo.New()
+
+	for path, expected := range map[string]bool{"/oauth/token": true, "/oauth/introspect": true, "/admin/oauth/clients": false} {
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, path, nil), httptest.NewRecorder())
+		c.SetPath(path)
+
+		assert.Equal(t, expected, oauth.Skipper(c))
+	}
+}
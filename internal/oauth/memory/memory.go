@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Memory struct {
+	mu      sync.RWMutex
+	clients map[string]*oauth.Client
+}
+
+func NewMemory() *Memory {
+	return &Memory{clients: make(map[string]*oauth.Client)}
+}
+
+func (m *Memory) Create(ctx context.Context, client *oauth.Client) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *client
+	stored.ID = primitive.NewObjectID().Hex()
+	m.clients[stored.ID] = &stored
+
+	return stored.ID, nil
+}
+
+func (m *Memory) Read(ctx context.Context, id string) (*oauth.Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	client, ok := m.clients[id]
+	if !ok {
+		return nil, oauth.ErrClientNotFound
+	}
+
+	found := *client
+	return &found, nil
+}
+
+func (m *Memory) ReadAll(ctx context.Context) ([]*oauth.Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clients := []*oauth.Client{}
+	for _, client := range m.clients {
+		found := *client
+		clients = append(clients, &found)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		if !clients[i].CreatedAt.Equal(clients[j].CreatedAt) {
+			return clients[i].CreatedAt.Before(clients[j].CreatedAt)
+		}
+		return clients[i].ID < clients[j].ID
+	})
+
+	return clients, nil
+}
+
+func (m *Memory) Revoke(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	client, ok := m.clients[id]
+	if !ok {
+		return oauth.ErrClientNotFound
+	}
+	client.Revoked = true
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/oauth (interfaces: ClientRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oauth "github.com/gokcelb/wallet-api/internal/oauth"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockClientRepository is a mock of ClientRepository interface.
+type MockClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientRepositoryMockRecorder
+}
+
+// MockClientRepositoryMockRecorder is the mock recorder for MockClientRepository.
+type MockClientRepositoryMockRecorder struct {
+	mock *MockClientRepository
+}
+
+// NewMockClientRepository creates a new mock instance.
+func NewMockClientRepository(ctrl *gomock.Controller) *MockClientRepository {
+	mock := &MockClientRepository{ctrl: ctrl}
+	mock.recorder = &MockClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientRepository) EXPECT() *MockClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockClientRepository) Create(arg0 context.Context, arg1 *oauth.Client) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockClientRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClientRepository)(nil).Create), arg0, arg1)
+}
+
+// Read mocks base method.
+func (m *MockClientRepository) Read(arg0 context.Context, arg1 string) (*oauth.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", arg0, arg1)
+	ret0, _ := ret[0].(*oauth.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockClientRepositoryMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockClientRepository)(nil).Read), arg0, arg1)
+}
+
+// ReadAll mocks base method.
+func (m *MockClientRepository) ReadAll(arg0 context.Context) ([]*oauth.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAll", arg0)
+	ret0, _ := ret[0].([]*oauth.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll.
+func (mr *MockClientRepositoryMockRecorder) ReadAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockClientRepository)(nil).ReadAll), arg0)
+}
+
+// Revoke mocks base method.
+func (m *MockClientRepository) Revoke(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockClientRepositoryMockRecorder) Revoke(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockClientRepository)(nil).Revoke), arg0, arg1)
+}
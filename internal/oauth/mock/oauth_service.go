@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/oauth (interfaces: OAuthService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oauth "github.com/gokcelb/wallet-api/internal/oauth"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOAuthService is a mock of OAuthService interface.
+type MockOAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthServiceMockRecorder
+}
+
+// MockOAuthServiceMockRecorder is the mock recorder for MockOAuthService.
+type MockOAuthServiceMockRecorder struct {
+	mock *MockOAuthService
+}
+
+// NewMockOAuthService creates a new mock instance.
+func NewMockOAuthService(ctrl *gomock.Controller) *MockOAuthService {
+	mock := &MockOAuthService{ctrl: ctrl}
+	mock.recorder = &MockOAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthService) EXPECT() *MockOAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// GetClients mocks base method.
+func (m *MockOAuthService) GetClients(arg0 context.Context) ([]*oauth.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClients", arg0)
+	ret0, _ := ret[0].([]*oauth.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClients indicates an expected call of GetClients.
+func (mr *MockOAuthServiceMockRecorder) GetClients(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClients", reflect.TypeOf((*MockOAuthService)(nil).GetClients), arg0)
+}
+
+// Introspect mocks base method.
+func (m *MockOAuthService) Introspect(arg0 context.Context, arg1, arg2, arg3 string) (*oauth.Introspection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*oauth.Introspection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MockOAuthServiceMockRecorder) Introspect(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*MockOAuthService)(nil).Introspect), arg0, arg1, arg2, arg3)
+}
+
+// RegisterClient mocks base method.
+func (m *MockOAuthService) RegisterClient(arg0 context.Context, arg1 *oauth.ClientRegistrationInfo) (*oauth.RegisteredClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterClient", arg0, arg1)
+	ret0, _ := ret[0].(*oauth.RegisteredClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterClient indicates an expected call of RegisterClient.
+func (mr *MockOAuthServiceMockRecorder) RegisterClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterClient", reflect.TypeOf((*MockOAuthService)(nil).RegisterClient), arg0, arg1)
+}
+
+// RevokeClient mocks base method.
+func (m *MockOAuthService) RevokeClient(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeClient", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeClient indicates an expected call of RevokeClient.
+func (mr *MockOAuthServiceMockRecorder) RevokeClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeClient", reflect.TypeOf((*MockOAuthService)(nil).RevokeClient), arg0, arg1)
+}
+
+// Token mocks base method.
+func (m *MockOAuthService) Token(arg0 context.Context, arg1 *oauth.TokenRequest) (*oauth.TokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", arg0, arg1)
+	ret0, _ := ret[0].(*oauth.TokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockOAuthServiceMockRecorder) Token(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockOAuthService)(nil).Token), arg0, arg1)
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import "time"
+
+type Client struct {
+	ID         string    `json:"clientId"`
+	Name       string    `json:"name"`
+	SecretHash string    `json:"-"`
+	Scopes     []string  `json:"scopes"`
+	WalletIDs  []string  `json:"walletIds,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Revoked    bool      `json:"revoked"`
+	// SigningKeyID, when set, rejects requests made with the client's tokens
+	// unless they are signed with the partner key of that id.
+	SigningKeyID string `json:"signingKeyId,omitempty"`
+}
+
+// RegisteredClient carries the plain secret, which is only ever shown on
+// registration.
+type RegisteredClient struct {
+	*Client
+	Secret string `json:"clientSecret"`
+}
+
+type ClientRegistrationInfo struct {
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	WalletIDs    []string `json:"walletIds"`
+	SigningKeyID string   `json:"signingKeyId"`
+}
+
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+}
+
+// TokenResponse and the types below follow the field names of RFC 6749 and
+// RFC 7662 rather than the camel case used elsewhere in the API.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type Introspection struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
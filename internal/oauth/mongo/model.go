@@ -0,0 +1,19 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mongoClient struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"name"`
+	SecretHash string             `bson:"secret_hash"`
+	Scopes     []string           `bson:"scopes"`
+	WalletIDs  []string           `bson:"wallet_ids"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	Revoked    bool               `bson:"revoked"`
+	// SigningKeyID is empty on clients registered before it was added.
+	SigningKeyID string `bson:"signing_key_id,omitempty"`
+}
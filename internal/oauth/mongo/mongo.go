@@ -0,0 +1,116 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection}
+}
+
+func (m *Mongo) Create(ctx context.Context, client *oauth.Client) (string, error) {
+	mongoClient := newMongoClientFromClient(client)
+	_, err := m.collection.InsertOne(ctx, mongoClient)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return mongoClient.ID.Hex(), nil
+}
+
+func (m *Mongo) Read(ctx context.Context, id string) (*oauth.Client, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, oauth.ErrClientNotFound
+	}
+
+	var mongoClient mongoClient
+	err = m.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mongoClient)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, oauth.ErrClientNotFound
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return newClientFromMongoClient(&mongoClient), nil
+}
+
+func (m *Mongo) ReadAll(ctx context.Context) ([]*oauth.Client, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoClients []mongoClient
+	if err = cursor.All(ctx, &mongoClients); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	clients := []*oauth.Client{}
+	for _, mongoClient := range mongoClients {
+		clients = append(clients, newClientFromMongoClient(&mongoClient))
+	}
+
+	return clients, nil
+}
+
+func (m *Mongo) Revoke(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return oauth.ErrClientNotFound
+	}
+
+	result, err := m.collection.UpdateByID(ctx, objectID, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return oauth.ErrClientNotFound
+	}
+
+	return nil
+}
+
+func newMongoClientFromClient(client *oauth.Client) *mongoClient {
+	return &mongoClient{
+		ID:           primitive.NewObjectID(),
+		Name:         client.Name,
+		SecretHash:   client.SecretHash,
+		Scopes:       client.Scopes,
+		WalletIDs:    client.WalletIDs,
+		CreatedAt:    client.CreatedAt,
+		Revoked:      client.Revoked,
+		SigningKeyID: client.SigningKeyID,
+	}
+}
+
+func newClientFromMongoClient(mongoClient *mongoClient) *oauth.Client {
+	return &oauth.Client{
+		ID:           mongoClient.ID.Hex(),
+		Name:         mongoClient.Name,
+		SecretHash:   mongoClient.SecretHash,
+		Scopes:       mongoClient.Scopes,
+		WalletIDs:    mongoClient.WalletIDs,
+		CreatedAt:    mongoClient.CreatedAt,
+		Revoked:      mongoClient.Revoked,
+		SigningKeyID: mongoClient.SigningKeyID,
+	}
+}
@@ -0,0 +1,233 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	tokenTypeBearer            = "Bearer"
+)
+
+var (
+	ErrClientNotFound       = errors.New("no client with the given id exists")
+	ErrMissingName          = errors.New("client name is required")
+	ErrMissingScopes        = errors.New("client needs at least one scope")
+	ErrInvalidRequest       = errors.New("request is missing a required parameter")
+	ErrUnsupportedGrantType = errors.New("grant type is not supported")
+	ErrInvalidClient        = errors.New("client authentication failed")
+	ErrInvalidScope         = errors.New("scope is invalid or exceeds the scopes of the client")
+	// ErrUnknownSigningKeyID is returned for signing key ids of no
+	// configured partner, including all of them while signing is disabled.
+	ErrUnknownSigningKeyID = errors.New("signing key id is not a configured partner key")
+)
+
+type ClientRepository interface {
+	Create(ctx context.Context, client *Client) (string, error)
+	Read(ctx context.Context, id string) (*Client, error)
+	ReadAll(ctx context.Context) ([]*Client, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+type TokenIssuer interface {
+	CreateForClient(clientID string, scopes, walletIDs []string, signingKeyID string) (string, *auth.Claims, error)
+	Verify(tokenString string) (*auth.Claims, error)
+	RevokeClient(ctx context.Context, clientID string) error
+}
+
+type service struct {
+	cr      ClientRepository
+	ti      TokenIssuer
+	signing config.SigningConf
+}
+
+func NewService(cr ClientRepository, ti TokenIssuer, signing config.SigningConf) *service {
+	return &service{cr, ti, signing}
+}
+
+func (s *service) RegisterClient(ctx context.Context, info *ClientRegistrationInfo) (*RegisteredClient, error) {
+	if strings.TrimSpace(info.Name) == "" {
+		return nil, ErrMissingName
+	}
+
+	if len(info.Scopes) == 0 {
+		return nil, ErrMissingScopes
+	}
+
+	for _, scope := range info.Scopes {
+		if !auth.IsDelegable(scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	if info.SigningKeyID != "" && !s.signing.HasPartner(info.SigningKeyID) {
+		return nil, ErrUnknownSigningKeyID
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	plainSecret := base64.RawURLEncoding.EncodeToString(secret)
+
+	client := &Client{
+		Name:         info.Name,
+		SecretHash:   hashSecret(plainSecret),
+		Scopes:       info.Scopes,
+		WalletIDs:    info.WalletIDs,
+		CreatedAt:    time.Now(),
+		SigningKeyID: info.SigningKeyID,
+	}
+
+	id, err := s.cr.Create(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	client.ID = id
+
+	return &RegisteredClient{client, plainSecret}, nil
+}
+
+func (s *service) GetClients(ctx context.Context) ([]*Client, error) {
+	return s.cr.ReadAll(ctx)
+}
+
+// RevokeClient also revokes the tokens already issued to the client, which
+// would otherwise work until they expire.
+func (s *service) RevokeClient(ctx context.Context, id string) error {
+	if err := s.cr.Revoke(ctx, id); err != nil {
+		return err
+	}
+
+	return s.ti.RevokeClient(ctx, id)
+}
+
+// Token implements the client credentials grant. Without a scope parameter
+// the client gets all of its scopes.
+func (s *service) Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	if req.GrantType == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	if req.GrantType != GrantTypeClientCredentials {
+		return nil, ErrUnsupportedGrantType
+	}
+
+	client, err := s.authenticate(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := client.Scopes
+	if req.Scope != "" {
+		scopes = strings.Fields(req.Scope)
+		for _, scope := range scopes {
+			if !contains(client.Scopes, scope) {
+				return nil, ErrInvalidScope
+			}
+		}
+	}
+
+	accessToken, claims, err := s.ti.CreateForClient(client.ID, scopes, client.WalletIDs, client.SigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   tokenTypeBearer,
+		ExpiresIn:   claims.ExpiresAt - claims.IssuedAt,
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// Introspect tells an authenticated client whether a token is active. Any
+// reason for a token to be rejected is reported the same way, as inactive.
+func (s *service) Introspect(ctx context.Context, clientID, clientSecret, token string) (*Introspection, error) {
+	if token == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	if _, err := s.authenticate(ctx, clientID, clientSecret); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.ti.Verify(token)
+	if err != nil {
+		return &Introspection{Active: false}, nil
+	}
+
+	if claims.ClientID != "" {
+		owner, err := s.cr.Read(ctx, claims.ClientID)
+		if err != nil && errors.Is(err, ErrClientNotFound) {
+			return &Introspection{Active: false}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if owner.Revoked {
+			return &Introspection{Active: false}, nil
+		}
+	}
+
+	return &Introspection{
+		Active:    true,
+		Scope:     strings.Join(claims.Scopes, " "),
+		ClientID:  claims.ClientID,
+		Subject:   claims.Subject,
+		TokenType: tokenTypeBearer,
+		ExpiresAt: claims.ExpiresAt,
+		IssuedAt:  claims.IssuedAt,
+		NotBefore: claims.NotBefore,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		ID:        claims.ID,
+	}, nil
+}
+
+func (s *service) authenticate(ctx context.Context, clientID, clientSecret string) (*Client, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.cr.Read(ctx, clientID)
+	if err != nil && errors.Is(err, ErrClientNotFound) {
+		return nil, ErrInvalidClient
+	} else if err != nil {
+		return nil, err
+	}
+
+	if client.Revoked || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashSecret(clientSecret))) != 1 {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// hashSecret does not need a slow hash since secrets are random and long
+// enough not to be guessed.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
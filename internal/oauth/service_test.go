@@ -0,0 +1,236 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	"github.com/gokcelb/wallet-api/internal/oauth/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockClientRepository(t *testing.T) *mock.MockClientRepository {
+	return mock.NewMockClientRepository(gomock.NewController(t))
+}
+
+func createMockTokenIssuer(t *testing.T) *mock.MockTokenIssuer {
+	return mock.NewMockTokenIssuer(gomock.NewController(t))
+}
+
+func getSigningConf() config.SigningConf {
+	return config.SigningConf{Enabled: true, Partners: []config.PartnerConf{{KeyID: "partner", Secret: "secret"}}}
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newClient() *oauth.Client {
+	return &oauth.Client{
+		ID:         "1",
+		SecretHash: hash("secret"),
+		Scopes:     []string{auth.ScopeWalletsRead, auth.ScopeTransactionsRead},
+		WalletIDs:  []string{"w1"},
+	}
+}
+
+func TestServiceRegisterClient(t *testing.T) {
+	mockClientRepository := createMockClientRepository(t)
+	s := oauth.NewService(mockClientRepository, createMockTokenIssuer(t), getSigningConf())
+
+	info := &oauth.ClientRegistrationInfo{Name: "partner", Scopes: []string{auth.ScopeWalletsRead}}
+
+	var stored *oauth.Client
+	mockClientRepository.EXPECT().
+		Create(context.TODO(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, client *oauth.Client) (string, error) {
+			stored = client
+			return "1", nil
+		})
+
+	client, err := s.RegisterClient(context.TODO(), info)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", client.ID)
+	assert.NotEmpty(t, client.Secret)
+	assert.Equal(t, hash(client.Secret), stored.SecretHash)
+
+	client, err = s.RegisterClient(context.TODO(), &oauth.ClientRegistrationInfo{Name: "partner", Scopes: []string{auth.ScopeAdmin}})
+
+	assert.Nil(t, client)
+	assert.ErrorIs(t, err, oauth.ErrInvalidScope)
+
+	disabled := oauth.NewService(mockClientRepository, createMockTokenIssuer(t), config.SigningConf{})
+	client, err = disabled.RegisterClient(context.TODO(), &oauth.ClientRegistrationInfo{
+		Name:         "partner",
+		Scopes:       []string{auth.ScopeWalletsRead},
+		SigningKeyID: "partner",
+	})
+
+	assert.Nil(t, client)
+	assert.ErrorIs(t, err, oauth.ErrUnknownSigningKeyID)
+}
+
+func TestServiceToken(t *testing.T) {
+	mockClientRepository := createMockClientRepository(t)
+	mockTokenIssuer := createMockTokenIssuer(t)
+	s := oauth.NewService(mockClientRepository, mockTokenIssuer, getSigningConf())
+
+	revokedClient := newClient()
+	revokedClient.Revoked = true
+
+	testCases := []struct {
+		desc           string
+		givenRequest   *oauth.TokenRequest
+		mockCRClient   *oauth.Client
+		mockCRErr      error
+		expectedScopes []string
+		expectedScope  string
+		expectedErr    error
+	}{
+		{
+			desc:           "scope is not requested, grant all scopes of the client",
+			givenRequest:   &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret"},
+			mockCRClient:   newClient(),
+			expectedScopes: []string{auth.ScopeWalletsRead, auth.ScopeTransactionsRead},
+			expectedScope:  "wallets:read transactions:read",
+		},
+		{
+			desc:           "scope is requested, grant the requested scopes",
+			givenRequest:   &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:read"},
+			mockCRClient:   newClient(),
+			expectedScopes: []string{auth.ScopeWalletsRead},
+			expectedScope:  "wallets:read",
+		},
+		{
+			desc:         "scope exceeds the client, return error",
+			givenRequest: &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret", Scope: "wallets:write"},
+			mockCRClient: newClient(),
+			expectedErr:  oauth.ErrInvalidScope,
+		},
+		{
+			desc:         "secret is wrong, return error",
+			givenRequest: &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "wrong"},
+			mockCRClient: newClient(),
+			expectedErr:  oauth.ErrInvalidClient,
+		},
+		{
+			desc:         "client is revoked, return error",
+			givenRequest: &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret"},
+			mockCRClient: revokedClient,
+			expectedErr:  oauth.ErrInvalidClient,
+		},
+		{
+			desc:         "client does not exist, return error",
+			givenRequest: &oauth.TokenRequest{GrantType: "client_credentials", ClientID: "1", ClientSecret: "secret"},
+			mockCRErr:    oauth.ErrClientNotFound,
+			expectedErr:  oauth.ErrInvalidClient,
+		},
+		{
+			desc:         "grant type is not supported, return error",
+			givenRequest: &oauth.TokenRequest{GrantType: "password", ClientID: "1", ClientSecret: "secret"},
+			expectedErr:  oauth.ErrUnsupportedGrantType,
+		},
+		{
+			desc:         "grant type is missing, return error",
+			givenRequest: &oauth.TokenRequest{ClientID: "1", ClientSecret: "secret"},
+			expectedErr:  oauth.ErrInvalidRequest,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.mockCRClient != nil || tC.mockCRErr != nil {
+				mockClientRepository.EXPECT().Read(context.TODO(), "1").Return(tC.mockCRClient, tC.mockCRErr)
+			}
+			if tC.expectedErr == nil {
+				mockTokenIssuer.EXPECT().
+					CreateForClient("1", tC.expectedScopes, []string{"w1"}, "").
+					Return("token", &auth.Claims{IssuedAt: 100, ExpiresAt: 1000}, nil)
+			}
+
+			res, err := s.Token(context.TODO(), tC.givenRequest)
+
+			assert.ErrorIs(t, err, tC.expectedErr)
+			if tC.expectedErr == nil {
+				assert.Equal(t, &oauth.TokenResponse{
+					AccessToken: "token",
+					TokenType:   "Bearer",
+					ExpiresIn:   900,
+					Scope:       tC.expectedScope,
+				}, res)
+			}
+		})
+	}
+}
+
+func TestServiceIntrospect(t *testing.T) {
+	mockClientRepository := createMockClientRepository(t)
+	mockTokenIssuer := createMockTokenIssuer(t)
+	s := oauth.NewService(mockClientRepository, mockTokenIssuer, getSigningConf())
+
+	mockClientRepository.EXPECT().Read(context.TODO(), "1").Return(newClient(), nil).Times(6)
+
+	mockTokenIssuer.EXPECT().
+		Verify("active").
+		Return(&auth.Claims{Subject: "client:1", ClientID: "1", Scopes: []string{auth.ScopeWalletsRead, auth.ScopeTransactionsRead}, ExpiresAt: 1000}, nil)
+	introspection, err := s.Introspect(context.TODO(), "1", "secret", "active")
+
+	assert.Nil(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, "wallets:read transactions:read", introspection.Scope)
+	assert.Equal(t, "1", introspection.ClientID)
+	assert.Equal(t, int64(1000), introspection.ExpiresAt)
+
+	mockTokenIssuer.EXPECT().Verify("expired").Return(nil, auth.ErrTokenExpired)
+	introspection, err = s.Introspect(context.TODO(), "1", "secret", "expired")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &oauth.Introspection{Active: false}, introspection)
+
+	revoked := newClient()
+	revoked.ID = "2"
+	revoked.Revoked = true
+	mockClientRepository.EXPECT().Read(context.TODO(), "2").Return(revoked, nil)
+	mockTokenIssuer.EXPECT().
+		Verify("revoked").
+		Return(&auth.Claims{Subject: "client:2", ClientID: "2", ExpiresAt: 1000}, nil)
+	introspection, err = s.Introspect(context.TODO(), "1", "secret", "revoked")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &oauth.Introspection{Active: false}, introspection)
+
+	mockClientRepository.EXPECT().Read(context.TODO(), "3").Return(nil, oauth.ErrClientNotFound)
+	mockTokenIssuer.EXPECT().
+		Verify("deleted").
+		Return(&auth.Claims{Subject: "client:3", ClientID: "3", ExpiresAt: 1000}, nil)
+	introspection, err = s.Introspect(context.TODO(), "1", "secret", "deleted")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &oauth.Introspection{Active: false}, introspection)
+
+	introspection, err = s.Introspect(context.TODO(), "1", "wrong", "active")
+
+	assert.Nil(t, introspection)
+	assert.ErrorIs(t, err, oauth.ErrInvalidClient)
+}
+
+func TestServiceRevokeClient(t *testing.T) {
+	mockClientRepository := createMockClientRepository(t)
+	mockTokenIssuer := createMockTokenIssuer(t)
+	s := oauth.NewService(mockClientRepository, mockTokenIssuer, getSigningConf())
+
+	mockClientRepository.EXPECT().Revoke(context.TODO(), "1").Return(nil)
+	mockTokenIssuer.EXPECT().RevokeClient(context.TODO(), "1").Return(nil)
+
+	assert.Nil(t, s.RevokeClient(context.TODO(), "1"))
+
+	mockClientRepository.EXPECT().Revoke(context.TODO(), "2").Return(oauth.ErrClientNotFound)
+
+	assert.ErrorIs(t, s.RevokeClient(context.TODO(), "2"), oauth.ErrClientNotFound)
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+
+	walletRoutePrefix = "/wallets/:id"
+)
+
+var ErrRateLimited = errors.New("too many requests, try again later")
+
+type limiter struct {
+	store Store
+	conf  config.RateLimitConf
+}
+
+func NewLimiter(store Store, conf config.RateLimitConf) *limiter {
+	return &limiter{store, conf}
+}
+
+// LimitCaller counts each request against the budget of its caller, with
+// separate budgets for reads and writes. Requests are let through when the
+// store fails, so that rate limiting cannot take the API down.
+func (l *limiter) LimitCaller(next echo.HandlerFunc) echo.HandlerFunc {
+	return l.limit(next, func(c echo.Context) (string, int) {
+		class, budget := l.budget(c)
+		return class + ":" + caller(c), budget.PerCaller
+	})
+}
+
+// LimitWallet counts requests to wallet routes against the budget of the
+// wallet. It has to run after the ownership check, or else anyone could use
+// up the budget of a wallet that is not theirs. The headers describe the
+// bucket closest to its limit, whichever of the two middlewares set it.
+func (l *limiter) LimitWallet(next echo.HandlerFunc) echo.HandlerFunc {
+	return l.limit(next, func(c echo.Context) (string, int) {
+		if !strings.HasPrefix(c.Path(), walletRoutePrefix) {
+			return "", 0
+		}
+
+		class, budget := l.budget(c)
+		return class + ":wallet:" + c.Param("id"), budget.PerWallet
+	})
+}
+
+// limit counts the request against the bucket returned by bucketOf, if it has
+// a limit.
+func (l *limiter) limit(next echo.HandlerFunc, bucketOf func(echo.Context) (string, int)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key, limit := bucketOf(c)
+		if limit <= 0 {
+			return next(c)
+		}
+
+		window := time.Second * time.Duration(l.conf.WindowInSec)
+		count, resetAt, err := l.store.Increment(c.Request().Context(), key, window)
+		if err != nil {
+			log.Error(err)
+			return next(c)
+		}
+
+		remaining := limit - count
+		header := c.Response().Header()
+		if set, err := strconv.Atoi(header.Get(HeaderRemaining)); err == nil && remaining >= 0 && set <= remaining {
+			return next(c)
+		}
+
+		reset := int(math.Ceil(time.Until(resetAt).Seconds()))
+		header.Set(HeaderLimit, strconv.Itoa(limit))
+		header.Set(HeaderRemaining, strconv.Itoa(max(remaining, 0)))
+		header.Set(HeaderReset, strconv.Itoa(reset))
+
+		if remaining < 0 {
+			header.Set(HeaderRetryAfter, strconv.Itoa(reset))
+			return echo.NewHTTPError(http.StatusTooManyRequests, ErrRateLimited.Error())
+		}
+
+		return next(c)
+	}
+}
+
+func (l *limiter) budget(c echo.Context) (string, config.RateLimitBudgetConf) {
+	if isWrite(c.Request().Method) {
+		return "write", l.conf.Write
+	}
+
+	return "read", l.conf.Read
+}
+
+// caller identifies the caller by the subject of its token, its API key or,
+// for anonymous callers, its IP address. API keys are hashed so that they do
+// not end up in the store.
+func caller(c echo.Context) string {
+	if userID, ok := auth.UserID(c); ok {
+		return "sub:" + userID
+	}
+
+	if claims, ok := auth.GetClaims(c); ok && claims.ClientID != "" {
+		return "client:" + claims.ClientID
+	}
+
+	if key := c.Request().Header.Get(apikey.Header); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:])
+	}
+
+	return "ip:" + c.RealIP()
+}
+
+func isWrite(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
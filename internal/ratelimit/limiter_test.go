@@ -0,0 +1,178 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/ratelimit"
+	"github.com/gokcelb/wallet-api/internal/ratelimit/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func getConf() config.RateLimitConf {
+	return config.RateLimitConf{
+		Enabled:     true,
+		WindowInSec: 60,
+		Read:        config.RateLimitBudgetConf{PerCaller: 3, PerWallet: 5},
+		Write:       config.RateLimitBudgetConf{PerCaller: 5, PerWallet: 2},
+	}
+}
+
+// newTestServer authenticates requests as the user in the X-User header and
+// answers 404 to strangers between the two limiters, like the ownership check.
+func newTestServer(limitCaller, limitWallet echo.MiddlewareFunc) *httptest.Server {
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if userID := c.Request().Header.Get("X-User"); userID != "" {
+				auth.SetClaims(c, &auth.Claims{Subject: userID})
+			}
+			return next(c)
+		}
+	})
+	e.Use(limitCaller)
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("X-User") == "stranger" {
+				return echo.NewHTTPError(http.StatusNotFound)
+			}
+			return next(c)
+		}
+	})
+	e.Use(limitWallet)
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/wallets/:id", ok)
+	e.POST("/wallets/:id/transactions", ok)
+
+	return httptest.NewServer(e.Server.Handler)
+}
+
+func do(t *testing.T, method, url, userID string) *http.Response {
+	req, _ := http.NewRequest(method, url, nil)
+	if userID != "" {
+		req.Header.Set("X-User", userID)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	res.Body.Close()
+
+	return res
+}
+
+func TestLimiterLimitPerCaller(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), getConf())
+	testServer := newTestServer(l.LimitCaller, l.LimitWallet)
+	defer testServer.Close()
+
+	for i := 0; i < 3; i++ {
+		res := do(t, http.MethodGet, testServer.URL+"/wallets/1", "1")
+
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, "3", res.Header.Get(ratelimit.HeaderLimit))
+		assert.Equal(t, []string{"2", "1", "0"}[i], res.Header.Get(ratelimit.HeaderRemaining))
+		assert.NotEmpty(t, res.Header.Get(ratelimit.HeaderReset))
+	}
+
+	res := do(t, http.MethodGet, testServer.URL+"/wallets/1", "1")
+
+	assert.Equal(t, 429, res.StatusCode)
+	assert.Equal(t, "0", res.Header.Get(ratelimit.HeaderRemaining))
+	assert.NotEmpty(t, res.Header.Get(ratelimit.HeaderRetryAfter))
+
+	res = do(t, http.MethodGet, testServer.URL+"/wallets/1", "2")
+
+	assert.Equal(t, 200, res.StatusCode, "other users have their own budget")
+
+	res = do(t, http.MethodPost, testServer.URL+"/wallets/2/transactions", "1")
+
+	assert.Equal(t, 200, res.StatusCode, "writes have their own budget")
+}
+
+func TestLimiterLimitPerWallet(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), getConf())
+	testServer := newTestServer(l.LimitCaller, l.LimitWallet)
+	defer testServer.Close()
+
+	assert.Equal(t, 200, do(t, http.MethodPost, testServer.URL+"/wallets/1/transactions", "1").StatusCode)
+	assert.Equal(t, 200, do(t, http.MethodPost, testServer.URL+"/wallets/1/transactions", "2").StatusCode)
+
+	res := do(t, http.MethodPost, testServer.URL+"/wallets/1/transactions", "3")
+
+	assert.Equal(t, 429, res.StatusCode)
+	assert.Equal(t, "2", res.Header.Get(ratelimit.HeaderLimit))
+}
+
+func TestLimiterLimitPerWalletIgnoresStrangers(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), getConf())
+	testServer := newTestServer(l.LimitCaller, l.LimitWallet)
+	defer testServer.Close()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 404, do(t, http.MethodPost, testServer.URL+"/wallets/1/transactions", "stranger").StatusCode)
+	}
+
+	assert.Equal(t, 200, do(t, http.MethodPost, testServer.URL+"/wallets/1/transactions", "1").StatusCode)
+}
+
+func TestLimiterLimitAnonymousCallers(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), getConf())
+	testServer := newTestServer(l.LimitCaller, l.LimitWallet)
+	defer testServer.Close()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 200, do(t, http.MethodGet, testServer.URL+"/wallets/1", "").StatusCode)
+	}
+
+	assert.Equal(t, 429, do(t, http.MethodGet, testServer.URL+"/wallets/2", "").StatusCode)
+}
+
+func TestLimiterLimitWithFailingStore(t *testing.T) {
+	mockStore := mock.NewMockStore(gomock.NewController(t))
+	l := ratelimit.NewLimiter(mockStore, getConf())
+	testServer := newTestServer(l.LimitCaller, l.LimitWallet)
+	defer testServer.Close()
+
+	mockStore.EXPECT().
+		Increment(gomock.Any(), "read:sub:1", time.Minute).
+		Return(0, time.Time{}, errors.New("store is down"))
+	mockStore.EXPECT().
+		Increment(gomock.Any(), "read:wallet:1", time.Minute).
+		Return(0, time.Time{}, errors.New("store is down"))
+
+	res := do(t, http.MethodGet, testServer.URL+"/wallets/1", "1")
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Get(ratelimit.HeaderLimit))
+}
+
+func TestMemoryStoreIncrement(t *testing.T) {
+	s := ratelimit.NewMemoryStore()
+
+	count, resetAt, err := s.Increment(context.TODO(), "key", time.Minute)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), resetAt, time.Second)
+
+	count, _, _ = s.Increment(context.TODO(), "key", time.Minute)
+
+	assert.Equal(t, 2, count)
+
+	_, _, _ = s.Increment(context.TODO(), "short", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	count, _, _ = s.Increment(context.TODO(), "short", time.Nanosecond)
+
+	assert.Equal(t, 1, count)
+}
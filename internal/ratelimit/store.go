@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store counts requests in fixed windows. Deployments running several
+// instances should plug in a shared Store, e.g. one backed by Redis INCR and
+// PEXPIRE.
+type Store interface {
+	// Increment counts a request against the key, returning the count so far
+	// in the current window and when the window resets.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{counters: make(map[string]*counter)}
+}
+
+func (s *memoryStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		s.prune(now)
+		c = &counter{resetAt: now.Add(window)}
+		s.counters[key] = c
+	}
+	c.count++
+
+	return c.count, c.resetAt, nil
+}
+
+func (s *memoryStore) prune(now time.Time) {
+	for key, c := range s.counters {
+		if !now.Before(c.resetAt) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package signing
+
+import (
+	"context"
+
+	"github.com/gokcelb/wallet-api/config"
+)
+
+// localSecretStore serves the partner secrets listed in the config file.
+type localSecretStore struct {
+	secrets map[string]string
+}
+
+func NewLocalSecretStore(partners []config.PartnerConf) *localSecretStore {
+	s := &localSecretStore{secrets: make(map[string]string, len(partners))}
+	for _, p := range partners {
+		s.secrets[p.KeyID] = p.Secret
+	}
+
+	return s
+}
+
+func (s *localSecretStore) ReadSecret(ctx context.Context, keyID string) (string, error) {
+	secret, ok := s.secrets[keyID]
+	if !ok {
+		return "", ErrUnknownKeyID
+	}
+
+	return secret, nil
+}
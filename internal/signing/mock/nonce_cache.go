@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/signing (interfaces: NonceCache)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNonceCache is a mock of NonceCache interface.
+type MockNonceCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockNonceCacheMockRecorder
+}
+
+// MockNonceCacheMockRecorder is the mock recorder for MockNonceCache.
+type MockNonceCacheMockRecorder struct {
+	mock *MockNonceCache
+}
+
+// NewMockNonceCache creates a new mock instance.
+func NewMockNonceCache(ctrl *gomock.Controller) *MockNonceCache {
+	mock := &MockNonceCache{ctrl: ctrl}
+	mock.recorder = &MockNonceCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNonceCache) EXPECT() *MockNonceCacheMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockNonceCache) Add(arg0 string, arg1 time.Time) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockNonceCacheMockRecorder) Add(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockNonceCache)(nil).Add), arg0, arg1)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/signing (interfaces: SecretStore)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSecretStore is a mock of SecretStore interface.
+type MockSecretStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretStoreMockRecorder
+}
+
+// MockSecretStoreMockRecorder is the mock recorder for MockSecretStore.
+type MockSecretStoreMockRecorder struct {
+	mock *MockSecretStore
+}
+
+// NewMockSecretStore creates a new mock instance.
+func NewMockSecretStore(ctrl *gomock.Controller) *MockSecretStore {
+	mock := &MockSecretStore{ctrl: ctrl}
+	mock.recorder = &MockSecretStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretStore) EXPECT() *MockSecretStoreMockRecorder {
+	return m.recorder
+}
+
+// ReadSecret mocks base method.
+func (m *MockSecretStore) ReadSecret(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecret", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSecret indicates an expected call of ReadSecret.
+func (mr *MockSecretStoreMockRecorder) ReadSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecret", reflect.TypeOf((*MockSecretStore)(nil).ReadSecret), arg0, arg1)
+}
@@ -0,0 +1,40 @@
+package signing
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryNonceCache is local to the process, so deployments running several
+// instances should plug in a shared NonceCache.
+type memoryNonceCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func NewMemoryNonceCache() *memoryNonceCache {
+	return &memoryNonceCache{entries: make(map[string]time.Time)}
+}
+
+func (nc *memoryNonceCache) Add(nonce string, expiresAt time.Time) bool {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	now := time.Now()
+	nc.prune(now)
+
+	if _, ok := nc.entries[nonce]; ok {
+		return false
+	}
+	nc.entries[nonce] = expiresAt
+
+	return true
+}
+
+func (nc *memoryNonceCache) prune(now time.Time) {
+	for nonce, expiresAt := range nc.entries {
+		if !now.Before(expiresAt) {
+			delete(nc.entries, nonce)
+		}
+	}
+}
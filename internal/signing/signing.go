@@ -0,0 +1,200 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderKeyID     = "X-Signature-Key-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+
+	defaultMaxBodyBytes = 1 << 20
+)
+
+var (
+	ErrSignatureMissing    = errors.New("request is not signed")
+	ErrSignatureMalformed  = errors.New("request signature headers are incomplete or malformed")
+	ErrUnknownKeyID        = errors.New("signature key id is unknown")
+	ErrKeyIDMismatch       = errors.New("signature key id is not the one of the caller")
+	ErrTimestampOutOfRange = errors.New("signature timestamp is too far from the server time")
+	ErrNonceReused         = errors.New("signature nonce has already been used")
+	ErrInvalidSignature    = errors.New("request signature does not match")
+	ErrBodyTooLarge        = errors.New("signed request body is too large")
+)
+
+// signatureErrorCodes tell partners why a signed request was rejected.
+var signatureErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrSignatureMissing, "signature_missing"},
+	{ErrSignatureMalformed, "signature_malformed"},
+	{ErrUnknownKeyID, "unknown_key_id"},
+	{ErrKeyIDMismatch, "key_id_mismatch"},
+	{ErrTimestampOutOfRange, "timestamp_out_of_range"},
+	{ErrNonceReused, "nonce_reused"},
+	{ErrInvalidSignature, "signature_invalid"},
+}
+
+type SecretStore interface {
+	ReadSecret(ctx context.Context, keyID string) (string, error)
+}
+
+type NonceCache interface {
+	// Add records the nonce until it expires, returning false if it is
+	// already recorded.
+	Add(nonce string, expiresAt time.Time) bool
+}
+
+type verifier struct {
+	ss      SecretStore
+	nc      NonceCache
+	conf    config.SigningConf
+	skipper func(echo.Context) bool
+}
+
+// NewVerifier returns a verifier which, when signatures are required, lets
+// the requests matched by skipper through unsigned.
+func NewVerifier(ss SecretStore, nc NonceCache, conf config.SigningConf, skipper func(echo.Context) bool) *verifier {
+	return &verifier{ss, nc, conf, skipper}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the request line, timestamp,
+// nonce and body digest, separated by newlines.
+func Sign(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	digest := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		method,
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(digest[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of signed requests. Unsigned requests are let
+// through unless signatures are required, for everyone or for the OAuth
+// client or API key making the request, so it has to run after
+// authentication. Callers with a signing key id have to sign with that key.
+func (v *verifier) Verify(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		callerKeyID := ""
+		if claims, ok := auth.GetClaims(c); ok {
+			callerKeyID = claims.SigningKeyID
+		}
+
+		req := c.Request()
+		if req.Header.Get(HeaderSignature) == "" && req.Header.Get(HeaderKeyID) == "" {
+			required := v.conf.Required || callerKeyID != ""
+			if !required || (v.skipper != nil && v.skipper(c)) {
+				return next(c)
+			}
+			return signatureError(ErrSignatureMissing)
+		}
+
+		keyID, err := v.verify(req, time.Now())
+		if err != nil {
+			return signatureError(err)
+		}
+
+		if callerKeyID != "" && keyID != callerKeyID {
+			return signatureError(ErrKeyIDMismatch)
+		}
+
+		return next(c)
+	}
+}
+
+func (v *verifier) verify(req *http.Request, now time.Time) (string, error) {
+	keyID := req.Header.Get(HeaderKeyID)
+	timestamp := req.Header.Get(HeaderTimestamp)
+	nonce := req.Header.Get(HeaderNonce)
+	signature := req.Header.Get(HeaderSignature)
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return "", ErrSignatureMalformed
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrSignatureMalformed
+	}
+
+	signedAt := time.Unix(unix, 0)
+	tolerance := time.Second * time.Duration(v.conf.ToleranceInSec)
+	if signedAt.Before(now.Add(-tolerance)) || signedAt.After(now.Add(tolerance)) {
+		return "", ErrTimestampOutOfRange
+	}
+
+	secret, err := v.ss.ReadSecret(req.Context(), keyID)
+	if err != nil {
+		return "", err
+	}
+
+	// One byte more than allowed is read to tell a body at the limit from
+	// a larger one.
+	maxBodyBytes := v.maxBodyBytes()
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodyBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > maxBodyBytes {
+		return "", ErrBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected := Sign(secret, req.Method, req.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return "", ErrInvalidSignature
+	}
+
+	// Nonces are only checked on valid signatures so that forged requests
+	// cannot burn the nonces of real ones. They need to be kept only as long
+	// as their timestamp is acceptable.
+	if !v.nc.Add(keyID+":"+nonce, signedAt.Add(tolerance)) {
+		return "", ErrNonceReused
+	}
+
+	return keyID, nil
+}
+
+func (v *verifier) maxBodyBytes() int64 {
+	if v.conf.MaxBodyBytes <= 0 {
+		return defaultMaxBodyBytes
+	}
+
+	return v.conf.MaxBodyBytes
+}
+
+func signatureError(err error) error {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	}
+
+	for _, sec := range signatureErrorCodes {
+		if errors.Is(err, sec.err) {
+			return echo.NewHTTPError(http.StatusUnauthorized, auth.TokenErrorResponse{Code: sec.code, Message: err.Error()})
+		}
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
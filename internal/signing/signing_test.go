@@ -0,0 +1,286 @@
+package signing_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/signing"
+	"github.com/gokcelb/wallet-api/internal/signing/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockSecretStore(t *testing.T) *mock.MockSecretStore {
+	return mock.NewMockSecretStore(gomock.NewController(t))
+}
+
+func getConf() config.SigningConf {
+	return config.SigningConf{Enabled: true, ToleranceInSec: 300}
+}
+
+// newTestServer runs the given middlewares, standing in for authentication,
+// before the verifier.
+func newTestServer(conf config.SigningConf, ss signing.SecretStore, middlewares ...echo.MiddlewareFunc) *httptest.Server {
+	v := signing.NewVerifier(ss, signing.NewMemoryNonceCache(), conf, func(c echo.Context) bool {
+		return c.Path() == "/auth/token"
+	})
+
+	e := echo.New()
+	e.Use(middlewares...)
+	e.Use(v.Verify)
+	handler := func(c echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusOK, string(body))
+	}
+	e.POST("/wallets/:id/transactions", handler)
+	e.POST("/auth/token", handler)
+
+	return httptest.NewServer(e.Server.Handler)
+}
+
+type signedRequest struct {
+	keyID     string
+	secret    string
+	timestamp time.Time
+	nonce     string
+	body      string
+	tamper    string
+}
+
+func (sr signedRequest) build(url string) *http.Request {
+	timestamp := strconv.FormatInt(sr.timestamp.Unix(), 10)
+	signature := signing.Sign(sr.secret, http.MethodPost, "/wallets/1/transactions", timestamp, sr.nonce, []byte(sr.body))
+
+	body := sr.body
+	if sr.tamper != "" {
+		body = sr.tamper
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, url+"/wallets/1/transactions", strings.NewReader(body))
+	req.Header.Set(signing.HeaderKeyID, sr.keyID)
+	req.Header.Set(signing.HeaderTimestamp, timestamp)
+	req.Header.Set(signing.HeaderNonce, sr.nonce)
+	req.Header.Set(signing.HeaderSignature, signature)
+	return req
+}
+
+func TestVerifierVerify(t *testing.T) {
+	mockSecretStore := createMockSecretStore(t)
+	mockSecretStore.EXPECT().ReadSecret(gomock.Any(), "partner").Return("secret", nil).AnyTimes()
+	mockSecretStore.EXPECT().ReadSecret(gomock.Any(), "unknown").Return("", signing.ErrUnknownKeyID).AnyTimes()
+
+	testServer := newTestServer(getConf(), mockSecretStore)
+	defer testServer.Close()
+
+	now := time.Now()
+
+	testCases := []struct {
+		desc                       string
+		givenRequest               signedRequest
+		expectedResponseStatusCode int
+		expectedCode               string
+	}{
+		{
+			desc:                       "signature is valid, pass the body through",
+			givenRequest:               signedRequest{"partner", "secret", now, "n1", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 200,
+		},
+		{
+			desc:                       "nonce is reused, return error",
+			givenRequest:               signedRequest{"partner", "secret", now, "n1", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "nonce_reused",
+		},
+		{
+			desc:                       "body is tampered with, return error",
+			givenRequest:               signedRequest{"partner", "secret", now, "n2", `{"amount":10}`, `{"amount":1000}`},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "signature_invalid",
+		},
+		{
+			desc:                       "secret is wrong, return error",
+			givenRequest:               signedRequest{"partner", "wrong", now, "n3", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "signature_invalid",
+		},
+		{
+			desc:                       "timestamp is too old, return error",
+			givenRequest:               signedRequest{"partner", "secret", now.Add(-time.Hour), "n4", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "timestamp_out_of_range",
+		},
+		{
+			desc:                       "key id is unknown, return error",
+			givenRequest:               signedRequest{"unknown", "secret", now, "n5", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "unknown_key_id",
+		},
+		{
+			desc:                       "nonce is missing, return error",
+			givenRequest:               signedRequest{"partner", "secret", now, "", `{"amount":10}`, ""},
+			expectedResponseStatusCode: 401,
+			expectedCode:               "signature_malformed",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := testServer.Client().Do(tC.givenRequest.build(testServer.URL))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			if tC.expectedCode == "" {
+				assert.Equal(t, tC.givenRequest.body, string(resBodyBytes))
+			} else {
+				var resErr auth.TokenErrorResponse
+				_ = json.Unmarshal(resBodyBytes, &resErr)
+				assert.Equal(t, tC.expectedCode, resErr.Code)
+			}
+		})
+	}
+}
+
+func TestVerifierVerifyUnsigned(t *testing.T) {
+	optional := newTestServer(getConf(), createMockSecretStore(t))
+	defer optional.Close()
+
+	required := getConf()
+	required.Required = true
+	requiredServer := newTestServer(required, createMockSecretStore(t))
+	defer requiredServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenURL                   string
+		expectedResponseStatusCode int
+	}{
+		{
+			desc:                       "signatures are optional, pass through",
+			givenURL:                   optional.URL + "/wallets/1/transactions",
+			expectedResponseStatusCode: 200,
+		},
+		{
+			desc:                       "signatures are required, return error",
+			givenURL:                   requiredServer.URL + "/wallets/1/transactions",
+			expectedResponseStatusCode: 401,
+		},
+		{
+			desc:                       "signatures are required but path is skipped, pass through",
+			givenURL:                   requiredServer.URL + "/auth/token",
+			expectedResponseStatusCode: 200,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := http.Post(tC.givenURL, "application/json", strings.NewReader("{}"))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestVerifierVerifyRequiredByCaller(t *testing.T) {
+	mockSecretStore := createMockSecretStore(t)
+	mockSecretStore.EXPECT().ReadSecret(gomock.Any(), "partner").Return("secret", nil).AnyTimes()
+	mockSecretStore.EXPECT().ReadSecret(gomock.Any(), "other").Return("other-secret", nil).AnyTimes()
+
+	withClaims := func(claims *auth.Claims) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				auth.SetClaims(c, claims)
+				return next(c)
+			}
+		}
+	}
+	requiredServer := newTestServer(getConf(), mockSecretStore, withClaims(&auth.Claims{ClientID: "1", SigningKeyID: "partner"}))
+	defer requiredServer.Close()
+	optionalServer := newTestServer(getConf(), mockSecretStore, withClaims(&auth.Claims{ClientID: "2"}))
+	defer optionalServer.Close()
+
+	res, err := http.Post(requiredServer.URL+"/wallets/1/transactions", "application/json", strings.NewReader("{}"))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res, err = requiredServer.Client().Do(signedRequest{"partner", "secret", time.Now(), "n1", "{}", ""}.build(requiredServer.URL))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Another partner's key can't sign for the caller.
+	res, err = requiredServer.Client().Do(signedRequest{"other", "other-secret", time.Now(), "n2", "{}", ""}.build(requiredServer.URL))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res, err = http.Post(optionalServer.URL+"/wallets/1/transactions", "application/json", strings.NewReader("{}"))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestVerifierVerifyBodyTooLarge(t *testing.T) {
+	mockSecretStore := createMockSecretStore(t)
+	mockSecretStore.EXPECT().ReadSecret(gomock.Any(), "partner").Return("secret", nil).AnyTimes()
+
+	conf := getConf()
+	conf.MaxBodyBytes = 16
+	testServer := newTestServer(conf, mockSecretStore)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Do(signedRequest{"partner", "secret", time.Now(), "n1", `{"amount":10}`, ""}.build(testServer.URL))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = testServer.Client().Do(signedRequest{"partner", "secret", time.Now(), "n2", `{"amount":10,"description":"too long"}`, ""}.build(testServer.URL))
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func TestLocalSecretStoreReadSecret(t *testing.T) {
+	s := signing.NewLocalSecretStore([]config.PartnerConf{{KeyID: "partner", Secret: "secret"}})
+
+	secret, err := s.ReadSecret(context.TODO(), "partner")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", secret)
+
+	_, err = s.ReadSecret(context.TODO(), "unknown")
+
+	assert.ErrorIs(t, err, signing.ErrUnknownKeyID)
+}
+
+func TestMemoryNonceCacheAdd(t *testing.T) {
+	nc := signing.NewMemoryNonceCache()
+
+	assert.True(t, nc.Add("n1", time.Now().Add(time.Minute)))
+	assert.False(t, nc.Add("n1", time.Now().Add(time.Minute)))
+	assert.True(t, nc.Add("n2", time.Now().Add(-time.Second)))
+	assert.True(t, nc.Add("n2", time.Now().Add(time.Minute)))
+}
@@ -0,0 +1,9 @@
+CREATE TABLE wallets (
+    id                      TEXT PRIMARY KEY,
+    user_id                 TEXT NOT NULL,
+    balance                 REAL NOT NULL DEFAULT 0,
+    balance_upper_limit     REAL NOT NULL DEFAULT 0,
+    transaction_upper_limit REAL NOT NULL DEFAULT 0
+);
+
+CREATE INDEX wallets_user_id ON wallets (user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE transactions (
+    id             TEXT PRIMARY KEY,
+    wallet_id      TEXT NOT NULL,
+    type           TEXT NOT NULL,
+    amount         REAL NOT NULL,
+    balance_before REAL NOT NULL,
+    balance_after  REAL NOT NULL,
+    created_at     INTEGER NOT NULL
+);
+
+CREATE INDEX transactions_wallet_id_created_at ON transactions (wallet_id, created_at, id);
+CREATE INDEX transactions_created_at ON transactions (created_at);
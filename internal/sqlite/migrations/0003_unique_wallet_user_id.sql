@@ -0,0 +1,3 @@
+DROP INDEX wallets_user_id;
+
+CREATE UNIQUE INDEX wallets_user_id ON wallets (user_id);
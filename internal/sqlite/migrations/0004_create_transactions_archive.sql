@@ -0,0 +1,12 @@
+CREATE TABLE transactions_archive (
+    id             TEXT PRIMARY KEY,
+    wallet_id      TEXT NOT NULL,
+    type           TEXT NOT NULL,
+    amount         REAL NOT NULL,
+    balance_before REAL NOT NULL,
+    balance_after  REAL NOT NULL,
+    created_at     INTEGER NOT NULL
+);
+
+CREATE INDEX transactions_archive_wallet_id_created_at ON transactions_archive (wallet_id, created_at, id);
+CREATE INDEX transactions_archive_created_at ON transactions_archive (created_at);
@@ -0,0 +1 @@
+ALTER TABLE wallets ADD COLUMN last_transaction_at INTEGER NOT NULL DEFAULT 0;
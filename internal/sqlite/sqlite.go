@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/labstack/gommon/log"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open opens the database at conf.Path and migrates it to the latest schema.
+// Transactions begin immediately, taking the write lock up front, so that a
+// read followed by a write in one transaction can't be interleaved.
+func Open(ctx context.Context, conf config.SQLiteConf) (*sql.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?%s", conf.Path, q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// IsUniqueViolation tells if err comes from breaking a unique constraint.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+type migration struct {
+	version int
+	name    string
+}
+
+// Migrate applies the migrations that haven't been applied yet in version
+// order, each in its own transaction along with its schema_migrations row.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	pending, err := readMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if err = apply(ctx, db, m); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.version).Scan(&applied)
+	if err != nil || applied > 0 {
+		return err
+	}
+
+	script, err := migrations.ReadFile("migrations/" + m.name)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, string(script)); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	log.Infof("applied sqlite migration %s", m.name)
+	return tx.Commit()
+}
+
+// readMigrations lists the embedded migrations, which are named after their
+// version like 0001_create_wallets.sql.
+func readMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	ms := []migration{}
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s is not prefixed with its version", entry.Name())
+		}
+		ms = append(ms, migration{version, entry.Name()})
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].version < ms[j].version
+	})
+
+	return ms, nil
+}
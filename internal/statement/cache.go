@@ -0,0 +1,40 @@
+package statement
+
+import "sync"
+
+type memoryCache struct {
+	mu    sync.Mutex
+	size  int
+	keys  []string
+	items map[string]*Statement
+}
+
+func NewMemoryCache(size int) *memoryCache {
+	return &memoryCache{size: size, items: map[string]*Statement{}}
+}
+
+func (c *memoryCache) Get(key string) (*Statement, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st, ok := c.items[key]
+	return st, ok
+}
+
+func (c *memoryCache) Set(key string, st *Statement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	if _, ok := c.items[key]; !ok {
+		if len(c.keys) >= c.size {
+			delete(c.items, c.keys[0])
+			c.keys = c.keys[1:]
+		}
+		c.keys = append(c.keys, key)
+	}
+	c.items[key] = st
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func WriteCSV(w io.Writer, st *Statement) error {
+	cw := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"walletId", "from", "to", "openingBalance", "closingBalance"},
+		{
+			st.WalletID,
+			st.From.Format(time.RFC3339),
+			st.To.Format(time.RFC3339),
+			formatAmount(st.OpeningBalance),
+			formatAmount(st.ClosingBalance),
+		},
+		{},
+		{"type", "count", "amount"},
+	}
+
+	types := make([]string, 0, len(st.Totals))
+	for txnType := range st.Totals {
+		types = append(types, txnType)
+	}
+	sort.Strings(types)
+
+	for _, txnType := range types {
+		total := st.Totals[txnType]
+		rows = append(rows, []string{txnType, strconv.Itoa(total.Count), formatAmount(total.Amount)})
+	}
+
+	rows = append(rows, []string{}, []string{"id", "createdAt", "type", "amount", "balanceBefore", "balanceAfter"})
+	for _, txn := range st.Transactions {
+		rows = append(rows, []string{
+			txn.ID,
+			txn.CreatedAt.In(st.From.Location()).Format(time.RFC3339),
+			txn.Type,
+			formatAmount(txn.Amount),
+			formatAmount(txn.BalanceBefore),
+			formatAmount(txn.BalanceAfter),
+		})
+	}
+
+	return cw.WriteAll(rows)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
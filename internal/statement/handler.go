@@ -0,0 +1,118 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+
+	monthLayout = "2006-01"
+)
+
+var (
+	ErrInvalidMonth  = errors.New("month must be in YYYY-MM format")
+	ErrMissingPeriod = errors.New("either month or both from and to must be given")
+	ErrInvalidFormat = errors.New("format must be json or csv")
+)
+
+var badRequestErrors = []error{
+	ErrInvalidPeriod,
+	transaction.ErrInvalidTimeZone,
+	ErrInvalidMonth,
+	transaction.ErrInvalidDate,
+	ErrMissingPeriod,
+	ErrInvalidFormat,
+}
+
+type StatementService interface {
+	GenerateStatement(ctx context.Context, walletID string, from, to time.Time) (*Statement, error)
+}
+
+type handler struct {
+	ss StatementService
+}
+
+func NewHandler(ss StatementService) *handler {
+	return &handler{ss}
+}
+
+func (h *handler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/wallets/:id/statement", h.GetStatement, auth.RequireScopes(auth.ScopeTransactionsRead))
+}
+
+func (h *handler) GetStatement(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatCSV {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidFormat.Error())
+	}
+
+	from, to, err := parsePeriod(c.QueryParam("month"), c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("tz"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	st, err := h.ss.GenerateStatement(c.Request().Context(), c.Param("id"), from, to)
+	if err != nil && wallet.ContainsError(err, badRequestErrors) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, wallet.ErrWalletNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if format == FormatCSV {
+		filename := fmt.Sprintf("statement-%s-%s.csv", st.WalletID, st.From.Format(transaction.DateLayout))
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		c.Response().WriteHeader(http.StatusOK)
+		return WriteCSV(c.Response(), st)
+	}
+
+	return c.JSON(http.StatusOK, st)
+}
+
+func parsePeriod(monthQuery, fromQuery, toQuery, tzQuery string) (time.Time, time.Time, error) {
+	loc, err := transaction.ParseLocation(tzQuery)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if monthQuery != "" {
+		month, err := time.ParseInLocation(monthLayout, monthQuery, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidMonth
+		}
+
+		return month, month.AddDate(0, 1, 0), nil
+	}
+
+	if fromQuery == "" || toQuery == "" {
+		return time.Time{}, time.Time{}, ErrMissingPeriod
+	}
+
+	from, err := transaction.ParseFrom(fromQuery, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to, err := transaction.ParseTo(toQuery, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return from, to, nil
+}
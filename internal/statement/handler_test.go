@@ -0,0 +1,270 @@
+package statement_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/auth/authtest"
+	"github.com/gokcelb/wallet-api/internal/statement"
+	"github.com/gokcelb/wallet-api/internal/statement/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type httpErr struct {
+	Message string `json:"message"`
+}
+
+func createMockStatementService(t *testing.T) *mock.MockStatementService {
+	return mock.NewMockStatementService(gomock.NewController(t))
+}
+
+func TestHandlerGetStatement(t *testing.T) {
+	mockStatementService := createMockStatementService(t)
+	h := statement.NewHandler(mockStatementService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	march := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	marchIstanbul := time.Date(2022, time.March, 1, 0, 0, 0, 0, istanbul)
+	mockStatement := &statement.Statement{
+		WalletID:       "1",
+		From:           march,
+		To:             march.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		ClosingBalance: 300,
+		Totals:         map[string]*statement.TypeTotal{"deposit": {Count: 1, Amount: 200}},
+		Transactions: []*transaction.Transaction{
+			{
+				ID:            "1",
+				WalletID:      "1",
+				Type:          "deposit",
+				Amount:        200,
+				BalanceBefore: 100,
+				BalanceAfter:  300,
+				CreatedAt:     march.Add(time.Hour),
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc                       string
+		givenQuery                 string
+		expectedFrom               time.Time
+		expectedTo                 time.Time
+		mockSSErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "month is given, return statement",
+			givenQuery:                 "month=2022-03",
+			expectedFrom:               march,
+			expectedTo:                 march.AddDate(0, 1, 0),
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       mockStatement,
+		},
+		{
+			desc:                       "month and time zone are given, return statement",
+			givenQuery:                 "month=2022-03&tz=Europe/Istanbul",
+			expectedFrom:               marchIstanbul,
+			expectedTo:                 marchIstanbul.AddDate(0, 1, 0),
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       mockStatement,
+		},
+		{
+			desc:                       "date range is given, return statement including the last day",
+			givenQuery:                 "from=2022-03-01&to=2022-03-15",
+			expectedFrom:               march,
+			expectedTo:                 march.AddDate(0, 0, 15),
+			expectedResponseStatusCode: 200,
+			expectedResponseBody:       mockStatement,
+		},
+		{
+			desc:                       "wallet does not exist, return error",
+			givenQuery:                 "month=2022-03",
+			expectedFrom:               march,
+			expectedTo:                 march.AddDate(0, 1, 0),
+			mockSSErr:                  wallet.ErrWalletNotFound,
+			expectedResponseStatusCode: 404,
+			expectedResponseBody:       httpErr{wallet.ErrWalletNotFound.Error()},
+		},
+		{
+			desc:                       "period end is before its start, return error",
+			givenQuery:                 "from=2022-03-15&to=2022-03-01",
+			expectedFrom:               march.AddDate(0, 0, 14),
+			expectedTo:                 march.AddDate(0, 0, 1),
+			mockSSErr:                  statement.ErrInvalidPeriod,
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{statement.ErrInvalidPeriod.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var mockSSStatement *statement.Statement
+			if tC.mockSSErr == nil {
+				mockSSStatement = mockStatement
+			}
+
+			mockStatementService.EXPECT().
+				GenerateStatement(gomock.Any(), "1", tC.expectedFrom, tC.expectedTo).
+				Return(mockSSStatement, tC.mockSSErr)
+
+			res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/statement?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerGetStatementCSV(t *testing.T) {
+	mockStatementService := createMockStatementService(t)
+	h := statement.NewHandler(mockStatementService)
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	march := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	mockStatement := &statement.Statement{
+		WalletID:       "1",
+		From:           march,
+		To:             march.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		ClosingBalance: 300,
+		Totals:         map[string]*statement.TypeTotal{"deposit": {Count: 1, Amount: 200}},
+		Transactions: []*transaction.Transaction{
+			{
+				ID:            "1",
+				WalletID:      "1",
+				Type:          "deposit",
+				Amount:        200,
+				BalanceBefore: 100,
+				BalanceAfter:  300,
+				CreatedAt:     march.Add(time.Hour),
+			},
+		},
+	}
+	expectedCSV := "walletId,from,to,openingBalance,closingBalance\n" +
+		"1,2022-03-01T00:00:00Z,2022-04-01T00:00:00Z,100.00,300.00\n" +
+		"\n" +
+		"type,count,amount\n" +
+		"deposit,1,200.00\n" +
+		"\n" +
+		"id,createdAt,type,amount,balanceBefore,balanceAfter\n" +
+		"1,2022-03-01T01:00:00Z,deposit,200.00,100.00,300.00\n"
+
+	mockStatementService.EXPECT().
+		GenerateStatement(gomock.Any(), "1", march, march.AddDate(0, 1, 0)).
+		Return(mockStatement, nil)
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/statement?month=2022-03&format=csv", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "text/csv", res.Header.Get(echo.HeaderContentType))
+	assert.Equal(t, expectedCSV, string(resBodyBytes))
+}
+
+func TestHandlerGetStatementInvalidParams(t *testing.T) {
+	h := statement.NewHandler(createMockStatementService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsRead))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                 string
+		givenQuery           string
+		expectedResponseBody interface{}
+	}{
+		{
+			desc:                 "no period given, return error",
+			givenQuery:           "",
+			expectedResponseBody: httpErr{statement.ErrMissingPeriod.Error()},
+		},
+		{
+			desc:                 "invalid month, return error",
+			givenQuery:           "month=march",
+			expectedResponseBody: httpErr{statement.ErrInvalidMonth.Error()},
+		},
+		{
+			desc:                 "invalid date, return error",
+			givenQuery:           "from=yesterday&to=2022-03-01",
+			expectedResponseBody: httpErr{transaction.ErrInvalidDate.Error()},
+		},
+		{
+			desc:                 "invalid time zone, return error",
+			givenQuery:           "month=2022-03&tz=Mars/Olympus",
+			expectedResponseBody: httpErr{transaction.ErrInvalidTimeZone.Error()},
+		},
+		{
+			desc:                 "invalid format, return error",
+			givenQuery:           "month=2022-03&format=pdf",
+			expectedResponseBody: httpErr{statement.ErrInvalidFormat.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1/statement?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, 400, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
+func TestHandlerRequiresScope(t *testing.T) {
+	h := statement.NewHandler(createMockStatementService(t))
+
+	e := echo.New()
+	e.Use(authtest.WithScopes(auth.ScopeTransactionsWrite))
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := testServer.Client().Get(testServer.URL + "/wallets/1/statement")
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 403, res.StatusCode)
+}
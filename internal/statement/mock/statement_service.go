@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/statement (interfaces: StatementService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	statement "github.com/gokcelb/wallet-api/internal/statement"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStatementService is a mock of StatementService interface.
+type MockStatementService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementServiceMockRecorder
+}
+
+// MockStatementServiceMockRecorder is the mock recorder for MockStatementService.
+type MockStatementServiceMockRecorder struct {
+	mock *MockStatementService
+}
+
+// NewMockStatementService creates a new mock instance.
+func NewMockStatementService(ctrl *gomock.Controller) *MockStatementService {
+	mock := &MockStatementService{ctrl: ctrl}
+	mock.recorder = &MockStatementServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementService) EXPECT() *MockStatementServiceMockRecorder {
+	return m.recorder
+}
+
+// GenerateStatement mocks base method.
+func (m *MockStatementService) GenerateStatement(arg0 context.Context, arg1 string, arg2, arg3 time.Time) (*statement.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateStatement", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*statement.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateStatement indicates an expected call of GenerateStatement.
+func (mr *MockStatementServiceMockRecorder) GenerateStatement(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateStatement", reflect.TypeOf((*MockStatementService)(nil).GenerateStatement), arg0, arg1, arg2, arg3)
+}
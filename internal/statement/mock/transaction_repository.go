@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/statement (interfaces: TransactionRepository)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	transaction "github.com/gokcelb/wallet-api/internal/transaction"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransactionRepository is a mock of TransactionRepository interface.
+type MockTransactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionRepositoryMockRecorder
+}
+
+// MockTransactionRepositoryMockRecorder is the mock recorder for MockTransactionRepository.
+type MockTransactionRepositoryMockRecorder struct {
+	mock *MockTransactionRepository
+}
+
+// NewMockTransactionRepository creates a new mock instance.
+func NewMockTransactionRepository(ctrl *gomock.Controller) *MockTransactionRepository {
+	mock := &MockTransactionRepository{ctrl: ctrl}
+	mock.recorder = &MockTransactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReadByWalletIDBetween mocks base method.
+func (m *MockTransactionRepository) ReadByWalletIDBetween(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*transaction.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByWalletIDBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*transaction.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByWalletIDBetween indicates an expected call of ReadByWalletIDBetween.
+func (mr *MockTransactionRepositoryMockRecorder) ReadByWalletIDBetween(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByWalletIDBetween", reflect.TypeOf((*MockTransactionRepository)(nil).ReadByWalletIDBetween), arg0, arg1, arg2, arg3)
+}
+
+// ReadLastByWalletIDBefore mocks base method.
+func (m *MockTransactionRepository) ReadLastByWalletIDBefore(arg0 context.Context, arg1 string, arg2 time.Time) (*transaction.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLastByWalletIDBefore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*transaction.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLastByWalletIDBefore indicates an expected call of ReadLastByWalletIDBefore.
+func (mr *MockTransactionRepositoryMockRecorder) ReadLastByWalletIDBefore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLastByWalletIDBefore", reflect.TypeOf((*MockTransactionRepository)(nil).ReadLastByWalletIDBefore), arg0, arg1, arg2)
+}
@@ -0,0 +1,22 @@
+package statement
+
+import (
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
+type Statement struct {
+	WalletID       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	Totals         map[string]*TypeTotal
+	Transactions   []*transaction.Transaction
+}
+
+type TypeTotal struct {
+	Count  int
+	Amount float64
+}
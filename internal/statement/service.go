@@ -0,0 +1,118 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+var ErrInvalidPeriod = errors.New("statement period start must be before its end")
+
+type WalletRepository interface {
+	Read(ctx context.Context, id string) (*wallet.Wallet, error)
+}
+
+type TransactionRepository interface {
+	ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error)
+	ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error)
+}
+
+type Cache interface {
+	Get(key string) (*Statement, bool)
+	Set(key string, s *Statement)
+}
+
+type service struct {
+	wr    WalletRepository
+	tr    TransactionRepository
+	cache Cache
+	conf  config.Conf
+	now   func() time.Time
+}
+
+func NewService(wr WalletRepository, tr TransactionRepository, cache Cache, conf config.Conf) *service {
+	return &service{wr, tr, cache, conf, time.Now}
+}
+
+func (s *service) GenerateStatement(ctx context.Context, walletID string, from, to time.Time) (*Statement, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.wr.Read(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	closed := !to.After(s.now())
+	key := cacheKey(walletID, from, to)
+	if closed {
+		if st, ok := s.cache.Get(key); ok {
+			return st, nil
+		}
+	}
+
+	openingBalance, err := s.openingBalance(ctx, walletID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.tr.ReadByWalletIDBetween(ctx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	st := newStatement(walletID, from, to, openingBalance, txns)
+	if closed {
+		s.cache.Set(key, st)
+	}
+
+	return st, nil
+}
+
+func (s *service) openingBalance(ctx context.Context, walletID string, from time.Time) (float64, error) {
+	last, err := s.tr.ReadLastByWalletIDBefore(ctx, walletID, from)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		return s.conf.Wallet.InitialBalance, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return last.BalanceAfter, nil
+}
+
+func newStatement(walletID string, from, to time.Time, openingBalance float64, txns []*transaction.Transaction) *Statement {
+	st := &Statement{
+		WalletID:       walletID,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		ClosingBalance: openingBalance,
+		Totals:         map[string]*TypeTotal{},
+		Transactions:   txns,
+	}
+
+	for _, txn := range txns {
+		total, ok := st.Totals[txn.Type]
+		if !ok {
+			total = &TypeTotal{}
+			st.Totals[txn.Type] = total
+		}
+		total.Count++
+		total.Amount += txn.Amount
+	}
+
+	if len(txns) > 0 {
+		st.ClosingBalance = txns[len(txns)-1].BalanceAfter
+	}
+
+	return st
+}
+
+func cacheKey(walletID string, from, to time.Time) string {
+	return fmt.Sprintf("%s|%s|%s|%s", walletID, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339), from.Location())
+}
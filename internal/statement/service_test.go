@@ -0,0 +1,149 @@
+package statement_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/statement"
+	"github.com/gokcelb/wallet-api/internal/statement/mock"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockWalletRepository(t *testing.T) *mock.MockWalletRepository {
+	return mock.NewMockWalletRepository(gomock.NewController(t))
+}
+
+func createMockTransactionRepository(t *testing.T) *mock.MockTransactionRepository {
+	return mock.NewMockTransactionRepository(gomock.NewController(t))
+}
+
+func getConf() config.Conf {
+	conf, err := config.Read("../../.config/dev.json")
+	if err != nil {
+		panic(err)
+	}
+
+	return conf
+}
+
+func TestServiceGenerateStatement(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := statement.NewService(mockWalletRepository, mockTransactionRepository, statement.NewMemoryCache(10), getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockTxns := []*transaction.Transaction{
+		{ID: "2", WalletID: "1", Type: "deposit", Amount: 200, BalanceBefore: 100, BalanceAfter: 300},
+		{ID: "3", WalletID: "1", Type: "withdrawal", Amount: 50, BalanceBefore: 300, BalanceAfter: 250},
+		{ID: "4", WalletID: "1", Type: "deposit", Amount: 25, BalanceBefore: 250, BalanceAfter: 275},
+	}
+
+	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil).Times(2)
+
+	mockTransactionRepository.EXPECT().
+		ReadLastByWalletIDBefore(context.TODO(), "1", from).
+		Return(&transaction.Transaction{ID: "1", BalanceAfter: 100}, nil)
+
+	mockTransactionRepository.EXPECT().
+		ReadByWalletIDBetween(context.TODO(), "1", from, to).
+		Return(mockTxns, nil)
+
+	expectedStatement := &statement.Statement{
+		WalletID:       "1",
+		From:           from,
+		To:             to,
+		OpeningBalance: 100,
+		ClosingBalance: 275,
+		Totals: map[string]*statement.TypeTotal{
+			"deposit":    {Count: 2, Amount: 225},
+			"withdrawal": {Count: 1, Amount: 50},
+		},
+		Transactions: mockTxns,
+	}
+
+	st, err := s.GenerateStatement(context.TODO(), "1", from, to)
+
+	assert.Equal(t, expectedStatement, st)
+	assert.Nil(t, err)
+
+	// closed periods are served from the cache
+	st, err = s.GenerateStatement(context.TODO(), "1", from, to)
+
+	assert.Equal(t, expectedStatement, st)
+	assert.Nil(t, err)
+}
+
+func TestServiceGenerateStatementWithoutPriorTransactions(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	mockTransactionRepository := createMockTransactionRepository(t)
+	s := statement.NewService(mockWalletRepository, mockTransactionRepository, statement.NewMemoryCache(10), getConf())
+
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+
+	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(&wallet.Wallet{ID: "1"}, nil).Times(2)
+
+	mockTransactionRepository.EXPECT().
+		ReadLastByWalletIDBefore(context.TODO(), "1", from).
+		Return(nil, transaction.ErrTransactionNotFound).
+		Times(2)
+
+	// open periods are never cached
+	mockTransactionRepository.EXPECT().
+		ReadByWalletIDBetween(context.TODO(), "1", from, to).
+		Return([]*transaction.Transaction{}, nil).
+		Times(2)
+
+	for i := 0; i < 2; i++ {
+		st, err := s.GenerateStatement(context.TODO(), "1", from, to)
+
+		assert.Equal(t, getConf().Wallet.InitialBalance, st.OpeningBalance)
+		assert.Equal(t, getConf().Wallet.InitialBalance, st.ClosingBalance)
+		assert.Empty(t, st.Totals)
+		assert.Nil(t, err)
+	}
+}
+
+func TestServiceGenerateStatementWithInvalidParams(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	s := statement.NewService(mockWalletRepository, nil, statement.NewMemoryCache(10), getConf())
+
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		givenTo       time.Time
+		mockRepoErr   error
+		expectedError error
+	}{
+		{
+			desc:          "period end is before its start, return error",
+			givenTo:       from.AddDate(0, 0, -1),
+			expectedError: statement.ErrInvalidPeriod,
+		},
+		{
+			desc:          "wallet does not exist, return error",
+			givenTo:       from.AddDate(0, 1, 0),
+			mockRepoErr:   wallet.ErrWalletNotFound,
+			expectedError: wallet.ErrWalletNotFound,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.mockRepoErr != nil {
+				mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, tC.mockRepoErr)
+			}
+
+			st, err := s.GenerateStatement(context.TODO(), "1", from, tC.givenTo)
+
+			assert.Nil(t, st)
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package transaction
+
+import (
+	"sort"
+	"time"
+)
+
+// Aggregate groups transactions into buckets by period and type, sorted by
+// period then type, for repositories that can't aggregate in the database.
+func Aggregate(txns []*Transaction, interval string, loc *time.Location) []*Bucket {
+	type key struct {
+		period  time.Time
+		txnType string
+	}
+
+	byKey := make(map[key]*Bucket)
+	for _, txn := range txns {
+		k := key{PeriodStart(txn.CreatedAt, interval, loc), txn.Type}
+		b, ok := byKey[k]
+		if !ok {
+			b = &Bucket{Period: k.period, Type: k.txnType}
+			byKey[k] = b
+		}
+		b.Total += txn.Amount
+		b.Count++
+	}
+
+	buckets := []*Bucket{}
+	for _, b := range byKey {
+		b.Average = b.Total / float64(b.Count)
+		buckets = append(buckets, b)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if !buckets[i].Period.Equal(buckets[j].Period) {
+			return buckets[i].Period.Before(buckets[j].Period)
+		}
+		return buckets[i].Type < buckets[j].Type
+	})
+
+	return buckets
+}
+
+// PeriodStart truncates t to the start of its day, week or month in loc, with
+// weeks starting on monday like $dateTrunc.
+func PeriodStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	switch interval {
+	case IntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+
+	return day
+}
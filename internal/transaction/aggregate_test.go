@@ -0,0 +1,70 @@
+package transaction_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodStart(t *testing.T) {
+	istanbul := time.FixedZone("Istanbul", 3*60*60)
+	// 2022-09-14 is a wednesday, and already the 15th in Istanbul at 22:00 UTC.
+	givenTime := time.Date(2022, 9, 14, 22, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		interval string
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			desc:     "day",
+			interval: transaction.IntervalDay,
+			loc:      time.UTC,
+			expected: time.Date(2022, 9, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "day in another time zone",
+			interval: transaction.IntervalDay,
+			loc:      istanbul,
+			expected: time.Date(2022, 9, 15, 0, 0, 0, 0, istanbul),
+		},
+		{
+			desc:     "week starts on monday",
+			interval: transaction.IntervalWeek,
+			loc:      time.UTC,
+			expected: time.Date(2022, 9, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "month",
+			interval: transaction.IntervalMonth,
+			loc:      time.UTC,
+			expected: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.True(t, tC.expected.Equal(transaction.PeriodStart(givenTime, tC.interval, tC.loc)))
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	day := time.Date(2022, 9, 14, 0, 0, 0, 0, time.UTC)
+	givenTxns := []*transaction.Transaction{
+		{Type: "withdraw", Amount: 30, CreatedAt: day.Add(time.Hour)},
+		{Type: "deposit", Amount: 100, CreatedAt: day.Add(time.Hour)},
+		{Type: "deposit", Amount: 50, CreatedAt: day.Add(2 * time.Hour)},
+		{Type: "deposit", Amount: 10, CreatedAt: day.AddDate(0, 0, -1)},
+	}
+
+	buckets := transaction.Aggregate(givenTxns, transaction.IntervalDay, time.UTC)
+
+	assert.Equal(t, []*transaction.Bucket{
+		{Period: day.AddDate(0, 0, -1), Type: "deposit", Total: 10, Count: 1, Average: 10},
+		{Period: day, Type: "deposit", Total: 150, Count: 2, Average: 75},
+		{Period: day, Type: "withdraw", Total: 30, Count: 1, Average: 30},
+	}, buckets)
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"errors"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+var (
+	ErrInvalidTimeZone = errors.New("tz is not a valid time zone")
+	ErrInvalidDate     = errors.New("from and to must be in YYYY-MM-DD or RFC 3339 format")
+)
+
+// ParseLocation returns the time zone of a tz query, which is UTC when the
+// query is empty.
+func ParseLocation(query string) (*time.Location, error) {
+	if query == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(query)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	return loc, nil
+}
+
+// ParseFrom parses the start of a range given as a plain date, which starts
+// at midnight in loc, or as an RFC 3339 time.
+func ParseFrom(query string, loc *time.Location) (time.Time, error) {
+	t, _, err := parseDate(query, loc)
+	return t, err
+}
+
+// ParseTo parses the exclusive end of a range given as a plain date, which
+// includes the whole day in loc and so ends at the next midnight, or as an
+// RFC 3339 time.
+func ParseTo(query string, loc *time.Location) (time.Time, error) {
+	t, isDate, err := parseDate(query, loc)
+	if err == nil && isDate {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, err
+}
+
+func parseDate(query string, loc *time.Location) (time.Time, bool, error) {
+	if date, err := time.ParseInLocation(DateLayout, query, loc); err == nil {
+		return date, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, query)
+	if err != nil {
+		return time.Time{}, false, ErrInvalidDate
+	}
+
+	return t.In(loc), false, nil
+}
@@ -0,0 +1,80 @@
+package transaction_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocation(t *testing.T) {
+	loc, err := transaction.ParseLocation("")
+
+	assert.Equal(t, time.UTC, loc)
+	assert.Nil(t, err)
+
+	loc, err = transaction.ParseLocation("Europe/Istanbul")
+
+	assert.Equal(t, "Europe/Istanbul", loc.String())
+	assert.Nil(t, err)
+
+	loc, err = transaction.ParseLocation("Mars/Olympus")
+
+	assert.Nil(t, loc)
+	assert.ErrorIs(t, err, transaction.ErrInvalidTimeZone)
+}
+
+func TestParseFromAndTo(t *testing.T) {
+	istanbul := time.FixedZone("Istanbul", 3*60*60)
+
+	testCases := []struct {
+		desc         string
+		query        string
+		loc          *time.Location
+		expectedFrom time.Time
+		expectedTo   time.Time
+		expectedErr  error
+	}{
+		{
+			desc:         "plain date",
+			query:        "2022-03-15",
+			loc:          time.UTC,
+			expectedFrom: time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC),
+			expectedTo:   time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:         "plain date in another time zone",
+			query:        "2022-03-15",
+			loc:          istanbul,
+			expectedFrom: time.Date(2022, 3, 15, 0, 0, 0, 0, istanbul),
+			expectedTo:   time.Date(2022, 3, 16, 0, 0, 0, 0, istanbul),
+		},
+		{
+			desc:         "RFC 3339 time",
+			query:        "2022-03-15T10:30:00Z",
+			loc:          istanbul,
+			expectedFrom: time.Date(2022, 3, 15, 13, 30, 0, 0, istanbul),
+			expectedTo:   time.Date(2022, 3, 15, 13, 30, 0, 0, istanbul),
+		},
+		{
+			desc:        "invalid date",
+			query:       "yesterday",
+			loc:         time.UTC,
+			expectedErr: transaction.ErrInvalidDate,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			from, err := transaction.ParseFrom(tC.query, tC.loc)
+
+			assert.True(t, tC.expectedFrom.Equal(from), "from is %s", from)
+			assert.ErrorIs(t, err, tC.expectedErr)
+
+			to, err := transaction.ParseTo(tC.query, tC.loc)
+
+			assert.True(t, tC.expectedTo.Equal(to), "to is %s", to)
+			assert.ErrorIs(t, err, tC.expectedErr)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/gokcelb/wallet-api/internal/auth"
 	"github.com/labstack/echo/v4"
 )
 
@@ -20,8 +21,11 @@ func NewHandler(ts TransactionService) *handler {
 	return &handler{ts}
 }
 
+// RegisterRoutes registers the lookup by transaction id for staff only, since
+// it is not scoped to a wallet; end users list their transactions through
+// their wallet.
 func (h *handler) RegisterRoutes(e *echo.Echo) {
-	e.GET("/transactions/:id", h.GetTransaction)
+	e.GET("/transactions/:id", h.GetTransaction, auth.RequireScopes(auth.ScopeTransactionsRead, auth.ScopeAnyWallet))
 }
 
 func (h *handler) GetTransaction(c echo.Context) error {
@@ -4,13 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/auth"
 	"github.com/gokcelb/wallet-api/internal/transaction"
 	"github.com/gokcelb/wallet-api/internal/transaction/mock"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,11 +26,48 @@ func createMockTransactionService(t *testing.T) *mock.MockTransactionService {
 	return mock.NewMockTransactionService(gomock.NewController(t))
 }
 
+func getJWTConf() config.JWTConf {
+	conf, err := config.Read("../../.config/dev.json")
+	if err != nil {
+		panic(err)
+	}
+
+	return conf.JWT
+}
+
+func newTokenService() *auth.TokenService {
+	conf := getJWTConf()
+	ks, err := auth.NewKeySet(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return auth.NewTokenService(conf, ks, auth.NewMemoryDenylist())
+}
+
+func useJWT(e *echo.Echo) {
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		ParseTokenFunc: newTokenService().Decode,
+	}))
+}
+
+func get(url, role string) (*http.Response, error) {
+	token, _, err := newTokenService().Create("1", role)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return http.DefaultClient.Do(req)
+}
+
 func TestHandlerGetTransaction(t *testing.T) {
 	mockTransactionService := createMockTransactionService(t)
 	h := transaction.NewHandler(mockTransactionService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -72,7 +113,7 @@ func TestHandlerGetTransaction(t *testing.T) {
 				GetTransaction(gomock.Any(), tC.givenID).
 				Return(tC.mockTxnSvcTxn, tC.mockTxnSvcErr)
 
-			res, err := testServer.Client().Get(fmt.Sprintf("%s/transactions/%s", testServer.URL, tC.givenID))
+			res, err := get(fmt.Sprintf("%s/transactions/%s", testServer.URL, tC.givenID), auth.RoleSupport)
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
@@ -86,3 +127,25 @@ func TestHandlerGetTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerGetTransactionAsEndUser(t *testing.T) {
+	h := transaction.NewHandler(createMockTransactionService(t))
+
+	e := echo.New()
+	useJWT(e)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	res, err := get(fmt.Sprintf("%s/transactions/1", testServer.URL), auth.RoleUser)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(httpErr{auth.ErrInsufficientScope.Error()})
+
+	assert.Equal(t, 403, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Memory keeps transactions in the process, for local development and tests.
+// It sorts and pages them the same way as the mongo backend.
+type Memory struct {
+	mu   sync.RWMutex
+	txns []*transaction.Transaction
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Create(ctx context.Context, txn *transaction.Transaction) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *txn
+	stored.ID = primitive.NewObjectID().Hex()
+	stored.CreatedAt = time.Now()
+	m.txns = append(m.txns, &stored)
+
+	return stored.ID, nil
+}
+
+func (m *Memory) Read(ctx context.Context, id string) (*transaction.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, txn := range m.txns {
+		if txn.ID == id {
+			found := *txn
+			return &found, nil
+		}
+	}
+
+	return nil, transaction.ErrTransactionNotFound
+}
+
+func (m *Memory) ReadByWalletID(ctx context.Context, walletID string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	return m.ReadByWalletIDFilterByType(ctx, walletID, "", pageNo, pageSize)
+}
+
+// ReadByWalletIDFilterByType returns the newest transactions first, reading
+// every type when typeFilter is empty.
+func (m *Memory) ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	txns := m.filter(func(txn *transaction.Transaction) bool {
+		return txn.WalletID == walletID && (typeFilter == "" || txn.Type == typeFilter)
+	})
+	sortNewestFirst(txns)
+
+	start := pageNo * pageSize
+	if start >= len(txns) {
+		return []*transaction.Transaction{}, nil
+	}
+
+	end := start + pageSize
+	if end > len(txns) {
+		end = len(txns)
+	}
+
+	return txns[start:end], nil
+}
+
+func (m *Memory) CountByWalletID(ctx context.Context, walletID string, limit int) (int64, error) {
+	return m.CountByWalletIDFilterByType(ctx, walletID, "", limit)
+}
+
+func (m *Memory) CountByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
+	txns := m.filter(func(txn *transaction.Transaction) bool {
+		return txn.WalletID == walletID && (typeFilter == "" || txn.Type == typeFilter)
+	})
+
+	count := int64(len(txns))
+	if limit > 0 && count > int64(limit) {
+		count = int64(limit)
+	}
+
+	return count, nil
+}
+
+func (m *Memory) ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error) {
+	txns := m.between(walletID, from, to)
+	sortOldestFirst(txns)
+
+	return txns, nil
+}
+
+func (m *Memory) StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*transaction.Transaction) error) error {
+	txns, _ := m.ReadByWalletIDBetween(ctx, walletID, from, to)
+	for _, txn := range txns {
+		if err := fn(txn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error) {
+	txns := m.filter(func(txn *transaction.Transaction) bool {
+		return txn.WalletID == walletID && txn.CreatedAt.Before(before)
+	})
+	if len(txns) == 0 {
+		return nil, transaction.ErrTransactionNotFound
+	}
+	sortNewestFirst(txns)
+
+	return txns[0], nil
+}
+
+func (m *Memory) AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*transaction.Bucket, error) {
+	return transaction.Aggregate(m.between(walletID, from, to), interval, loc), nil
+}
+
+func (m *Memory) DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	walletIDs := []string{}
+	for _, txn := range m.txns {
+		if !seen[txn.WalletID] && !txn.CreatedAt.Before(from) && txn.CreatedAt.Before(to) {
+			seen[txn.WalletID] = true
+			walletIDs = append(walletIDs, txn.WalletID)
+		}
+	}
+
+	return walletIDs, nil
+}
+
+func (m *Memory) ReadBefore(ctx context.Context, before time.Time, limit int) ([]*transaction.Transaction, error) {
+	txns := m.filter(func(txn *transaction.Transaction) bool {
+		return txn.CreatedAt.Before(before)
+	})
+	sortOldestFirst(txns)
+
+	if len(txns) > limit {
+		txns = txns[:limit]
+	}
+
+	return txns, nil
+}
+
+func (m *Memory) DeleteByIDs(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make(map[string]bool)
+	for _, id := range ids {
+		deleted[id] = true
+	}
+
+	kept := []*transaction.Transaction{}
+	for _, txn := range m.txns {
+		if !deleted[txn.ID] {
+			kept = append(kept, txn)
+		}
+	}
+	m.txns = kept
+
+	return nil
+}
+
+// Insert keeps the ids and creation times of the transactions, skipping the
+// ones that are there already.
+func (m *Memory) Insert(ctx context.Context, txns []*transaction.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := make(map[string]bool)
+	for _, txn := range m.txns {
+		existing[txn.ID] = true
+	}
+
+	for _, txn := range txns {
+		if !existing[txn.ID] {
+			stored := *txn
+			m.txns = append(m.txns, &stored)
+			existing[txn.ID] = true
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) between(walletID string, from, to time.Time) []*transaction.Transaction {
+	return m.filter(func(txn *transaction.Transaction) bool {
+		return txn.WalletID == walletID && !txn.CreatedAt.Before(from) && txn.CreatedAt.Before(to)
+	})
+}
+
+// filter returns copies of the matching transactions.
+func (m *Memory) filter(match func(*transaction.Transaction) bool) []*transaction.Transaction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	txns := []*transaction.Transaction{}
+	for _, txn := range m.txns {
+		if match(txn) {
+			found := *txn
+			txns = append(txns, &found)
+		}
+	}
+
+	return txns
+}
+
+func sortNewestFirst(txns []*transaction.Transaction) {
+	sort.SliceStable(txns, func(i, j int) bool {
+		return before(txns[j], txns[i])
+	})
+}
+
+func sortOldestFirst(txns []*transaction.Transaction) {
+	sort.SliceStable(txns, func(i, j int) bool {
+		return before(txns[i], txns[j])
+	})
+}
+
+// before orders transactions by creation time, then by id like the mongo
+// backend does.
+func before(a, b *transaction.Transaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	return a.ID < b.ID
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/transaction/memory"
+	"github.com/gokcelb/wallet-api/internal/transaction/transactiontest"
+)
+
+func TestMemory(t *testing.T) {
+	transactiontest.TestRepository(t, func(t *testing.T) transaction.TransactionRepository {
+		return memory.NewMemory()
+	})
+}
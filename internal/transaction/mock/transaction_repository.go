@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	transaction "github.com/gokcelb/wallet-api/internal/transaction"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,51 @@ func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecor
 	return m.recorder
 }
 
+// AggregateByWalletID mocks base method.
+func (m *MockTransactionRepository) AggregateByWalletID(arg0 context.Context, arg1 string, arg2, arg3 time.Time, arg4 string, arg5 *time.Location) ([]*transaction.Bucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateByWalletID", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*transaction.Bucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AggregateByWalletID indicates an expected call of AggregateByWalletID.
+func (mr *MockTransactionRepositoryMockRecorder) AggregateByWalletID(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateByWalletID", reflect.TypeOf((*MockTransactionRepository)(nil).AggregateByWalletID), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// CountByWalletID mocks base method.
+func (m *MockTransactionRepository) CountByWalletID(arg0 context.Context, arg1 string, arg2 int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByWalletID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByWalletID indicates an expected call of CountByWalletID.
+func (mr *MockTransactionRepositoryMockRecorder) CountByWalletID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByWalletID", reflect.TypeOf((*MockTransactionRepository)(nil).CountByWalletID), arg0, arg1, arg2)
+}
+
+// CountByWalletIDFilterByType mocks base method.
+func (m *MockTransactionRepository) CountByWalletIDFilterByType(arg0 context.Context, arg1, arg2 string, arg3 int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByWalletIDFilterByType", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByWalletIDFilterByType indicates an expected call of CountByWalletIDFilterByType.
+func (mr *MockTransactionRepositoryMockRecorder) CountByWalletIDFilterByType(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByWalletIDFilterByType", reflect.TypeOf((*MockTransactionRepository)(nil).CountByWalletIDFilterByType), arg0, arg1, arg2, arg3)
+}
+
 // Create mocks base method.
 func (m *MockTransactionRepository) Create(arg0 context.Context, arg1 *transaction.Transaction) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +96,21 @@ func (mr *MockTransactionRepositoryMockRecorder) Create(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransactionRepository)(nil).Create), arg0, arg1)
 }
 
+// DistinctWalletIDsBetween mocks base method.
+func (m *MockTransactionRepository) DistinctWalletIDsBetween(arg0 context.Context, arg1, arg2 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DistinctWalletIDsBetween", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DistinctWalletIDsBetween indicates an expected call of DistinctWalletIDsBetween.
+func (mr *MockTransactionRepositoryMockRecorder) DistinctWalletIDsBetween(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistinctWalletIDsBetween", reflect.TypeOf((*MockTransactionRepository)(nil).DistinctWalletIDsBetween), arg0, arg1, arg2)
+}
+
 // Read mocks base method.
 func (m *MockTransactionRepository) Read(arg0 context.Context, arg1 string) (*transaction.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +141,21 @@ func (mr *MockTransactionRepositoryMockRecorder) ReadByWalletID(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByWalletID", reflect.TypeOf((*MockTransactionRepository)(nil).ReadByWalletID), arg0, arg1, arg2, arg3)
 }
 
+// ReadByWalletIDBetween mocks base method.
+func (m *MockTransactionRepository) ReadByWalletIDBetween(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*transaction.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByWalletIDBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*transaction.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByWalletIDBetween indicates an expected call of ReadByWalletIDBetween.
+func (mr *MockTransactionRepositoryMockRecorder) ReadByWalletIDBetween(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByWalletIDBetween", reflect.TypeOf((*MockTransactionRepository)(nil).ReadByWalletIDBetween), arg0, arg1, arg2, arg3)
+}
+
 // ReadByWalletIDFilterByType mocks base method.
 func (m *MockTransactionRepository) ReadByWalletIDFilterByType(arg0 context.Context, arg1, arg2 string, arg3, arg4 int) ([]*transaction.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +170,32 @@ func (mr *MockTransactionRepositoryMockRecorder) ReadByWalletIDFilterByType(arg0
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByWalletIDFilterByType", reflect.TypeOf((*MockTransactionRepository)(nil).ReadByWalletIDFilterByType), arg0, arg1, arg2, arg3, arg4)
 }
+
+// ReadLastByWalletIDBefore mocks base method.
+func (m *MockTransactionRepository) ReadLastByWalletIDBefore(arg0 context.Context, arg1 string, arg2 time.Time) (*transaction.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLastByWalletIDBefore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*transaction.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLastByWalletIDBefore indicates an expected call of ReadLastByWalletIDBefore.
+func (mr *MockTransactionRepositoryMockRecorder) ReadLastByWalletIDBefore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLastByWalletIDBefore", reflect.TypeOf((*MockTransactionRepository)(nil).ReadLastByWalletIDBefore), arg0, arg1, arg2)
+}
+
+// StreamByWalletIDBetween mocks base method.
+func (m *MockTransactionRepository) StreamByWalletIDBetween(arg0 context.Context, arg1 string, arg2, arg3 time.Time, arg4 func(*transaction.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamByWalletIDBetween", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamByWalletIDBetween indicates an expected call of StreamByWalletIDBetween.
+func (mr *MockTransactionRepositoryMockRecorder) StreamByWalletIDBetween(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamByWalletIDBetween", reflect.TypeOf((*MockTransactionRepository)(nil).StreamByWalletIDBetween), arg0, arg1, arg2, arg3, arg4)
+}
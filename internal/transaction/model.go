@@ -3,9 +3,25 @@ package transaction
 import "time"
 
 type Transaction struct {
-	ID        string
-	WalletID  string
-	Type      string
-	Amount    float64
-	CreatedAt time.Time
+	ID            string
+	WalletID      string
+	Type          string
+	Amount        float64
+	BalanceBefore float64
+	BalanceAfter  float64
+	CreatedAt     time.Time
+}
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+type Bucket struct {
+	Period  time.Time
+	Type    string
+	Total   float64
+	Count   int64
+	Average float64
 }
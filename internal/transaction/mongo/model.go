@@ -1,15 +1,38 @@
 package mongo
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// schemaVersion is the version of the transaction documents we write.
+// Documents written before there were versions have none, and the same fields
+// as version 1.
+const schemaVersion = 1
+
+// ErrUnsupportedSchemaVersion is returned for documents written by a newer
+// version of the API, until this one is upgraded.
+var ErrUnsupportedSchemaVersion = errors.New("transaction document has a newer schema version")
+
 type mongoTransaction struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	WalletID  string             `bson:"wallet_id"`
-	Type      string             `bson:"type"`
-	Amount    float64            `bson:"amount"`
-	CreatedAt time.Time          `bson:"created_at"`
+	ID            primitive.ObjectID `bson:"_id"`
+	WalletID      string             `bson:"wallet_id"`
+	Type          string             `bson:"type"`
+	Amount        float64            `bson:"amount"`
+	BalanceBefore float64            `bson:"balance_before"`
+	BalanceAfter  float64            `bson:"balance_after"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	SchemaVersion int                `bson:"schema_version"`
+}
+
+type mongoBucket struct {
+	ID struct {
+		Period time.Time `bson:"period"`
+		Type   string    `bson:"type"`
+	} `bson:"_id"`
+	Total   float64 `bson:"total"`
+	Count   int64   `bson:"count"`
+	Average float64 `bson:"average"`
 }
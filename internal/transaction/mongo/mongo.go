@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gokcelb/wallet-api/internal/transaction"
@@ -37,8 +38,7 @@ func (m *Mongo) Create(ctx context.Context, txn *transaction.Transaction) (strin
 func (m *Mongo) Read(ctx context.Context, id string) (*transaction.Transaction, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return nil, transaction.ErrTransactionNotFound
 	}
 
 	var mongoTxn mongoTransaction
@@ -49,11 +49,11 @@ func (m *Mongo) Read(ctx context.Context, id string) (*transaction.Transaction,
 		return nil, err
 	}
 
-	return newTransactionFromMongoTransaction(&mongoTxn), nil
+	return newTransactionFromMongoTransaction(&mongoTxn)
 }
 
 func (m *Mongo) ReadByWalletID(ctx context.Context, walletID string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
-	opts := options.Find().SetSkip(int64(pageNo * pageSize)).SetLimit(int64(pageSize))
+	opts := newPaginationOptions(pageNo, pageSize)
 	filter := bson.D{bson.E{Key: "wallet_id", Value: walletID}}
 
 	cursor, err := m.collection.Find(ctx, filter, opts)
@@ -70,17 +70,212 @@ func (m *Mongo) ReadByWalletID(ctx context.Context, walletID string, pageNo, pag
 
 	txns := []*transaction.Transaction{}
 	for _, mongoTxn := range mongoTxns {
-		txns = append(txns, newTransactionFromMongoTransaction(&mongoTxn))
+		txn, err := newTransactionFromMongoTransaction(&mongoTxn)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
 	}
 
 	return txns, nil
 }
 
 func (m *Mongo) ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
-	opts := options.Find().SetSkip(int64(pageNo * pageSize)).SetLimit(int64(pageSize))
+	opts := newPaginationOptions(pageNo, pageSize)
+	filter := bson.D{bson.E{Key: "wallet_id", Value: walletID}, bson.E{Key: "type", Value: typeFilter}}
+
+	cursor, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoTxns []mongoTransaction
+	if err = cursor.All(ctx, &mongoTxns); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	txns := []*transaction.Transaction{}
+	for _, mongoTxn := range mongoTxns {
+		txn, err := newTransactionFromMongoTransaction(&mongoTxn)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, nil
+}
+
+func (m *Mongo) CountByWalletID(ctx context.Context, walletID string, limit int) (int64, error) {
+	filter := bson.D{bson.E{Key: "wallet_id", Value: walletID}}
+
+	count, err := m.collection.CountDocuments(ctx, filter, newCountOptions(limit))
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (m *Mongo) CountByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
 	filter := bson.D{bson.E{Key: "wallet_id", Value: walletID}, bson.E{Key: "type", Value: typeFilter}}
 
+	count, err := m.collection.CountDocuments(ctx, filter, newCountOptions(limit))
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (m *Mongo) ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error) {
+	txns := []*transaction.Transaction{}
+	err := m.StreamByWalletIDBetween(ctx, walletID, from, to, func(txn *transaction.Transaction) error {
+		txns = append(txns, txn)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txns, nil
+}
+
+func (m *Mongo) StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*transaction.Transaction) error) error {
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}})
+	filter := bson.D{
+		bson.E{Key: "wallet_id", Value: walletID},
+		bson.E{Key: "created_at", Value: bson.M{"$gte": from, "$lt": to}},
+	}
+
 	cursor, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mongoTxn mongoTransaction
+		if err = cursor.Decode(&mongoTxn); err != nil {
+			log.Error(err)
+			return err
+		}
+
+		txn, err := newTransactionFromMongoTransaction(&mongoTxn)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(txn); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (m *Mongo) ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error) {
+	opts := options.FindOne().SetSort(bson.D{bson.E{Key: "created_at", Value: -1}, bson.E{Key: "_id", Value: -1}})
+	filter := bson.D{
+		bson.E{Key: "wallet_id", Value: walletID},
+		bson.E{Key: "created_at", Value: bson.M{"$lt": before}},
+	}
+
+	var mongoTxn mongoTransaction
+	err := m.collection.FindOne(ctx, filter, opts).Decode(&mongoTxn)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, transaction.ErrTransactionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return newTransactionFromMongoTransaction(&mongoTxn)
+}
+
+// AggregateByWalletID relies on $dateTrunc, which needs MongoDB 5.0 or later.
+func (m *Mongo) AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*transaction.Bucket, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{bson.E{Key: "$match", Value: bson.M{
+			"wallet_id":  walletID,
+			"created_at": bson.M{"$gte": from, "$lt": to},
+		}}},
+		bson.D{bson.E{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"period": bson.M{"$dateTrunc": bson.M{
+					"date":        "$created_at",
+					"unit":        interval,
+					"timezone":    loc.String(),
+					"startOfWeek": "monday",
+				}},
+				"type": "$type",
+			},
+			"total":   bson.M{"$sum": "$amount"},
+			"count":   bson.M{"$sum": 1},
+			"average": bson.M{"$avg": "$amount"},
+		}}},
+		bson.D{bson.E{Key: "$sort", Value: bson.D{
+			bson.E{Key: "_id.period", Value: 1},
+			bson.E{Key: "_id.type", Value: 1},
+		}}},
+	}
+
+	cursor, err := m.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var mongoBuckets []mongoBucket
+	if err = cursor.All(ctx, &mongoBuckets); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	buckets := []*transaction.Bucket{}
+	for _, mongoBucket := range mongoBuckets {
+		buckets = append(buckets, &transaction.Bucket{
+			Period:  mongoBucket.ID.Period.In(loc),
+			Type:    mongoBucket.ID.Type,
+			Total:   mongoBucket.Total,
+			Count:   mongoBucket.Count,
+			Average: mongoBucket.Average,
+		})
+	}
+
+	return buckets, nil
+}
+
+func (m *Mongo) DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error) {
+	filter := bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}
+
+	values, err := m.collection.Distinct(ctx, "wallet_id", filter)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	walletIDs := []string{}
+	for _, value := range values {
+		if walletID, ok := value.(string); ok {
+			walletIDs = append(walletIDs, walletID)
+		}
+	}
+
+	return walletIDs, nil
+}
+
+// ReadBefore returns the oldest transactions created before the given time.
+func (m *Mongo) ReadBefore(ctx context.Context, before time.Time, limit int) ([]*transaction.Transaction, error) {
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.collection.Find(ctx, bson.M{"created_at": bson.M{"$lt": before}}, opts)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -94,28 +289,119 @@ func (m *Mongo) ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFi
 
 	txns := []*transaction.Transaction{}
 	for _, mongoTxn := range mongoTxns {
-		txns = append(txns, newTransactionFromMongoTransaction(&mongoTxn))
+		txn, err := newTransactionFromMongoTransaction(&mongoTxn)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
 	}
 
 	return txns, nil
 }
 
+func (m *Mongo) DeleteByIDs(ctx context.Context, ids []string) error {
+	objectIDs := bson.A{}
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	_, err := m.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+// Insert keeps the ids and creation times of the transactions, skipping the
+// ones that are there already so that it can be retried.
+func (m *Mongo) Insert(ctx context.Context, txns []*transaction.Transaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+
+	docs := []interface{}{}
+	for _, txn := range txns {
+		objectID, err := primitive.ObjectIDFromHex(txn.ID)
+		if err != nil {
+			return err
+		}
+
+		mongoTxn := newMongoTransactionFromTransaction(txn)
+		mongoTxn.ID = objectID
+		mongoTxn.CreatedAt = txn.CreatedAt
+		docs = append(docs, mongoTxn)
+	}
+
+	_, err := m.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !onlyDuplicateKeyErrors(err) {
+		log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func onlyDuplicateKeyErrors(err error) bool {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil {
+		return false
+	}
+
+	for _, we := range bwe.WriteErrors {
+		if we.Code != 11000 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newPaginationOptions(pageNo, pageSize int) *options.FindOptions {
+	return options.Find().
+		SetSort(bson.D{bson.E{Key: "created_at", Value: -1}, bson.E{Key: "_id", Value: -1}}).
+		SetSkip(int64(pageNo * pageSize)).
+		SetLimit(int64(pageSize))
+}
+
+func newCountOptions(limit int) *options.CountOptions {
+	opts := options.Count()
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	return opts
+}
+
 func newMongoTransactionFromTransaction(txn *transaction.Transaction) *mongoTransaction {
 	return &mongoTransaction{
-		ID:        primitive.NewObjectID(),
-		WalletID:  txn.WalletID,
-		Type:      txn.Type,
-		Amount:    txn.Amount,
-		CreatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		WalletID:      txn.WalletID,
+		Type:          txn.Type,
+		Amount:        txn.Amount,
+		BalanceBefore: txn.BalanceBefore,
+		BalanceAfter:  txn.BalanceAfter,
+		CreatedAt:     time.Now(),
+		SchemaVersion: schemaVersion,
 	}
 }
 
-func newTransactionFromMongoTransaction(mongoTxn *mongoTransaction) *transaction.Transaction {
-	return &transaction.Transaction{
-		ID:        mongoTxn.ID.Hex(),
-		WalletID:  mongoTxn.WalletID,
-		Type:      mongoTxn.Type,
-		Amount:    mongoTxn.Amount,
-		CreatedAt: mongoTxn.CreatedAt,
+// newTransactionFromMongoTransaction rejects documents of a newer schema,
+// which this version may not read correctly.
+func newTransactionFromMongoTransaction(mongoTxn *mongoTransaction) (*transaction.Transaction, error) {
+	if mongoTxn.SchemaVersion > schemaVersion {
+		return nil, fmt.Errorf("%w: transaction %s has version %d", ErrUnsupportedSchemaVersion, mongoTxn.ID.Hex(), mongoTxn.SchemaVersion)
 	}
+
+	return &transaction.Transaction{
+		ID:            mongoTxn.ID.Hex(),
+		WalletID:      mongoTxn.WalletID,
+		Type:          mongoTxn.Type,
+		Amount:        mongoTxn.Amount,
+		BalanceBefore: mongoTxn.BalanceBefore,
+		BalanceAfter:  mongoTxn.BalanceAfter,
+		CreatedAt:     mongoTxn.CreatedAt,
+	}, nil
 }
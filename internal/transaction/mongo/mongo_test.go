@@ -0,0 +1,77 @@
+package mongo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/transaction/mongo"
+	"github.com/gokcelb/wallet-api/internal/transaction/transactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMongo needs a server at MONGO_TEST_URI and is skipped without one.
+func TestMongo(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	transactiontest.TestRepository(t, func(t *testing.T) transaction.TransactionRepository {
+		collection := client.Database("wallet-api-test").Collection(primitive.NewObjectID().Hex())
+		t.Cleanup(func() { collection.Drop(context.TODO()) })
+
+		m := mongo.NewMongo(collection)
+		require.NoError(t, m.CreateIndexes(context.TODO()))
+
+		return m
+	})
+}
+
+func TestMongoNewerSchemaVersion(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	collection := client.Database("wallet-api-test").Collection(primitive.NewObjectID().Hex())
+	defer collection.Drop(context.TODO())
+
+	id := primitive.NewObjectID()
+	_, err = collection.InsertOne(context.TODO(), bson.M{
+		"_id":            id,
+		"wallet_id":      "1",
+		"type":           "deposit",
+		"amount":         10,
+		"created_at":     time.Now(),
+		"schema_version": 2,
+	})
+	require.NoError(t, err)
+
+	m := mongo.NewMongo(collection)
+
+	txn, err := m.Read(context.TODO(), id.Hex())
+
+	assert.Nil(t, txn)
+	assert.ErrorIs(t, err, mongo.ErrUnsupportedSchemaVersion)
+
+	txns, err := m.ReadByWalletID(context.TODO(), "1", 0, 10)
+
+	assert.Nil(t, txns)
+	assert.ErrorIs(t, err, mongo.ErrUnsupportedSchemaVersion)
+}
@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var ErrTransactionNotFound = errors.New("no transaction with the given id exists")
@@ -12,6 +13,13 @@ type TransactionRepository interface {
 	Read(ctx context.Context, id string) (*Transaction, error)
 	ReadByWalletID(ctx context.Context, walletID string, pageNo, pageSize int) ([]*Transaction, error)
 	ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*Transaction, error)
+	CountByWalletID(ctx context.Context, walletID string, limit int) (int64, error)
+	CountByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, limit int) (int64, error)
+	ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*Transaction, error)
+	ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*Transaction, error)
+	StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*Transaction) error) error
+	AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*Bucket, error)
+	DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error)
 }
 
 type service struct {
@@ -22,10 +30,6 @@ func NewService(tr TransactionRepository) *service {
 	return &service{tr}
 }
 
-func (s *service) CreateTransaction(ctx context.Context, txn *Transaction) (string, error) {
-	return s.tr.Create(ctx, txn)
-}
-
 func (s *service) GetTransaction(ctx context.Context, id string) (*Transaction, error) {
 	return s.tr.Read(ctx, id)
 }
@@ -37,3 +41,11 @@ func (s *service) GetTransactionsByWalletID(ctx context.Context, walletID, typeF
 
 	return s.tr.ReadByWalletIDFilterByType(ctx, walletID, typeFilter, pageNo, pageSize)
 }
+
+func (s *service) CountTransactionsByWalletID(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
+	if typeFilter == "" {
+		return s.tr.CountByWalletID(ctx, walletID, limit)
+	}
+
+	return s.tr.CountByWalletIDFilterByType(ctx, walletID, typeFilter, limit)
+}
@@ -15,25 +15,6 @@ func createMockTransactionRepository(t *testing.T) *mock.MockTransactionReposito
 	return mock.NewMockTransactionRepository(gomock.NewController(t))
 }
 
-func TestServiceCreateTransaction(t *testing.T) {
-	mockRepository := createMockTransactionRepository(t)
-	s := transaction.NewService(mockRepository)
-
-	givenTxn := &transaction.Transaction{
-		WalletID: "1",
-		Type:     "deposit",
-		Amount:   200,
-	}
-	mockRepoTxnID := "1"
-
-	mockRepository.EXPECT().Create(context.TODO(), givenTxn).Return(mockRepoTxnID, nil)
-
-	id, err := s.CreateTransaction(context.TODO(), givenTxn)
-
-	assert.Equal(t, mockRepoTxnID, id)
-	assert.Nil(t, err)
-}
-
 func TestServiceGetTransaction(t *testing.T) {
 	mockRepository := createMockTransactionRepository(t)
 	s := transaction.NewService(mockRepository)
@@ -116,3 +97,21 @@ func TestServiceGetTransactionsWithType(t *testing.T) {
 	assert.Equal(t, mockTxns, txns)
 	assert.Nil(t, err)
 }
+
+func TestServiceCountTransactions(t *testing.T) {
+	mockRepository := createMockTransactionRepository(t)
+	s := transaction.NewService(mockRepository)
+
+	mockRepository.EXPECT().CountByWalletID(context.TODO(), "1", 0).Return(int64(3), nil)
+	mockRepository.EXPECT().CountByWalletIDFilterByType(context.TODO(), "1", "deposit", 100).Return(int64(2), nil)
+
+	count, err := s.CountTransactionsByWalletID(context.TODO(), "1", "", 0)
+
+	assert.Equal(t, int64(3), count)
+	assert.Nil(t, err)
+
+	count, err = s.CountTransactionsByWalletID(context.TODO(), "1", "deposit", 100)
+
+	assert.Equal(t, int64(2), count)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,256 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const columns = "id, wallet_id, type, amount, balance_before, balance_after, created_at"
+
+// SQLite keeps created_at as unix nanoseconds so that it sorts and compares
+// exactly.
+type SQLite struct {
+	db    *sql.DB
+	table string
+}
+
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db, "transactions"}
+}
+
+// NewSQLiteArchive keeps the archived transactions, in a table of their own.
+func NewSQLiteArchive(db *sql.DB) *SQLite {
+	return &SQLite{db, "transactions_archive"}
+}
+
+func (s *SQLite) Create(ctx context.Context, txn *transaction.Transaction) (string, error) {
+	id := primitive.NewObjectID().Hex()
+	_, err := s.db.ExecContext(ctx, "INSERT INTO "+s.table+" ("+columns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		id, txn.WalletID, txn.Type, txn.Amount, txn.BalanceBefore, txn.BalanceAfter, time.Now().UnixNano())
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) Read(ctx context.Context, id string) (*transaction.Transaction, error) {
+	txns, err := s.read(ctx, "WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txns) == 0 {
+		return nil, transaction.ErrTransactionNotFound
+	}
+
+	return txns[0], nil
+}
+
+func (s *SQLite) ReadByWalletID(ctx context.Context, walletID string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	return s.read(ctx, "WHERE wallet_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		walletID, pageSize, pageNo*pageSize)
+}
+
+func (s *SQLite) ReadByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+	return s.read(ctx, "WHERE wallet_id = ? AND type = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		walletID, typeFilter, pageSize, pageNo*pageSize)
+}
+
+func (s *SQLite) CountByWalletID(ctx context.Context, walletID string, limit int) (int64, error) {
+	return s.count(ctx, "WHERE wallet_id = ?", limit, walletID)
+}
+
+func (s *SQLite) CountByWalletIDFilterByType(ctx context.Context, walletID, typeFilter string, limit int) (int64, error) {
+	return s.count(ctx, "WHERE wallet_id = ? AND type = ?", limit, walletID, typeFilter)
+}
+
+func (s *SQLite) ReadByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time) ([]*transaction.Transaction, error) {
+	return s.read(ctx, "WHERE wallet_id = ? AND created_at >= ? AND created_at < ? ORDER BY created_at, id",
+		walletID, from.UnixNano(), to.UnixNano())
+}
+
+func (s *SQLite) ReadLastByWalletIDBefore(ctx context.Context, walletID string, before time.Time) (*transaction.Transaction, error) {
+	txns, err := s.read(ctx, "WHERE wallet_id = ? AND created_at < ? ORDER BY created_at DESC, id DESC LIMIT 1",
+		walletID, before.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txns) == 0 {
+		return nil, transaction.ErrTransactionNotFound
+	}
+
+	return txns[0], nil
+}
+
+func (s *SQLite) StreamByWalletIDBetween(ctx context.Context, walletID string, from, to time.Time, fn func(*transaction.Transaction) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+columns+" FROM "+s.table+" WHERE wallet_id = ? AND created_at >= ? AND created_at < ? ORDER BY created_at, id",
+		walletID, from.UnixNano(), to.UnixNano())
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		txn, err := scan(rows)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+
+		if err = fn(txn); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// AggregateByWalletID buckets in Go, since SQLite can't truncate dates in
+// arbitrary time zones.
+func (s *SQLite) AggregateByWalletID(ctx context.Context, walletID string, from, to time.Time, interval string, loc *time.Location) ([]*transaction.Bucket, error) {
+	txns, err := s.ReadByWalletIDBetween(ctx, walletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction.Aggregate(txns, interval, loc), nil
+}
+
+func (s *SQLite) DistinctWalletIDsBetween(ctx context.Context, from, to time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT DISTINCT wallet_id FROM "+s.table+" WHERE created_at >= ? AND created_at < ?",
+		from.UnixNano(), to.UnixNano())
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	walletIDs := []string{}
+	for rows.Next() {
+		var walletID string
+		if err = rows.Scan(&walletID); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	return walletIDs, rows.Err()
+}
+
+func (s *SQLite) ReadBefore(ctx context.Context, before time.Time, limit int) ([]*transaction.Transaction, error) {
+	return s.read(ctx, "WHERE created_at < ? ORDER BY created_at, id LIMIT ?", before.UnixNano(), limit)
+}
+
+func (s *SQLite) DeleteByIDs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := []interface{}{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	_, err := s.db.ExecContext(ctx, "DELETE FROM "+s.table+" WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+// Insert keeps the ids and creation times of the transactions, skipping the
+// ones that are there already.
+func (s *SQLite) Insert(ctx context.Context, txns []*transaction.Transaction) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = s.InsertTx(ctx, tx, txns); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertTx inserts the transactions as part of tx, so that they are stored
+// together with the other writes of the caller or not at all.
+func (s *SQLite) InsertTx(ctx context.Context, tx *sql.Tx, txns []*transaction.Transaction) error {
+	for _, txn := range txns {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO "+s.table+" ("+columns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+			txn.ID, txn.WalletID, txn.Type, txn.Amount, txn.BalanceBefore, txn.BalanceAfter, txn.CreatedAt.UnixNano())
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLite) read(ctx context.Context, query string, args ...interface{}) ([]*transaction.Transaction, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+columns+" FROM "+s.table+" "+query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	txns := []*transaction.Transaction{}
+	for rows.Next() {
+		txn, err := scan(rows)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, rows.Err()
+}
+
+// count stops counting at limit, unless it is 0.
+func (s *SQLite) count(ctx context.Context, where string, limit int, args ...interface{}) (int64, error) {
+	query := "SELECT COUNT(*) FROM " + s.table + " " + where
+	if limit > 0 {
+		query = "SELECT COUNT(*) FROM (SELECT 1 FROM " + s.table + " " + where + " LIMIT ?)"
+		args = append(args, limit)
+	}
+
+	var count int64
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func scan(rows *sql.Rows) (*transaction.Transaction, error) {
+	var txn transaction.Transaction
+	var createdAt int64
+	err := rows.Scan(&txn.ID, &txn.WalletID, &txn.Type, &txn.Amount, &txn.BalanceBefore, &txn.BalanceAfter, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	txn.CreatedAt = time.Unix(0, createdAt)
+
+	return &txn, nil
+}
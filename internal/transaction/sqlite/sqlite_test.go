@@ -0,0 +1,62 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	sqliteDB "github.com/gokcelb/wallet-api/internal/sqlite"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/transaction/sqlite"
+	"github.com/gokcelb/wallet-api/internal/transaction/transactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sqliteDB.Open(context.TODO(), config.SQLiteConf{Path: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestSQLite(t *testing.T) {
+	transactiontest.TestRepository(t, func(t *testing.T) transaction.TransactionRepository {
+		return sqlite.NewSQLite(openDB(t))
+	})
+}
+
+func TestSQLiteArchive(t *testing.T) {
+	db := openDB(t)
+	live, archived := sqlite.NewSQLite(db), sqlite.NewSQLiteArchive(db)
+
+	for _, txnType := range []string{"deposit", "withdrawal", "deposit"} {
+		_, err := live.Create(context.TODO(), &transaction.Transaction{WalletID: "w1", Type: txnType, Amount: 10})
+		require.NoError(t, err)
+	}
+
+	old, err := live.ReadBefore(context.TODO(), time.Now(), 2)
+	require.NoError(t, err)
+	require.Len(t, old, 2)
+
+	require.NoError(t, archived.Insert(context.TODO(), old))
+	require.NoError(t, archived.Insert(context.TODO(), old))
+	require.NoError(t, live.DeleteByIDs(context.TODO(), []string{old[0].ID, old[1].ID}))
+
+	for _, txn := range old {
+		_, err := live.Read(context.TODO(), txn.ID)
+		assert.ErrorIs(t, err, transaction.ErrTransactionNotFound)
+
+		got, err := archived.Read(context.TODO(), txn.ID)
+		require.NoError(t, err)
+		assert.Equal(t, txn, got)
+	}
+
+	count, err := live.CountByWalletID(context.TODO(), "w1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
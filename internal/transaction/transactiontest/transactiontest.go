@@ -0,0 +1,217 @@
+// Package transactiontest holds the contract every
+// transaction.TransactionRepository has to meet, so that the storage backends
+// behave the same.
+package transactiontest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unknownID is well formed for every backend but never handed out.
+const unknownID = "000000000000000000000000"
+
+const invalidID = "not-an-id"
+
+// TestRepository runs the contract against the repositories newRepository
+// returns, which have to be empty.
+func TestRepository(t *testing.T, newRepository func(t *testing.T) transaction.TransactionRepository) {
+	t.Run("create and read", func(t *testing.T) {
+		tr := newRepository(t)
+		before := time.Now()
+
+		id, err := tr.Create(context.TODO(), &transaction.Transaction{
+			WalletID: "w1", Type: "deposit", Amount: 50, BalanceBefore: 10, BalanceAfter: 60,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, id)
+
+		txn, err := tr.Read(context.TODO(), id)
+		require.NoError(t, err)
+		assert.Equal(t, id, txn.ID)
+		assert.Equal(t, "w1", txn.WalletID)
+		assert.Equal(t, "deposit", txn.Type)
+		assert.Equal(t, 50.0, txn.Amount)
+		assert.Equal(t, 10.0, txn.BalanceBefore)
+		assert.Equal(t, 60.0, txn.BalanceAfter)
+		assert.WithinDuration(t, before, txn.CreatedAt, time.Second)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		tr := newRepository(t)
+
+		for _, id := range []string{unknownID, invalidID} {
+			_, err := tr.Read(context.TODO(), id)
+			assert.ErrorIs(t, err, transaction.ErrTransactionNotFound, id)
+		}
+
+		_, err := tr.ReadLastByWalletIDBefore(context.TODO(), "w1", time.Now())
+		assert.ErrorIs(t, err, transaction.ErrTransactionNotFound)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		tr := newRepository(t)
+		ids := createAll(t, tr, "w1", "deposit", "withdraw", "deposit", "deposit", "withdraw")
+		createAll(t, tr, "w2", "deposit")
+
+		testCases := []struct {
+			desc     string
+			pageNo   int
+			pageSize int
+			expected []string
+		}{
+			{desc: "first page", pageNo: 0, pageSize: 2, expected: []string{ids[4], ids[3]}},
+			{desc: "middle page", pageNo: 1, pageSize: 2, expected: []string{ids[2], ids[1]}},
+			{desc: "partial last page", pageNo: 2, pageSize: 2, expected: []string{ids[0]}},
+			{desc: "page past the end", pageNo: 3, pageSize: 2, expected: []string{}},
+			{desc: "page as large as all", pageNo: 0, pageSize: 5, expected: []string{ids[4], ids[3], ids[2], ids[1], ids[0]}},
+			{desc: "page larger than all", pageNo: 0, pageSize: 10, expected: []string{ids[4], ids[3], ids[2], ids[1], ids[0]}},
+		}
+		for _, tC := range testCases {
+			t.Run(tC.desc, func(t *testing.T) {
+				txns, err := tr.ReadByWalletID(context.TODO(), "w1", tC.pageNo, tC.pageSize)
+				require.NoError(t, err)
+				assert.Equal(t, tC.expected, idsOf(txns))
+			})
+		}
+
+		count, err := tr.CountByWalletID(context.TODO(), "w1", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+
+		count, err = tr.CountByWalletID(context.TODO(), "w1", 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		count, err = tr.CountByWalletID(context.TODO(), "unknown", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("type filter", func(t *testing.T) {
+		tr := newRepository(t)
+		ids := createAll(t, tr, "w1", "deposit", "withdraw", "deposit", "deposit", "withdraw")
+
+		txns, err := tr.ReadByWalletIDFilterByType(context.TODO(), "w1", "deposit", 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[3], ids[2]}, idsOf(txns))
+
+		txns, err = tr.ReadByWalletIDFilterByType(context.TODO(), "w1", "deposit", 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0]}, idsOf(txns))
+
+		txns, err = tr.ReadByWalletIDFilterByType(context.TODO(), "w1", "refund", 0, 2)
+		require.NoError(t, err)
+		assert.Empty(t, txns)
+
+		count, err := tr.CountByWalletIDFilterByType(context.TODO(), "w1", "withdraw", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = tr.CountByWalletIDFilterByType(context.TODO(), "w1", "deposit", 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("time ranges", func(t *testing.T) {
+		tr := newRepository(t)
+		from := boundary()
+		early := createAll(t, tr, "w1", "deposit", "withdraw")
+		createAll(t, tr, "w2", "deposit")
+		mid := boundary()
+		late := createAll(t, tr, "w1", "deposit")
+		to := boundary()
+
+		txns, err := tr.ReadByWalletIDBetween(context.TODO(), "w1", from, mid)
+		require.NoError(t, err)
+		assert.Equal(t, early, idsOf(txns))
+
+		txns, err = tr.ReadByWalletIDBetween(context.TODO(), "w1", from, to)
+		require.NoError(t, err)
+		assert.Equal(t, append(early, late...), idsOf(txns))
+
+		streamed := []string{}
+		err = tr.StreamByWalletIDBetween(context.TODO(), "w1", mid, to, func(txn *transaction.Transaction) error {
+			streamed = append(streamed, txn.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, late, streamed)
+
+		txn, err := tr.ReadLastByWalletIDBefore(context.TODO(), "w1", mid)
+		require.NoError(t, err)
+		assert.Equal(t, early[1], txn.ID)
+
+		_, err = tr.ReadLastByWalletIDBefore(context.TODO(), "w1", from)
+		assert.ErrorIs(t, err, transaction.ErrTransactionNotFound)
+
+		walletIDs, err := tr.DistinctWalletIDsBetween(context.TODO(), from, mid)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"w1", "w2"}, walletIDs)
+
+		walletIDs, err = tr.DistinctWalletIDsBetween(context.TODO(), mid, to)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"w1"}, walletIDs)
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		tr := newRepository(t)
+		from := boundary()
+		for _, amount := range []float64{100, 50} {
+			_, err := tr.Create(context.TODO(), &transaction.Transaction{WalletID: "w1", Type: "deposit", Amount: amount})
+			require.NoError(t, err)
+		}
+		_, err := tr.Create(context.TODO(), &transaction.Transaction{WalletID: "w1", Type: "withdraw", Amount: 30})
+		require.NoError(t, err)
+		to := boundary()
+
+		buckets, err := tr.AggregateByWalletID(context.TODO(), "w1", from, to, transaction.IntervalMonth, time.UTC)
+		require.NoError(t, err)
+
+		// The transactions may straddle the start of a month.
+		totals := map[string]float64{}
+		counts := map[string]int64{}
+		for _, b := range buckets {
+			assert.True(t, b.Period.Equal(transaction.PeriodStart(b.Period, transaction.IntervalMonth, time.UTC)))
+			totals[b.Type] += b.Total
+			counts[b.Type] += b.Count
+		}
+		assert.Equal(t, map[string]float64{"deposit": 150, "withdraw": 30}, totals)
+		assert.Equal(t, map[string]int64{"deposit": 2, "withdraw": 1}, counts)
+	})
+}
+
+// createAll creates a transaction of each type in order, returning their ids.
+func createAll(t *testing.T, tr transaction.TransactionRepository, walletID string, types ...string) []string {
+	ids := []string{}
+	for _, txnType := range types {
+		id, err := tr.Create(context.TODO(), &transaction.Transaction{WalletID: walletID, Type: txnType, Amount: 10})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// boundary returns a time strictly between the transactions created before
+// and after it, even on backends that keep times in milliseconds.
+func boundary() time.Time {
+	time.Sleep(2 * time.Millisecond)
+	defer time.Sleep(2 * time.Millisecond)
+
+	return time.Now()
+}
+
+func idsOf(txns []*transaction.Transaction) []string {
+	ids := []string{}
+	for _, txn := range txns {
+		ids = append(ids, txn.ID)
+	}
+
+	return ids
+}
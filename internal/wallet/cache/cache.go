@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+// Store keeps wallets by id for a while. The in-process LRU is one, a cache
+// shared between instances can be another.
+type Store interface {
+	Get(ctx context.Context, id string) (*wallet.Wallet, bool)
+	Set(ctx context.Context, w *wallet.Wallet, ttl time.Duration)
+	Delete(ctx context.Context, id string)
+}
+
+// Cache reads wallets through store, dropping them from it whenever they
+// change. Other instances can still change a wallet behind its back, so it
+// serves wallets up to ttl old; ReadFresh is there for when that won't do.
+type Cache struct {
+	wr    wallet.WalletRepository
+	store Store
+	ttl   time.Duration
+	// invalidations counts the wallets dropped, so that a read racing with a
+	// change doesn't put back what it read from before the change.
+	invalidations uint64
+}
+
+func NewCache(wr wallet.WalletRepository, store Store, ttl time.Duration) *Cache {
+	return &Cache{wr: wr, store: store, ttl: ttl}
+}
+
+func (c *Cache) Create(ctx context.Context, w *wallet.Wallet) (string, error) {
+	return c.wr.Create(ctx, w)
+}
+
+func (c *Cache) Read(ctx context.Context, id string) (*wallet.Wallet, error) {
+	if w, ok := c.store.Get(ctx, id); ok {
+		return w, nil
+	}
+
+	return c.ReadFresh(ctx, id)
+}
+
+// ReadFresh reads the wallet from the repository, caching it for later reads.
+func (c *Cache) ReadFresh(ctx context.Context, id string) (*wallet.Wallet, error) {
+	invalidations := atomic.LoadUint64(&c.invalidations)
+
+	w, err := c.wr.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if atomic.LoadUint64(&c.invalidations) == invalidations {
+		cached := *w
+		c.store.Set(ctx, &cached, c.ttl)
+
+		// A change may have dropped the wallet between the check and the set.
+		if atomic.LoadUint64(&c.invalidations) != invalidations {
+			c.store.Delete(ctx, id)
+		}
+	}
+
+	return w, nil
+}
+
+func (c *Cache) ReadByUserID(ctx context.Context, userID string) (*wallet.Wallet, error) {
+	return c.wr.ReadByUserID(ctx, userID)
+}
+
+func (c *Cache) Delete(ctx context.Context, id string) error {
+	defer c.invalidate(ctx, id)
+	return c.wr.Delete(ctx, id)
+}
+
+// ApplyTransaction drops the wallet even when it fails, since that means the
+// wallet we had was stale.
+func (c *Cache) ApplyTransaction(ctx context.Context, w *wallet.Wallet, txn *transaction.Transaction) (string, error) {
+	defer c.invalidate(ctx, w.ID)
+	return c.wr.ApplyTransaction(ctx, w, txn)
+}
+
+func (c *Cache) UpdateLimits(ctx context.Context, id string, balanceUpperLimit, transactionUpperLimit float64) error {
+	defer c.invalidate(ctx, id)
+	return c.wr.UpdateLimits(ctx, id, balanceUpperLimit, transactionUpperLimit)
+}
+
+func (c *Cache) invalidate(ctx context.Context, id string) {
+	atomic.AddUint64(&c.invalidations, 1)
+	c.store.Delete(ctx, id)
+}
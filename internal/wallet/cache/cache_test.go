@@ -0,0 +1,130 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	transactionMemory "github.com/gokcelb/wallet-api/internal/transaction/memory"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/cache"
+	"github.com/gokcelb/wallet-api/internal/wallet/memory"
+	"github.com/gokcelb/wallet-api/internal/wallet/mock"
+	"github.com/gokcelb/wallet-api/internal/wallet/wallettest"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheContract(t *testing.T) {
+	wallettest.TestRepository(t, func(t *testing.T) (wallet.WalletRepository, transaction.TransactionRepository) {
+		transactions := transactionMemory.NewMemory()
+		return cache.NewCache(memory.NewMemory(transactions), cache.NewLRU(100), time.Minute), transactions
+	})
+}
+
+func TestCacheRead(t *testing.T) {
+	mockRepository := mock.NewMockWalletRepository(gomock.NewController(t))
+	c := cache.NewCache(mockRepository, cache.NewLRU(10), time.Minute)
+
+	mockRepository.EXPECT().Read(gomock.Any(), "1").Return(&wallet.Wallet{ID: "1", Balance: 10}, nil).Times(1)
+
+	for i := 0; i < 3; i++ {
+		w, err := c.Read(context.TODO(), "1")
+		assert.Nil(t, err)
+		assert.Equal(t, &wallet.Wallet{ID: "1", Balance: 10}, w)
+	}
+}
+
+func TestCacheReadNotFound(t *testing.T) {
+	mockRepository := mock.NewMockWalletRepository(gomock.NewController(t))
+	c := cache.NewCache(mockRepository, cache.NewLRU(10), time.Minute)
+
+	mockRepository.EXPECT().Read(gomock.Any(), "1").Return(nil, wallet.ErrWalletNotFound).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Read(context.TODO(), "1")
+		assert.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	}
+}
+
+func TestCacheReadFresh(t *testing.T) {
+	mockRepository := mock.NewMockWalletRepository(gomock.NewController(t))
+	c := cache.NewCache(mockRepository, cache.NewLRU(10), time.Minute)
+
+	gomock.InOrder(
+		mockRepository.EXPECT().Read(gomock.Any(), "1").Return(&wallet.Wallet{ID: "1", Balance: 10}, nil),
+		mockRepository.EXPECT().Read(gomock.Any(), "1").Return(&wallet.Wallet{ID: "1", Balance: 20}, nil),
+	)
+
+	w, _ := c.Read(context.TODO(), "1")
+	assert.Equal(t, 10.0, w.Balance)
+
+	w, _ = c.ReadFresh(context.TODO(), "1")
+	assert.Equal(t, 20.0, w.Balance)
+
+	w, _ = c.Read(context.TODO(), "1")
+	assert.Equal(t, 20.0, w.Balance)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		expect func(m *mock.MockWalletRepository)
+		change func(c *cache.Cache) error
+	}{
+		{
+			desc: "apply transaction",
+			expect: func(m *mock.MockWalletRepository) {
+				m.EXPECT().ApplyTransaction(gomock.Any(), &wallet.Wallet{ID: "1"}, gomock.Any()).Return("1", nil)
+			},
+			change: func(c *cache.Cache) error {
+				_, err := c.ApplyTransaction(context.TODO(), &wallet.Wallet{ID: "1"}, &transaction.Transaction{})
+				return err
+			},
+		},
+		{
+			desc: "failed transaction",
+			expect: func(m *mock.MockWalletRepository) {
+				m.EXPECT().
+					ApplyTransaction(gomock.Any(), &wallet.Wallet{ID: "1"}, gomock.Any()).
+					Return("", wallet.ErrWalletBalanceUpdateFailed)
+			},
+			change: func(c *cache.Cache) error {
+				_, err := c.ApplyTransaction(context.TODO(), &wallet.Wallet{ID: "1"}, &transaction.Transaction{})
+				return err
+			},
+		},
+		{
+			desc: "update limits",
+			expect: func(m *mock.MockWalletRepository) {
+				m.EXPECT().UpdateLimits(gomock.Any(), "1", 1000.0, 100.0).Return(nil)
+			},
+			change: func(c *cache.Cache) error {
+				return c.UpdateLimits(context.TODO(), "1", 1000, 100)
+			},
+		},
+		{
+			desc: "delete",
+			expect: func(m *mock.MockWalletRepository) {
+				m.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+			},
+			change: func(c *cache.Cache) error {
+				return c.Delete(context.TODO(), "1")
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockRepository := mock.NewMockWalletRepository(gomock.NewController(t))
+			c := cache.NewCache(mockRepository, cache.NewLRU(10), time.Minute)
+
+			mockRepository.EXPECT().Read(gomock.Any(), "1").Return(&wallet.Wallet{ID: "1", Balance: 10}, nil).Times(2)
+			tC.expect(mockRepository)
+
+			c.Read(context.TODO(), "1")
+			tC.change(c)
+			c.Read(context.TODO(), "1")
+		})
+	}
+}
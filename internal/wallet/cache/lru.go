@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/wallet"
+)
+
+type entry struct {
+	w         wallet.Wallet
+	expiresAt time.Time
+}
+
+// lru keeps up to size wallets in the process, dropping the least recently
+// used one to make room.
+type lru struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+func NewLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), elements: make(map[string]*list.Element)}
+}
+
+func (c *lru) Get(ctx context.Context, id string) (*wallet.Wallet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.elements[id]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+
+	w := e.w
+	return &w, true
+}
+
+func (c *lru) Set(ctx context.Context, w *wallet.Wallet, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	e := &entry{*w, time.Now().Add(ttl)}
+	if el, ok := c.elements[w.ID]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+	c.elements[w.ID] = c.order.PushFront(e)
+}
+
+func (c *lru) Delete(ctx context.Context, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.elements[id]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *lru) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.elements, el.Value.(*entry).w.ID)
+}
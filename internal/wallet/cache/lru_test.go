@@ -0,0 +1,47 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	lru := cache.NewLRU(2)
+	lru.Set(context.TODO(), &wallet.Wallet{ID: "1"}, time.Minute)
+	lru.Set(context.TODO(), &wallet.Wallet{ID: "2"}, time.Minute)
+	lru.Get(context.TODO(), "1")
+	lru.Set(context.TODO(), &wallet.Wallet{ID: "3"}, time.Minute)
+
+	_, ok := lru.Get(context.TODO(), "1")
+	assert.True(t, ok)
+	_, ok = lru.Get(context.TODO(), "2")
+	assert.False(t, ok)
+	_, ok = lru.Get(context.TODO(), "3")
+	assert.True(t, ok)
+}
+
+func TestLRUExpires(t *testing.T) {
+	lru := cache.NewLRU(2)
+	lru.Set(context.TODO(), &wallet.Wallet{ID: "1"}, -time.Second)
+
+	_, ok := lru.Get(context.TODO(), "1")
+	assert.False(t, ok)
+}
+
+func TestLRUReturnsCopies(t *testing.T) {
+	lru := cache.NewLRU(2)
+	givenWallet := &wallet.Wallet{ID: "1", Balance: 10}
+	lru.Set(context.TODO(), givenWallet, time.Minute)
+	givenWallet.Balance = 20
+
+	w, _ := lru.Get(context.TODO(), "1")
+	w.Balance = 30
+
+	w, _ = lru.Get(context.TODO(), "1")
+	assert.Equal(t, 10.0, w.Balance)
+}
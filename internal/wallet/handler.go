@@ -3,9 +3,14 @@ package wallet
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/challenge"
 	"github.com/gokcelb/wallet-api/internal/transaction"
 	"github.com/labstack/echo/v4"
 )
@@ -17,30 +22,54 @@ var badRequestErrors = []error{
 var notFoundErrors = []error{
 	ErrWalletNotFound,
 	transaction.ErrTransactionNotFound,
+	challenge.ErrChallengeNotFound,
 }
 
 var unprocessableEntityErrors = []error{
 	ErrWalletWithUserIDExists,
+	ErrBalanceAboveUpperLimit,
 	ErrAboveMaximumBalanceLimit,
 	ErrAboveMaximumTransactionLimit,
 	ErrBelowMinimumTransactionLimit,
 	ErrInsufficientBalance,
+	challenge.ErrInvalidCode,
 }
 
+var conflictErrors = []error{
+	ErrWalletBalanceUpdateFailed,
+	challenge.ErrChallengeExpired,
+	challenge.ErrChallengeNotPending,
+}
+
+var tooManyRequestsErrors = []error{
+	challenge.ErrLockedOut,
+}
+
+// walletRoutePrefix matches every route that addresses a single wallet,
+// including the ones registered by other packages.
+const walletRoutePrefix = "/wallets/:id"
+
 var (
 	DefaultPageNo   = 0
 	DefaultPageSize = 10
+	MaxPageSize     = 100
 
-	ErrInvalidPageNo   = errors.New("pageNo cannot be converted to integer")
-	ErrInvalidPageSize = errors.New("pageSize cannot be converted to integer")
+	ErrInvalidPageNo        = errors.New("pageNo cannot be converted to integer")
+	ErrInvalidPageSize      = errors.New("pageSize cannot be converted to integer")
+	ErrPageNoOutOfRange     = errors.New("pageNo cannot be negative")
+	ErrPageSizeOutOfRange   = fmt.Errorf("pageSize must be between 1 and %d", MaxPageSize)
+	ErrInvalidEstimateTotal = errors.New("estimateTotal cannot be converted to boolean")
 )
 
 type WalletService interface {
 	CreateWallet(ctx context.Context, info *WalletCreationInfo) (string, error)
 	GetWallet(ctx context.Context, id string) (*Wallet, error)
 	DeleteWallet(ctx context.Context, id string) error
+	UpdateLimits(ctx context.Context, info *LimitsUpdateInfo) error
 	CreateTransaction(ctx context.Context, info *TransactionCreationInfo) (string, error)
-	GetTransactions(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error)
+	ChallengeTransaction(ctx context.Context, info *TransactionCreationInfo) (*challenge.Challenge, error)
+	CompleteTransaction(ctx context.Context, info *ChallengeCompletionInfo) (string, error)
+	GetTransactions(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int, estimateTotal bool) (*TransactionPage, error)
 }
 
 type handler struct {
@@ -48,39 +77,139 @@ type handler struct {
 }
 
 type WalletCreationInfo struct {
-	UserID                string  `json:"userId"`
+	UserID                string  `json:"-"`
+	BalanceUpperLimit     float64 `json:"balanceUpperLimit"`
+	TransactionUpperLimit float64 `json:"transactionUpperLimit"`
+}
+
+type LimitsUpdateInfo struct {
+	WalletID              string  `param:"id" json:"-"`
 	BalanceUpperLimit     float64 `json:"balanceUpperLimit"`
 	TransactionUpperLimit float64 `json:"transactionUpperLimit"`
 }
 
 type TransactionCreationInfo struct {
+	// ID, when set, is the id the transaction is stored with, so that
+	// creating it again fails with ErrDuplicateTransaction instead of
+	// applying it twice.
+	ID              string  `json:"-"`
 	WalletID        string  `param:"id"`
 	TransactionType string  `json:"type"`
 	Amount          float64 `json:"amount"`
 }
 
+type ChallengeCompletionInfo struct {
+	WalletID    string `param:"id" json:"-"`
+	ChallengeID string `param:"challengeId" json:"-"`
+	Code        string `json:"code"`
+}
+
 type PostResponse struct {
 	ID string `json:"id"`
 }
 
+type PaginatedResponse struct {
+	Items          []*transaction.Transaction `json:"items"`
+	Total          int64                      `json:"total"`
+	TotalEstimated bool                       `json:"totalEstimated"`
+	Page           PageInfo                   `json:"page"`
+	Links          PageLinks                  `json:"links"`
+}
+
+// PageInfo leaves TotalPages out when the total is estimated, as the pages
+// would only be counted up to the estimate.
+type PageInfo struct {
+	PageNo     int  `json:"pageNo"`
+	PageSize   int  `json:"pageSize"`
+	TotalPages *int `json:"totalPages,omitempty"`
+}
+
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
 func NewHandler(ws WalletService) *handler {
 	return &handler{ws}
 }
 
 func (h *handler) RegisterRoutes(e *echo.Echo) {
-	e.POST("/wallets", h.CreateWallet)
-	e.GET("/wallets/:id", h.GetWallet)
-	e.DELETE("/wallets/:id", h.DeleteWallet)
+	e.POST("/wallets", h.CreateWallet, auth.RequireScopes(auth.ScopeWalletsWrite))
+	e.GET("/wallets/:id", h.GetWallet, auth.RequireScopes(auth.ScopeWalletsRead))
 
-	e.POST("/wallets/:id/transactions", h.CreateTransaction)
-	e.GET("/wallets/:id/transactions", h.GetTransactions)
+	e.POST("/wallets/:id/transactions", h.CreateTransaction, auth.RequireScopes(auth.ScopeTransactionsWrite))
+	e.GET("/wallets/:id/transactions", h.GetTransactions, auth.RequireScopes(auth.ScopeTransactionsRead))
+	e.POST("/wallets/:id/transactions/challenges/:challengeId", h.CompleteTransaction, auth.RequireScopes(auth.ScopeTransactionsWrite))
+}
+
+// RegisterAdminRoutes registers the operations reserved for admins under
+// /admin, outside of the ownership checked /wallets/:id routes.
+func (h *handler) RegisterAdminRoutes(e *echo.Echo) {
+	g := e.Group("/admin", auth.RequireScopes(auth.ScopeAdmin))
+	g.DELETE("/wallets/:id", h.DeleteWallet)
+	g.PUT("/wallets/:id/limits", h.UpdateLimits)
+}
+
+// RequireOwner answers 404 for wallets that do not belong to the user of the
+// token, the same as for wallets that do not exist, so that wallet ids cannot
+// be probed. Callers restricted to a list of wallets are checked against it
+// instead.
+func (h *handler) RequireOwner(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !strings.HasPrefix(c.Path(), walletRoutePrefix) {
+			return next(c)
+		}
+
+		claims, ok := auth.GetClaims(c)
+		if !ok {
+			return echo.NewHTTPError(http.StatusUnauthorized, auth.ErrMissingSubject.Error())
+		}
+
+		if len(claims.WalletIDs) > 0 {
+			if !claims.AllowsWallet(c.Param("id")) {
+				return echo.NewHTTPError(http.StatusNotFound, ErrWalletNotFound.Error())
+			}
+			return next(c)
+		}
+
+		if auth.HasScope(c, auth.ScopeAnyWallet) {
+			return next(c)
+		}
+
+		userID, ok := auth.UserID(c)
+		if !ok {
+			return echo.NewHTTPError(http.StatusUnauthorized, auth.ErrMissingSubject.Error())
+		}
+
+		w, err := h.ws.GetWallet(c.Request().Context(), c.Param("id"))
+		if err != nil && isNotFound(err) {
+			return echo.NewHTTPError(http.StatusNotFound, ErrWalletNotFound.Error())
+		} else if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		if w.UserID != userID {
+			return echo.NewHTTPError(http.StatusNotFound, ErrWalletNotFound.Error())
+		}
+
+		return next(c)
+	}
 }
 
 func (h *handler) CreateWallet(c echo.Context) error {
+	userID, ok := auth.UserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, auth.ErrMissingSubject.Error())
+	}
+
 	var info WalletCreationInfo
 	if err := c.Bind(&info); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	info.UserID = userID
 
 	id, err := h.ws.CreateWallet(c.Request().Context(), &info)
 	if err != nil && isBadRequest(err) {
@@ -112,6 +241,24 @@ func (h *handler) DeleteWallet(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *handler) UpdateLimits(c echo.Context) error {
+	var info LimitsUpdateInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err := h.ws.UpdateLimits(c.Request().Context(), &info)
+	if err != nil && isNotFound(err) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil && isUnprocessableEntity(err) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (h *handler) CreateTransaction(c echo.Context) error {
 	var info TransactionCreationInfo
 	if err := c.Bind(&info); err != nil {
@@ -119,12 +266,51 @@ func (h *handler) CreateTransaction(c echo.Context) error {
 	}
 
 	txnID, err := h.ws.CreateTransaction(c.Request().Context(), &info)
+	if err != nil && errors.Is(err, ErrStepUpRequired) {
+		return h.challengeTransaction(c, &info)
+	}
+
+	return h.transactionResponse(c, txnID, err)
+}
+
+// challengeTransaction answers with the challenge to complete, through
+// CompleteTransaction, before the transaction is created.
+func (h *handler) challengeTransaction(c echo.Context, info *TransactionCreationInfo) error {
+	ch, err := h.ws.ChallengeTransaction(c.Request().Context(), info)
+	if err != nil && isNotFound(err) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil && isUnprocessableEntity(err) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	} else if err != nil && isTooManyRequests(err) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, ch)
+}
+
+func (h *handler) CompleteTransaction(c echo.Context) error {
+	var info ChallengeCompletionInfo
+	if err := c.Bind(&info); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	txnID, err := h.ws.CompleteTransaction(c.Request().Context(), &info)
+	return h.transactionResponse(c, txnID, err)
+}
+
+func (h *handler) transactionResponse(c echo.Context, txnID string, err error) error {
 	if err != nil && isNotFound(err) {
 		return echo.NewHTTPError(http.StatusNotFound, err.Error())
 	} else if err != nil && isBadRequest(err) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	} else if err != nil && isUnprocessableEntity(err) {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	} else if err != nil && isConflict(err) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	} else if err != nil && isTooManyRequests(err) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -138,7 +324,19 @@ func (h *handler) GetTransactions(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	txns, err := h.ws.GetTransactions(c.Request().Context(), c.Param("id"), c.QueryParam("type"), pageNo, pageSize)
+	estimateTotal, err := h.getEstimateTotalParamOrDefault(c.QueryParam("estimateTotal"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := h.ws.GetTransactions(
+		c.Request().Context(),
+		c.Param("id"),
+		c.QueryParam("type"),
+		pageNo,
+		pageSize,
+		estimateTotal,
+	)
 	if err != nil && isBadRequest(err) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	} else if err != nil && isNotFound(err) {
@@ -147,27 +345,90 @@ func (h *handler) GetTransactions(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, txns)
+	return c.JSON(http.StatusOK, newPaginatedResponse(c.Request().URL, page, pageNo, pageSize))
 }
 
 func (h *handler) getPaginationParamsOrDefault(pageNoQuery string, pageSizeQuery string) (int, int, error) {
-	if pageNoQuery == "" || pageSizeQuery == "" {
-		return DefaultPageNo, DefaultPageSize, nil
+	pageNo, pageSize := DefaultPageNo, DefaultPageSize
+
+	if pageNoQuery != "" {
+		var err error
+		if pageNo, err = strconv.Atoi(pageNoQuery); err != nil {
+			return 0, 0, ErrInvalidPageNo
+		}
 	}
 
-	pageNo, err := strconv.Atoi(pageNoQuery)
-	if err != nil {
-		return 0, 0, ErrInvalidPageNo
+	if pageSizeQuery != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(pageSizeQuery); err != nil {
+			return 0, 0, ErrInvalidPageSize
+		}
 	}
 
-	pageSize, err := strconv.Atoi(pageSizeQuery)
-	if err != nil {
-		return 0, 0, ErrInvalidPageSize
+	if pageNo < 0 {
+		return 0, 0, ErrPageNoOutOfRange
+	}
+
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return 0, 0, ErrPageSizeOutOfRange
 	}
 
 	return pageNo, pageSize, nil
 }
 
+func (h *handler) getEstimateTotalParamOrDefault(estimateTotalQuery string) (bool, error) {
+	if estimateTotalQuery == "" {
+		return false, nil
+	}
+
+	estimateTotal, err := strconv.ParseBool(estimateTotalQuery)
+	if err != nil {
+		return false, ErrInvalidEstimateTotal
+	}
+
+	return estimateTotal, nil
+}
+
+func newPaginatedResponse(u *url.URL, page *TransactionPage, pageNo, pageSize int) *PaginatedResponse {
+	totalPages := int((page.Total + int64(pageSize) - 1) / int64(pageSize))
+
+	links := PageLinks{
+		Self:  pageLink(u, pageNo, pageSize),
+		First: pageLink(u, 0, pageSize),
+	}
+	if pageNo > 0 {
+		links.Prev = pageLink(u, pageNo-1, pageSize)
+	}
+	if pageNo+1 < totalPages || (page.TotalEstimated && len(page.Transactions) == pageSize) {
+		links.Next = pageLink(u, pageNo+1, pageSize)
+	}
+	if !page.TotalEstimated && totalPages > 0 {
+		links.Last = pageLink(u, totalPages-1, pageSize)
+	}
+
+	pageInfo := PageInfo{PageNo: pageNo, PageSize: pageSize}
+	if !page.TotalEstimated {
+		pageInfo.TotalPages = &totalPages
+	}
+
+	return &PaginatedResponse{
+		Items:          page.Transactions,
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
+		Page:           pageInfo,
+		Links:          links,
+	}
+}
+
+func pageLink(u *url.URL, pageNo, pageSize int) string {
+	query := u.Query()
+	query.Set("pageNo", strconv.Itoa(pageNo))
+	query.Set("pageSize", strconv.Itoa(pageSize))
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
+
 func isBadRequest(err error) bool {
 	return ContainsError(err, badRequestErrors)
 }
@@ -180,6 +441,14 @@ func isUnprocessableEntity(err error) bool {
 	return ContainsError(err, unprocessableEntityErrors)
 }
 
+func isConflict(err error) bool {
+	return ContainsError(err, conflictErrors)
+}
+
+func isTooManyRequests(err error) bool {
+	return ContainsError(err, tooManyRequestsErrors)
+}
+
 func ContainsError(err error, errList []error) bool {
 	for _, e := range errList {
 		if errors.Is(e, err) {
@@ -9,11 +9,14 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/challenge"
 	"github.com/gokcelb/wallet-api/internal/transaction"
 	"github.com/gokcelb/wallet-api/internal/wallet"
 	"github.com/gokcelb/wallet-api/internal/wallet/mock"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,11 +34,46 @@ func createMockWalletService(t *testing.T) *mock.MockWalletService {
 	return mock.NewMockWalletService(gomock.NewController(t))
 }
 
+func newTokenService() *auth.TokenService {
+	conf := getConf().JWT
+	ks, err := auth.NewKeySet(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return auth.NewTokenService(conf, ks, auth.NewMemoryDenylist())
+}
+
+func useJWT(e *echo.Echo) {
+	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		ParseTokenFunc: newTokenService().Decode,
+	}))
+}
+
+type bearerTransport struct {
+	token string
+}
+
+func (bt *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+bt.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newAuthenticatedClient(userID, role string) *http.Client {
+	token, _, err := newTokenService().Create(userID, role)
+	if err != nil {
+		panic(err)
+	}
+
+	return &http.Client{Transport: &bearerTransport{token}}
+}
+
 func TestHandlerPostWallet(t *testing.T) {
 	mockWalletService := createMockWalletService(t)
 	h := wallet.NewHandler(mockWalletService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -104,7 +142,7 @@ func TestHandlerPostWallet(t *testing.T) {
 				Return(tC.mockWSWalletID, tC.mockWSError)
 
 			walletCreationInfoBytes, _ := json.Marshal(walletCreationInfo)
-			res, err := http.DefaultClient.Post(
+			res, err := newAuthenticatedClient(tC.givenUserID, auth.RoleUser).Post(
 				fmt.Sprintf("%s/wallets", testServer.URL),
 				contentType,
 				bytes.NewReader(walletCreationInfoBytes),
@@ -128,6 +166,7 @@ func TestHandlerGetWallet(t *testing.T) {
 	h := wallet.NewHandler(mockWalletService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -173,7 +212,7 @@ func TestHandlerGetWallet(t *testing.T) {
 		t.Run(tC.desc, func(t *testing.T) {
 			mockWalletService.EXPECT().GetWallet(gomock.Any(), tC.givenID).Return(tC.mockWSWallet, tC.mockWSErr)
 
-			res, err := http.DefaultClient.Get(fmt.Sprintf("%s/wallets/%s", testServer.URL, tC.givenID))
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Get(fmt.Sprintf("%s/wallets/%s", testServer.URL, tC.givenID))
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
@@ -193,7 +232,8 @@ func TestHandlerDeleteWallet(t *testing.T) {
 	h := wallet.NewHandler(mockService)
 
 	e := echo.New()
-	h.RegisterRoutes(e)
+	useJWT(e)
+	h.RegisterAdminRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
 
@@ -220,12 +260,12 @@ func TestHandlerDeleteWallet(t *testing.T) {
 		t.Run(tC.desc, func(t *testing.T) {
 			mockService.EXPECT().DeleteWallet(gomock.Any(), tC.givenWalletID).Return(tC.mockWSErr)
 
-			req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/wallets/%s", testServer.URL, tC.givenWalletID), nil)
+			req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/admin/wallets/%s", testServer.URL, tC.givenWalletID), nil)
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
 
-			res, err := testServer.Client().Do(req)
+			res, err := newAuthenticatedClient("1", auth.RoleAdmin).Do(req)
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
@@ -235,11 +275,77 @@ func TestHandlerDeleteWallet(t *testing.T) {
 	}
 }
 
+func TestHandlerUpdateLimits(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	h.RegisterAdminRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		givenRole                  string
+		expectWSCall               bool
+		mockWSErr                  error
+		expectedResponseStatusCode int
+	}{
+		{
+			desc:                       "admin updates valid limits, return success",
+			givenRole:                  auth.RoleAdmin,
+			expectWSCall:               true,
+			expectedResponseStatusCode: 204,
+		},
+		{
+			desc:                       "admin updates invalid limits, return error",
+			givenRole:                  auth.RoleAdmin,
+			expectWSCall:               true,
+			mockWSErr:                  wallet.ErrBalanceAboveUpperLimit,
+			expectedResponseStatusCode: 422,
+		},
+		{
+			desc:                       "support staff updates limits, return forbidden",
+			givenRole:                  auth.RoleSupport,
+			expectedResponseStatusCode: 403,
+		},
+		{
+			desc:                       "end user updates limits, return forbidden",
+			givenRole:                  auth.RoleUser,
+			expectedResponseStatusCode: 403,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			info := &wallet.LimitsUpdateInfo{BalanceUpperLimit: 2000, TransactionUpperLimit: 500}
+			if tC.expectWSCall {
+				mockWalletService.EXPECT().
+					UpdateLimits(gomock.Any(), &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 2000, TransactionUpperLimit: 500}).
+					Return(tC.mockWSErr)
+			}
+
+			infoBytes, _ := json.Marshal(info)
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/admin/wallets/1/limits", testServer.URL), bytes.NewReader(infoBytes))
+			req.Header.Set("Content-Type", contentType)
+
+			res, err := newAuthenticatedClient("1", tC.givenRole).Do(req)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+		})
+	}
+}
+
 func TestHandlerCreateTransaction(t *testing.T) {
 	mockWalletService := createMockWalletService(t)
 	h := wallet.NewHandler(mockWalletService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -314,6 +420,16 @@ func TestHandlerCreateTransaction(t *testing.T) {
 			expectedResponseStatusCode: 422,
 			expectedResponseBody:       httpErr{wallet.ErrInsufficientBalance.Error()},
 		},
+		{
+			desc:                       "concurrent balance updates keep conflicting, return error",
+			givenWalletID:              "1",
+			givenTransactionType:       "deposit",
+			givenAmount:                500,
+			mockWSTransactionID:        "",
+			mockWSErr:                  wallet.ErrWalletBalanceUpdateFailed,
+			expectedResponseStatusCode: 409,
+			expectedResponseBody:       httpErr{wallet.ErrWalletBalanceUpdateFailed.Error()},
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -328,7 +444,7 @@ func TestHandlerCreateTransaction(t *testing.T) {
 				Return(tC.mockWSTransactionID, tC.mockWSErr)
 
 			transactionCreationInfoBytes, _ := json.Marshal(transactionCreationInfo)
-			res, err := testServer.Client().Post(
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Post(
 				fmt.Sprintf("%s/wallets/%s/transactions", testServer.URL, tC.givenWalletID),
 				contentType,
 				bytes.NewReader(transactionCreationInfoBytes),
@@ -347,11 +463,145 @@ func TestHandlerCreateTransaction(t *testing.T) {
 	}
 }
 
+func TestHandlerCreateTransactionWithStepUp(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	transactionCreationInfo := wallet.TransactionCreationInfo{WalletID: "1", TransactionType: "withdrawal", Amount: 2000}
+	mockChallenge := &challenge.Challenge{ID: "c1", WalletID: "1", TransactionType: "withdrawal", Amount: 2000, Status: challenge.StatusPending}
+
+	mockWalletService.EXPECT().
+		CreateTransaction(gomock.Any(), &transactionCreationInfo).
+		Return("", wallet.ErrStepUpRequired)
+	mockWalletService.EXPECT().
+		ChallengeTransaction(gomock.Any(), &transactionCreationInfo).
+		Return(mockChallenge, nil)
+
+	transactionCreationInfoBytes, _ := json.Marshal(transactionCreationInfo)
+	res, err := newAuthenticatedClient("1", auth.RoleUser).Post(
+		fmt.Sprintf("%s/wallets/1/transactions", testServer.URL),
+		contentType,
+		bytes.NewReader(transactionCreationInfoBytes),
+	)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBodyBytes, _ := io.ReadAll(res.Body)
+	expectedResBodyBytes, _ := json.Marshal(mockChallenge)
+
+	assert.Equal(t, 202, res.StatusCode)
+	assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+
+	mockWalletService.EXPECT().
+		CreateTransaction(gomock.Any(), &transactionCreationInfo).
+		Return("", wallet.ErrStepUpRequired)
+	mockWalletService.EXPECT().
+		ChallengeTransaction(gomock.Any(), &transactionCreationInfo).
+		Return(nil, wallet.ErrInsufficientBalance)
+
+	res, err = newAuthenticatedClient("1", auth.RoleUser).Post(
+		fmt.Sprintf("%s/wallets/1/transactions", testServer.URL),
+		contentType,
+		bytes.NewReader(transactionCreationInfoBytes),
+	)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 422, res.StatusCode)
+}
+
+func TestHandlerCompleteTransaction(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc                       string
+		mockWSTransactionID        string
+		mockWSErr                  error
+		expectedResponseStatusCode int
+		expectedResponseBody       interface{}
+	}{
+		{
+			desc:                       "code is correct, return transaction",
+			mockWSTransactionID:        "1",
+			expectedResponseStatusCode: 201,
+			expectedResponseBody:       wallet.PostResponse{"1"},
+		},
+		{
+			desc:                       "code is incorrect, return error",
+			mockWSErr:                  challenge.ErrInvalidCode,
+			expectedResponseStatusCode: 422,
+			expectedResponseBody:       httpErr{challenge.ErrInvalidCode.Error()},
+		},
+		{
+			desc:                       "challenge is expired, return error",
+			mockWSErr:                  challenge.ErrChallengeExpired,
+			expectedResponseStatusCode: 409,
+			expectedResponseBody:       httpErr{challenge.ErrChallengeExpired.Error()},
+		},
+		{
+			desc:                       "attempts are used up, return error",
+			mockWSErr:                  challenge.ErrLockedOut,
+			expectedResponseStatusCode: 429,
+			expectedResponseBody:       httpErr{challenge.ErrLockedOut.Error()},
+		},
+		{
+			desc:                       "challenge does not exist, return error",
+			mockWSErr:                  challenge.ErrChallengeNotFound,
+			expectedResponseStatusCode: 404,
+			expectedResponseBody:       httpErr{challenge.ErrChallengeNotFound.Error()},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			completionInfo := wallet.ChallengeCompletionInfo{WalletID: "1", ChallengeID: "c1", Code: "123456"}
+
+			mockWalletService.EXPECT().
+				CompleteTransaction(gomock.Any(), &completionInfo).
+				Return(tC.mockWSTransactionID, tC.mockWSErr)
+
+			completionInfoBytes, _ := json.Marshal(completionInfo)
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Post(
+				fmt.Sprintf("%s/wallets/1/transactions/challenges/c1", testServer.URL),
+				contentType,
+				bytes.NewReader(completionInfoBytes),
+			)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			resBodyBytes, _ := io.ReadAll(res.Body)
+			expectedResBodyBytes, _ := json.Marshal(tC.expectedResponseBody)
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+		})
+	}
+}
+
 func TestHandlerGetTransactions(t *testing.T) {
 	mockWalletService := createMockWalletService(t)
 	h := wallet.NewHandler(mockWalletService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -385,18 +635,27 @@ func TestHandlerGetTransactions(t *testing.T) {
 			},
 			mockWSErr:                  nil,
 			expectedResponseStatusCode: 200,
-			expectedResponseBody: []*transaction.Transaction{
-				{
-					ID:       "1",
-					WalletID: "1",
-					Type:     "deposit",
-					Amount:   200,
+			expectedResponseBody: wallet.PaginatedResponse{
+				Items: []*transaction.Transaction{
+					{
+						ID:       "1",
+						WalletID: "1",
+						Type:     "deposit",
+						Amount:   200,
+					},
+					{
+						ID:       "2",
+						WalletID: "1",
+						Type:     "withdrawal",
+						Amount:   100,
+					},
 				},
-				{
-					ID:       "2",
-					WalletID: "1",
-					Type:     "withdrawal",
-					Amount:   100,
+				Total: 2,
+				Page:  wallet.PageInfo{PageNo: 0, PageSize: 10, TotalPages: intPtr(1)},
+				Links: wallet.PageLinks{
+					Self:  "/wallets/1/transactions?pageNo=0&pageSize=10",
+					First: "/wallets/1/transactions?pageNo=0&pageSize=10",
+					Last:  "/wallets/1/transactions?pageNo=0&pageSize=10",
 				},
 			},
 		},
@@ -420,18 +679,27 @@ func TestHandlerGetTransactions(t *testing.T) {
 			},
 			mockWSErr:                  nil,
 			expectedResponseStatusCode: 200,
-			expectedResponseBody: []*transaction.Transaction{
-				{
-					ID:       "1",
-					WalletID: "2",
-					Type:     "deposit",
-					Amount:   200,
+			expectedResponseBody: wallet.PaginatedResponse{
+				Items: []*transaction.Transaction{
+					{
+						ID:       "1",
+						WalletID: "2",
+						Type:     "deposit",
+						Amount:   200,
+					},
+					{
+						ID:       "2",
+						WalletID: "2",
+						Type:     "deposit",
+						Amount:   300,
+					},
 				},
-				{
-					ID:       "2",
-					WalletID: "2",
-					Type:     "deposit",
-					Amount:   300,
+				Total: 2,
+				Page:  wallet.PageInfo{PageNo: 0, PageSize: 10, TotalPages: intPtr(1)},
+				Links: wallet.PageLinks{
+					Self:  "/wallets/2/transactions?pageNo=0&pageSize=10&type=deposit",
+					First: "/wallets/2/transactions?pageNo=0&pageSize=10&type=deposit",
+					Last:  "/wallets/2/transactions?pageNo=0&pageSize=10&type=deposit",
 				},
 			},
 		},
@@ -456,6 +724,14 @@ func TestHandlerGetTransactions(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
+			var mockWSPage *wallet.TransactionPage
+			if tC.mockWSErr == nil {
+				mockWSPage = &wallet.TransactionPage{
+					Transactions: tC.mockWSTransactions,
+					Total:        int64(len(tC.mockWSTransactions)),
+				}
+			}
+
 			mockWalletService.EXPECT().
 				GetTransactions(
 					gomock.Any(),
@@ -463,7 +739,8 @@ func TestHandlerGetTransactions(t *testing.T) {
 					tC.givenType,
 					wallet.DefaultPageNo,
 					wallet.DefaultPageSize,
-				).Return(tC.mockWSTransactions, tC.mockWSErr)
+					false,
+				).Return(mockWSPage, tC.mockWSErr)
 
 			var url string
 			if tC.givenType == "" {
@@ -472,7 +749,7 @@ func TestHandlerGetTransactions(t *testing.T) {
 				url = fmt.Sprintf("%s/wallets/%s/transactions?type=%s", testServer.URL, tC.givenWalletID, tC.givenType)
 			}
 
-			res, err := testServer.Client().Get(url)
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Get(url)
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
@@ -492,6 +769,7 @@ func TestHandlerGetTransactionsInvalidPaginationParams(t *testing.T) {
 	h := wallet.NewHandler(mockWalletService)
 
 	e := echo.New()
+	useJWT(e)
 	h.RegisterRoutes(e)
 	testServer := httptest.NewServer(e.Server.Handler)
 	defer testServer.Close()
@@ -520,6 +798,30 @@ func TestHandlerGetTransactionsInvalidPaginationParams(t *testing.T) {
 			expectedResponseStatusCode: 400,
 			expectedResponseBody:       httpErr{wallet.ErrInvalidPageSize.Error()},
 		},
+		{
+			desc:                       "negative pageNo, return error",
+			givenWalletID:              "1",
+			givenPageNo:                "-1",
+			givenPageSize:              "10",
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{wallet.ErrPageNoOutOfRange.Error()},
+		},
+		{
+			desc:                       "zero pageSize, return error",
+			givenWalletID:              "1",
+			givenPageNo:                "0",
+			givenPageSize:              "0",
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{wallet.ErrPageSizeOutOfRange.Error()},
+		},
+		{
+			desc:                       "pageSize above maximum, return error",
+			givenWalletID:              "1",
+			givenPageNo:                "0",
+			givenPageSize:              "101",
+			expectedResponseStatusCode: 400,
+			expectedResponseBody:       httpErr{wallet.ErrPageSizeOutOfRange.Error()},
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -531,7 +833,7 @@ func TestHandlerGetTransactionsInvalidPaginationParams(t *testing.T) {
 				tC.givenPageSize,
 			)
 
-			res, err := testServer.Client().Get(url)
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Get(url)
 			if err != nil {
 				assert.Fail(t, err.Error())
 			}
@@ -545,3 +847,234 @@ func TestHandlerGetTransactionsInvalidPaginationParams(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerGetTransactionsPaginationLinks(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	testCases := []struct {
+		desc               string
+		givenQuery         string
+		givenPageNo        int
+		givenPageSize      int
+		givenEstimateTotal bool
+		mockWSPage         *wallet.TransactionPage
+		expectedPageInfo   wallet.PageInfo
+		expectedLinks      wallet.PageLinks
+	}{
+		{
+			desc:          "middle page, return all links",
+			givenQuery:    "pageNo=1&pageSize=2",
+			givenPageNo:   1,
+			givenPageSize: 2,
+			mockWSPage: &wallet.TransactionPage{
+				Transactions: []*transaction.Transaction{{ID: "3"}, {ID: "4"}},
+				Total:        5,
+			},
+			expectedPageInfo: wallet.PageInfo{PageNo: 1, PageSize: 2, TotalPages: intPtr(3)},
+			expectedLinks: wallet.PageLinks{
+				Self:  "/wallets/1/transactions?pageNo=1&pageSize=2",
+				First: "/wallets/1/transactions?pageNo=0&pageSize=2",
+				Prev:  "/wallets/1/transactions?pageNo=0&pageSize=2",
+				Next:  "/wallets/1/transactions?pageNo=2&pageSize=2",
+				Last:  "/wallets/1/transactions?pageNo=2&pageSize=2",
+			},
+		},
+		{
+			desc:               "estimated total, omit last link and total pages",
+			givenQuery:         "pageNo=0&pageSize=2&estimateTotal=true",
+			givenPageNo:        0,
+			givenPageSize:      2,
+			givenEstimateTotal: true,
+			mockWSPage: &wallet.TransactionPage{
+				Transactions:   []*transaction.Transaction{{ID: "1"}, {ID: "2"}},
+				Total:          int64(wallet.EstimatedTotalLimit),
+				TotalEstimated: true,
+			},
+			expectedPageInfo: wallet.PageInfo{PageNo: 0, PageSize: 2},
+			expectedLinks: wallet.PageLinks{
+				Self:  "/wallets/1/transactions?estimateTotal=true&pageNo=0&pageSize=2",
+				First: "/wallets/1/transactions?estimateTotal=true&pageNo=0&pageSize=2",
+				Next:  "/wallets/1/transactions?estimateTotal=true&pageNo=1&pageSize=2",
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockWalletService.EXPECT().
+				GetTransactions(gomock.Any(), "1", "", tC.givenPageNo, tC.givenPageSize, tC.givenEstimateTotal).
+				Return(tC.mockWSPage, nil)
+
+			res, err := newAuthenticatedClient("1", auth.RoleUser).Get(fmt.Sprintf("%s/wallets/1/transactions?%s", testServer.URL, tC.givenQuery))
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			var resBody wallet.PaginatedResponse
+			_ = json.NewDecoder(res.Body).Decode(&resBody)
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tC.mockWSPage.Total, resBody.Total)
+			assert.Equal(t, tC.mockWSPage.TotalEstimated, resBody.TotalEstimated)
+			assert.Equal(t, tC.expectedPageInfo, resBody.Page)
+			assert.Equal(t, tC.expectedLinks, resBody.Links)
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestHandlerRequireOwner(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	e.Use(h.RequireOwner)
+	h.RegisterRoutes(e)
+	e.GET("/wallets/:id/statement", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	ownedWallet := &wallet.Wallet{ID: "1", UserID: "1"}
+
+	testCases := []struct {
+		desc                       string
+		givenUserID                string
+		givenPath                  string
+		mockWSWallet               *wallet.Wallet
+		mockWSError                error
+		expectedResponseStatusCode int
+	}{
+		{
+			desc:                       "user owns the wallet, return wallet",
+			givenUserID:                "1",
+			givenPath:                  "/wallets/1",
+			mockWSWallet:               ownedWallet,
+			expectedResponseStatusCode: 200,
+		},
+		{
+			desc:                       "user owns the wallet, allow routes of other packages",
+			givenUserID:                "1",
+			givenPath:                  "/wallets/1/statement",
+			mockWSWallet:               ownedWallet,
+			expectedResponseStatusCode: 200,
+		},
+		{
+			desc:                       "wallet belongs to another user, return not found",
+			givenUserID:                "2",
+			givenPath:                  "/wallets/1",
+			mockWSWallet:               ownedWallet,
+			expectedResponseStatusCode: 404,
+		},
+		{
+			desc:                       "wallet belongs to another user, hide routes of other packages",
+			givenUserID:                "2",
+			givenPath:                  "/wallets/1/statement",
+			mockWSWallet:               ownedWallet,
+			expectedResponseStatusCode: 404,
+		},
+		{
+			desc:                       "wallet does not exist, return not found",
+			givenUserID:                "1",
+			givenPath:                  "/wallets/1",
+			mockWSError:                wallet.ErrWalletNotFound,
+			expectedResponseStatusCode: 404,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockWalletService.EXPECT().
+				GetWallet(gomock.Any(), "1").
+				Return(tC.mockWSWallet, tC.mockWSError)
+			if tC.expectedResponseStatusCode == 200 && tC.givenPath == "/wallets/1" {
+				mockWalletService.EXPECT().
+					GetWallet(gomock.Any(), "1").
+					Return(tC.mockWSWallet, tC.mockWSError)
+			}
+
+			res, err := newAuthenticatedClient(tC.givenUserID, auth.RoleUser).Get(testServer.URL + tC.givenPath)
+			if err != nil {
+				assert.Fail(t, err.Error())
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tC.expectedResponseStatusCode, res.StatusCode)
+			if tC.expectedResponseStatusCode == 404 {
+				resBodyBytes, _ := io.ReadAll(res.Body)
+				expectedResBodyBytes, _ := json.Marshal(httpErr{wallet.ErrWalletNotFound.Error()})
+				assert.JSONEq(t, string(expectedResBodyBytes), string(resBodyBytes))
+			}
+		})
+	}
+}
+
+func TestHandlerRequireOwnerWithAnyWalletScope(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	useJWT(e)
+	e.Use(h.RequireOwner)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	mockWalletService.EXPECT().
+		GetWallet(gomock.Any(), "1").
+		Return(&wallet.Wallet{ID: "1", UserID: "1"}, nil)
+
+	res, err := newAuthenticatedClient("2", auth.RoleSupport).Get(fmt.Sprintf("%s/wallets/1", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestHandlerRequireOwnerWithWalletIDs(t *testing.T) {
+	mockWalletService := createMockWalletService(t)
+	h := wallet.NewHandler(mockWalletService)
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetClaims(c, &auth.Claims{Scopes: []string{auth.ScopeWalletsRead}, WalletIDs: []string{"1"}})
+			return next(c)
+		}
+	})
+	e.Use(h.RequireOwner)
+	h.RegisterRoutes(e)
+	testServer := httptest.NewServer(e.Server.Handler)
+	defer testServer.Close()
+
+	mockWalletService.EXPECT().
+		GetWallet(gomock.Any(), "1").
+		Return(&wallet.Wallet{ID: "1", UserID: "1"}, nil)
+
+	res, err := testServer.Client().Get(fmt.Sprintf("%s/wallets/1", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = testServer.Client().Get(fmt.Sprintf("%s/wallets/2", testServer.URL))
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 404, res.StatusCode)
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Memory keeps wallets in the process, for local development and tests. It
+// hands out copies so that callers cannot change stored wallets.
+type Memory struct {
+	mu           sync.RWMutex
+	wallets      map[string]*wallet.Wallet
+	transactions wallet.TransactionInserter
+}
+
+func NewMemory(transactions wallet.TransactionInserter) *Memory {
+	return &Memory{wallets: make(map[string]*wallet.Wallet), transactions: transactions}
+}
+
+// Create gives wallets ids in the same format as the mongo backend, which
+// other parts of the API, like bank imports, expect.
+func (m *Memory) Create(ctx context.Context, w *wallet.Wallet) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.wallets {
+		if existing.UserID == w.UserID {
+			return "", wallet.ErrWalletWithUserIDExists
+		}
+	}
+
+	stored := *w
+	stored.ID = primitive.NewObjectID().Hex()
+	m.wallets[stored.ID] = &stored
+
+	return stored.ID, nil
+}
+
+func (m *Memory) Read(ctx context.Context, id string) (*wallet.Wallet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	w, ok := m.wallets[id]
+	if !ok {
+		return nil, wallet.ErrWalletNotFound
+	}
+
+	found := *w
+	return &found, nil
+}
+
+func (m *Memory) ReadByUserID(ctx context.Context, userID string) (*wallet.Wallet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, w := range m.wallets {
+		if w.UserID == userID {
+			found := *w
+			return &found, nil
+		}
+	}
+
+	return nil, wallet.ErrWalletNotFound
+}
+
+func (m *Memory) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.wallets, id)
+	return nil
+}
+
+// ApplyTransaction stores the transaction while holding the lock, so that no
+// other change to the wallet comes in between.
+func (m *Memory) ApplyTransaction(ctx context.Context, w *wallet.Wallet, txn *transaction.Transaction) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.wallets[w.ID]
+	if !ok || stored.Balance != w.Balance || !stored.LastTransactionAt.Equal(w.LastTransactionAt) {
+		return "", wallet.ErrWalletBalanceUpdateFailed
+	}
+
+	if txn.ID == "" {
+		txn.ID = primitive.NewObjectID().Hex()
+	}
+	txn.WalletID = w.ID
+	txn.CreatedAt = wallet.TransactionTime(stored.LastTransactionAt)
+	if err := m.transactions.Insert(ctx, []*transaction.Transaction{txn}); err != nil {
+		return "", err
+	}
+
+	stored.Balance = txn.BalanceAfter
+	stored.LastTransactionAt = txn.CreatedAt
+
+	return txn.ID, nil
+}
+
+func (m *Memory) UpdateLimits(ctx context.Context, id string, balanceUpperLimit, transactionUpperLimit float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, ok := m.wallets[id]
+	if !ok {
+		return wallet.ErrWalletNotFound
+	}
+	w.BalanceUpperLimit = balanceUpperLimit
+	w.TransactionUpperLimit = transactionUpperLimit
+
+	return nil
+}
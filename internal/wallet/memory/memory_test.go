@@ -0,0 +1,18 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	transactionMemory "github.com/gokcelb/wallet-api/internal/transaction/memory"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/memory"
+	"github.com/gokcelb/wallet-api/internal/wallet/wallettest"
+)
+
+func TestMemory(t *testing.T) {
+	wallettest.TestRepository(t, func(t *testing.T) (wallet.WalletRepository, transaction.TransactionRepository) {
+		transactions := transactionMemory.NewMemory()
+		return memory.NewMemory(transactions), transactions
+	})
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gokcelb/wallet-api/internal/wallet (interfaces: ChallengeService)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	challenge "github.com/gokcelb/wallet-api/internal/challenge"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockChallengeService is a mock of ChallengeService interface.
+type MockChallengeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChallengeServiceMockRecorder
+}
+
+// MockChallengeServiceMockRecorder is the mock recorder for MockChallengeService.
+type MockChallengeServiceMockRecorder struct {
+	mock *MockChallengeService
+}
+
+// NewMockChallengeService creates a new mock instance.
+func NewMockChallengeService(ctrl *gomock.Controller) *MockChallengeService {
+	mock := &MockChallengeService{ctrl: ctrl}
+	mock.recorder = &MockChallengeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChallengeService) EXPECT() *MockChallengeServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateChallenge mocks base method.
+func (m *MockChallengeService) CreateChallenge(arg0 context.Context, arg1, arg2 string, arg3 float64) (*challenge.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChallenge", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*challenge.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChallenge indicates an expected call of CreateChallenge.
+func (mr *MockChallengeServiceMockRecorder) CreateChallenge(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChallenge", reflect.TypeOf((*MockChallengeService)(nil).CreateChallenge), arg0, arg1, arg2, arg3)
+}
+
+// ReleaseChallenge mocks base method.
+func (m *MockChallengeService) ReleaseChallenge(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseChallenge indicates an expected call of ReleaseChallenge.
+func (mr *MockChallengeServiceMockRecorder) ReleaseChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseChallenge", reflect.TypeOf((*MockChallengeService)(nil).ReleaseChallenge), arg0, arg1)
+}
+
+// VerifyChallenge mocks base method.
+func (m *MockChallengeService) VerifyChallenge(arg0 context.Context, arg1, arg2, arg3 string) (*challenge.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChallenge", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*challenge.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChallenge indicates an expected call of VerifyChallenge.
+func (mr *MockChallengeServiceMockRecorder) VerifyChallenge(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChallenge", reflect.TypeOf((*MockChallengeService)(nil).VerifyChallenge), arg0, arg1, arg2, arg3)
+}
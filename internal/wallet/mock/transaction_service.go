@@ -35,19 +35,34 @@ func (m *MockTransactionService) EXPECT() *MockTransactionServiceMockRecorder {
 	return m.recorder
 }
 
-// CreateTransaction mocks base method.
-func (m *MockTransactionService) CreateTransaction(arg0 context.Context, arg1 *transaction.Transaction) (string, error) {
+// CountTransactionsByWalletID mocks base method.
+func (m *MockTransactionService) CountTransactionsByWalletID(arg0 context.Context, arg1, arg2 string, arg3 int) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransaction", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "CountTransactionsByWalletID", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransaction indicates an expected call of CreateTransaction.
-func (mr *MockTransactionServiceMockRecorder) CreateTransaction(arg0, arg1 interface{}) *gomock.Call {
+// CountTransactionsByWalletID indicates an expected call of CountTransactionsByWalletID.
+func (mr *MockTransactionServiceMockRecorder) CountTransactionsByWalletID(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockTransactionService)(nil).CreateTransaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTransactionsByWalletID", reflect.TypeOf((*MockTransactionService)(nil).CountTransactionsByWalletID), arg0, arg1, arg2, arg3)
+}
+
+// GetTransaction mocks base method.
+func (m *MockTransactionService) GetTransaction(arg0 context.Context, arg1 string) (*transaction.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*transaction.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockTransactionServiceMockRecorder) GetTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockTransactionService)(nil).GetTransaction), arg0, arg1)
 }
 
 // GetTransactionsByWalletID mocks base method.
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	transaction "github.com/gokcelb/wallet-api/internal/transaction"
 	wallet "github.com/gokcelb/wallet-api/internal/wallet"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,21 @@ func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyTransaction mocks base method.
+func (m *MockWalletRepository) ApplyTransaction(arg0 context.Context, arg1 *wallet.Wallet, arg2 *transaction.Transaction) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyTransaction indicates an expected call of ApplyTransaction.
+func (mr *MockWalletRepositoryMockRecorder) ApplyTransaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyTransaction", reflect.TypeOf((*MockWalletRepository)(nil).ApplyTransaction), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockWalletRepository) Create(arg0 context.Context, arg1 *wallet.Wallet) (string, error) {
 	m.ctrl.T.Helper()
@@ -94,16 +110,16 @@ func (mr *MockWalletRepositoryMockRecorder) ReadByUserID(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUserID", reflect.TypeOf((*MockWalletRepository)(nil).ReadByUserID), arg0, arg1)
 }
 
-// UpdateBalance mocks base method.
-func (m *MockWalletRepository) UpdateBalance(arg0 context.Context, arg1 string, arg2 float64) error {
+// UpdateLimits mocks base method.
+func (m *MockWalletRepository) UpdateLimits(arg0 context.Context, arg1 string, arg2, arg3 float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateLimits", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateBalance indicates an expected call of UpdateBalance.
-func (mr *MockWalletRepositoryMockRecorder) UpdateBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+// UpdateLimits indicates an expected call of UpdateLimits.
+func (mr *MockWalletRepositoryMockRecorder) UpdateLimits(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWalletRepository)(nil).UpdateBalance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimits", reflect.TypeOf((*MockWalletRepository)(nil).UpdateLimits), arg0, arg1, arg2, arg3)
 }
@@ -8,7 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	transaction "github.com/gokcelb/wallet-api/internal/transaction"
+	challenge "github.com/gokcelb/wallet-api/internal/challenge"
 	wallet "github.com/gokcelb/wallet-api/internal/wallet"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +36,36 @@ func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
 	return m.recorder
 }
 
+// ChallengeTransaction mocks base method.
+func (m *MockWalletService) ChallengeTransaction(arg0 context.Context, arg1 *wallet.TransactionCreationInfo) (*challenge.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChallengeTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*challenge.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChallengeTransaction indicates an expected call of ChallengeTransaction.
+func (mr *MockWalletServiceMockRecorder) ChallengeTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChallengeTransaction", reflect.TypeOf((*MockWalletService)(nil).ChallengeTransaction), arg0, arg1)
+}
+
+// CompleteTransaction mocks base method.
+func (m *MockWalletService) CompleteTransaction(arg0 context.Context, arg1 *wallet.ChallengeCompletionInfo) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTransaction", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTransaction indicates an expected call of CompleteTransaction.
+func (mr *MockWalletServiceMockRecorder) CompleteTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTransaction", reflect.TypeOf((*MockWalletService)(nil).CompleteTransaction), arg0, arg1)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockWalletService) CreateTransaction(arg0 context.Context, arg1 *wallet.TransactionCreationInfo) (string, error) {
 	m.ctrl.T.Helper()
@@ -81,18 +111,18 @@ func (mr *MockWalletServiceMockRecorder) DeleteWallet(arg0, arg1 interface{}) *g
 }
 
 // GetTransactions mocks base method.
-func (m *MockWalletService) GetTransactions(arg0 context.Context, arg1, arg2 string, arg3, arg4 int) ([]*transaction.Transaction, error) {
+func (m *MockWalletService) GetTransactions(arg0 context.Context, arg1, arg2 string, arg3, arg4 int, arg5 bool) (*wallet.TransactionPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransactions", arg0, arg1, arg2, arg3, arg4)
-	ret0, _ := ret[0].([]*transaction.Transaction)
+	ret := m.ctrl.Call(m, "GetTransactions", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*wallet.TransactionPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTransactions indicates an expected call of GetTransactions.
-func (mr *MockWalletServiceMockRecorder) GetTransactions(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockWalletServiceMockRecorder) GetTransactions(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // GetWallet mocks base method.
@@ -109,3 +139,17 @@ func (mr *MockWalletServiceMockRecorder) GetWallet(arg0, arg1 interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletService)(nil).GetWallet), arg0, arg1)
 }
+
+// UpdateLimits mocks base method.
+func (m *MockWalletService) UpdateLimits(arg0 context.Context, arg1 *wallet.LimitsUpdateInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLimits", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLimits indicates an expected call of UpdateLimits.
+func (mr *MockWalletServiceMockRecorder) UpdateLimits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimits", reflect.TypeOf((*MockWalletService)(nil).UpdateLimits), arg0, arg1)
+}
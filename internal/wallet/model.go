@@ -1,9 +1,35 @@
 package wallet
 
+import (
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+)
+
 type Wallet struct {
 	ID                    string
 	UserID                string
 	Balance               float64
 	BalanceUpperLimit     float64
 	TransactionUpperLimit float64
+	LastTransactionAt     time.Time
+}
+
+// TransactionTime is the creation time of the next transaction of a wallet
+// whose last one was created at last. It is always after last, so that the
+// transactions of a wallet sort in the order their balance changes were
+// applied, and in milliseconds, which is all mongo keeps.
+func TransactionTime(last time.Time) time.Time {
+	now := time.Now().Truncate(time.Millisecond)
+	if !now.After(last) {
+		return last.Add(time.Millisecond)
+	}
+
+	return now
+}
+
+type TransactionPage struct {
+	Transactions   []*transaction.Transaction
+	Total          int64
+	TotalEstimated bool
 }
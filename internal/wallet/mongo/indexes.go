@@ -0,0 +1,22 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The unique user_id index keeps a user from ending up with two wallets when
+// they create them concurrently.
+var indexes = []mongo.IndexModel{
+	{Keys: bson.D{bson.E{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+// CreateIndexes creates the indexes the queries rely on. Mongo leaves the
+// ones that already exist alone.
+func (m *Mongo) CreateIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
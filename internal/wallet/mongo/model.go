@@ -1,6 +1,20 @@
 package mongo
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// schemaVersion is the version of the wallet documents we write. Documents
+// written before there were versions have none, and the same fields as
+// version 1.
+const schemaVersion = 1
+
+// ErrUnsupportedSchemaVersion is returned for documents written by a newer
+// version of the API, until this one is upgraded.
+var ErrUnsupportedSchemaVersion = errors.New("wallet document has a newer schema version")
 
 type mongoWallet struct {
 	ID                    primitive.ObjectID `bson:"_id"`
@@ -8,4 +22,18 @@ type mongoWallet struct {
 	Balance               float64            `bson:"balance"`
 	BalanceUpperLimit     float64            `bson:"balance_upper_limit"`
 	TransactionUpperLimit float64            `bson:"transaction_upper_limit"`
+	LastTransactionAt     time.Time          `bson:"last_transaction_at"`
+	// PendingTransactions are applied to the balance but maybe not stored in
+	// the transactions collection yet.
+	PendingTransactions []mongoPendingTransaction `bson:"pending_transactions,omitempty"`
+	SchemaVersion       int                       `bson:"schema_version"`
+}
+
+type mongoPendingTransaction struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Type          string             `bson:"type"`
+	Amount        float64            `bson:"amount"`
+	BalanceBefore float64            `bson:"balance_before"`
+	BalanceAfter  float64            `bson:"balance_after"`
+	CreatedAt     time.Time          `bson:"created_at"`
 }
@@ -3,7 +3,10 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/gokcelb/wallet-api/internal/transaction"
 	"github.com/gokcelb/wallet-api/internal/wallet"
 	"github.com/labstack/gommon/log"
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,18 +14,26 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const defaultFlushInterval = time.Minute
+
 type Mongo struct {
-	collection *mongo.Collection
+	collection   *mongo.Collection
+	transactions wallet.TransactionInserter
+	// flushFailed wakes RunFlush up when ApplyTransaction couldn't store a
+	// transaction.
+	flushFailed chan struct{}
 }
 
-func NewMongo(collection *mongo.Collection) *Mongo {
-	return &Mongo{collection}
+func NewMongo(collection *mongo.Collection, transactions wallet.TransactionInserter) *Mongo {
+	return &Mongo{collection, transactions, make(chan struct{}, 1)}
 }
 
-func (m *Mongo) Create(ctx context.Context, wallet *wallet.Wallet) (string, error) {
-	mongoWallet := newMongoWalletFromWallet(wallet)
+func (m *Mongo) Create(ctx context.Context, w *wallet.Wallet) (string, error) {
+	mongoWallet := newMongoWalletFromWallet(w)
 	result, err := m.collection.InsertOne(ctx, mongoWallet)
-	if err != nil {
+	if mongo.IsDuplicateKeyError(err) {
+		return "", wallet.ErrWalletWithUserIDExists
+	} else if err != nil {
 		log.Error(err)
 		return "", err
 	}
@@ -33,8 +44,7 @@ func (m *Mongo) Create(ctx context.Context, wallet *wallet.Wallet) (string, erro
 func (m *Mongo) Read(ctx context.Context, id string) (*wallet.Wallet, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return nil, wallet.ErrWalletNotFound
 	}
 
 	var mongoWallet mongoWallet
@@ -45,7 +55,7 @@ func (m *Mongo) Read(ctx context.Context, id string) (*wallet.Wallet, error) {
 		return nil, err
 	}
 
-	return newWalletFromMongoWallet(&mongoWallet), nil
+	return newWalletFromMongoWallet(&mongoWallet)
 }
 
 func (m *Mongo) ReadByUserID(ctx context.Context, userID string) (*wallet.Wallet, error) {
@@ -57,28 +67,193 @@ func (m *Mongo) ReadByUserID(ctx context.Context, userID string) (*wallet.Wallet
 		return nil, err
 	}
 
-	return newWalletFromMongoWallet(&mongoWallet), nil
+	return newWalletFromMongoWallet(&mongoWallet)
 }
 
 func (m *Mongo) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil
+	}
+
+	_, err = m.collection.DeleteOne(ctx, primitive.M{"_id": objectID})
+	return err
+}
+
+// ApplyTransaction pushes the transaction onto the wallet in the same update
+// as the balance, then moves it to the transactions collection. If that
+// fails, the transaction stays pending on the wallet until RunFlush or
+// FlushPendingTransactions stores it.
+func (m *Mongo) ApplyTransaction(ctx context.Context, w *wallet.Wallet, txn *transaction.Transaction) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(w.ID)
+	if err != nil {
+		return "", wallet.ErrWalletBalanceUpdateFailed
+	}
+
+	pendingID := primitive.NewObjectID()
+	if txn.ID != "" {
+		if pendingID, err = primitive.ObjectIDFromHex(txn.ID); err != nil {
+			return "", err
+		}
+	}
+
+	pending := mongoPendingTransaction{
+		ID:            pendingID,
+		Type:          txn.Type,
+		Amount:        txn.Amount,
+		BalanceBefore: txn.BalanceBefore,
+		BalanceAfter:  txn.BalanceAfter,
+		CreatedAt:     wallet.TransactionTime(w.LastTransactionAt),
+	}
+
+	filter := bson.M{
+		"_id":     objectID,
+		"balance": w.Balance,
+		// Wallets that never had a transaction applied may not have the field.
+		"last_transaction_at": bson.M{"$in": bson.A{w.LastTransactionAt, nil}},
+	}
+	if !w.LastTransactionAt.IsZero() {
+		filter["last_transaction_at"] = w.LastTransactionAt
+	}
+	if txn.ID != "" {
+		// The transaction may have been applied and not stored yet.
+		filter["pending_transactions._id"] = bson.M{"$ne": pending.ID}
+	}
+	update := bson.M{
+		"$set":  bson.M{"balance": txn.BalanceAfter, "last_transaction_at": pending.CreatedAt},
+		"$push": bson.M{"pending_transactions": pending},
+	}
+	result, err := m.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	if result.MatchedCount == 0 {
+		return "", m.applyFailure(ctx, objectID, pending.ID)
+	}
+
+	txn.ID = pending.ID.Hex()
+	txn.WalletID = w.ID
+	txn.CreatedAt = pending.CreatedAt
+	if err = m.flush(ctx, objectID, []mongoPendingTransaction{pending}); err != nil {
+		log.Error(err)
+		select {
+		case m.flushFailed <- struct{}{}:
+		default:
+		}
+	}
+
+	return txn.ID, nil
+}
+
+// applyFailure tells a transaction still pending on the wallet from a
+// wallet that changed.
+func (m *Mongo) applyFailure(ctx context.Context, walletID, pendingID primitive.ObjectID) error {
+	n, err := m.collection.CountDocuments(ctx, bson.M{"_id": walletID, "pending_transactions._id": pendingID})
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	_, err = m.collection.DeleteOne(ctx, primitive.M{"_id": objectID})
+	if n > 0 {
+		return wallet.ErrDuplicateTransaction
+	}
+
+	return wallet.ErrWalletBalanceUpdateFailed
+}
+
+// RunFlush stores the pending transactions every interval, and right after
+// ApplyTransaction fails to, until ctx is done. This keeps them from missing
+// from the transaction reads until the next startup.
+func (m *Mongo) RunFlush(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.flushFailed:
+		}
+
+		if err := m.FlushPendingTransactions(ctx); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// FlushPendingTransactions stores the transactions left pending on wallets by
+// a failure between updating a balance and storing its transaction.
+func (m *Mongo) FlushPendingTransactions(ctx context.Context) error {
+	cursor, err := m.collection.Find(ctx, bson.M{"pending_transactions.0": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mongoWallet mongoWallet
+		if err = cursor.Decode(&mongoWallet); err != nil {
+			return err
+		}
+
+		// The pending transactions of newer documents are left to the
+		// instances that can read them.
+		if mongoWallet.SchemaVersion > schemaVersion {
+			continue
+		}
+
+		if err = m.flush(ctx, mongoWallet.ID, mongoWallet.PendingTransactions); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (m *Mongo) flush(ctx context.Context, walletID primitive.ObjectID, pending []mongoPendingTransaction) error {
+	txns := []*transaction.Transaction{}
+	ids := bson.A{}
+	for _, p := range pending {
+		txns = append(txns, newTransactionFromMongoPendingTransaction(walletID, &p))
+		ids = append(ids, p.ID)
+	}
+
+	if err := m.transactions.Insert(ctx, txns); err != nil {
+		return err
+	}
+
+	update := bson.M{"$pull": bson.M{"pending_transactions": bson.M{"_id": bson.M{"$in": ids}}}}
+	_, err := m.collection.UpdateOne(ctx, bson.M{"_id": walletID}, update)
 	return err
 }
 
-func (m *Mongo) UpdateBalance(ctx context.Context, id string, newBalance float64) error {
+func (m *Mongo) UpdateLimits(ctx context.Context, id string, balanceUpperLimit, transactionUpperLimit float64) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return wallet.ErrWalletNotFound
+	}
+
+	update := bson.M{"$set": bson.M{
+		"balance_upper_limit":     balanceUpperLimit,
+		"transaction_upper_limit": transactionUpperLimit,
+	}}
+	result, err := m.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	if err != nil {
 		log.Error(err)
+		return err
 	}
 
-	_, err = m.collection.UpdateByID(ctx, objectID, bson.M{"$set": bson.M{"balance": newBalance}})
-	return err
+	if result.MatchedCount == 0 {
+		return wallet.ErrWalletNotFound
+	}
+
+	return nil
 }
 
 func newMongoWalletFromWallet(wallet *wallet.Wallet) *mongoWallet {
@@ -88,15 +263,35 @@ func newMongoWalletFromWallet(wallet *wallet.Wallet) *mongoWallet {
 		Balance:               wallet.Balance,
 		BalanceUpperLimit:     wallet.BalanceUpperLimit,
 		TransactionUpperLimit: wallet.TransactionUpperLimit,
+		SchemaVersion:         schemaVersion,
 	}
 }
 
-func newWalletFromMongoWallet(mongoWallet *mongoWallet) *wallet.Wallet {
+// newWalletFromMongoWallet rejects documents of a newer schema, which this
+// version may not read correctly.
+func newWalletFromMongoWallet(mongoWallet *mongoWallet) (*wallet.Wallet, error) {
+	if mongoWallet.SchemaVersion > schemaVersion {
+		return nil, fmt.Errorf("%w: wallet %s has version %d", ErrUnsupportedSchemaVersion, mongoWallet.ID.Hex(), mongoWallet.SchemaVersion)
+	}
+
 	return &wallet.Wallet{
 		ID:                    mongoWallet.ID.Hex(),
 		UserID:                mongoWallet.UserID,
 		Balance:               mongoWallet.Balance,
 		BalanceUpperLimit:     mongoWallet.BalanceUpperLimit,
 		TransactionUpperLimit: mongoWallet.TransactionUpperLimit,
+		LastTransactionAt:     mongoWallet.LastTransactionAt,
+	}, nil
+}
+
+func newTransactionFromMongoPendingTransaction(walletID primitive.ObjectID, pending *mongoPendingTransaction) *transaction.Transaction {
+	return &transaction.Transaction{
+		ID:            pending.ID.Hex(),
+		WalletID:      walletID.Hex(),
+		Type:          pending.Type,
+		Amount:        pending.Amount,
+		BalanceBefore: pending.BalanceBefore,
+		BalanceAfter:  pending.BalanceAfter,
+		CreatedAt:     pending.CreatedAt,
 	}
 }
@@ -0,0 +1,135 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	transactionMongo "github.com/gokcelb/wallet-api/internal/transaction/mongo"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/mongo"
+	"github.com/gokcelb/wallet-api/internal/wallet/wallettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMongo needs a server at MONGO_TEST_URI and is skipped without one.
+func TestMongo(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	wallettest.TestRepository(t, func(t *testing.T) (wallet.WalletRepository, transaction.TransactionRepository) {
+		db := client.Database("wallet-api-test")
+		collection := db.Collection(primitive.NewObjectID().Hex())
+		transactionCollection := db.Collection(primitive.NewObjectID().Hex())
+		t.Cleanup(func() {
+			collection.Drop(context.TODO())
+			transactionCollection.Drop(context.TODO())
+		})
+
+		transactions := transactionMongo.NewMongo(transactionCollection)
+		m := mongo.NewMongo(collection, transactions)
+		require.NoError(t, m.CreateIndexes(context.TODO()))
+
+		return m, transactions
+	})
+}
+
+func TestMongoNewerSchemaVersion(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	collection := client.Database("wallet-api-test").Collection(primitive.NewObjectID().Hex())
+	defer collection.Drop(context.TODO())
+
+	id := primitive.NewObjectID()
+	_, err = collection.InsertOne(context.TODO(), bson.M{"_id": id, "user_id": "1", "balance": 10, "schema_version": 2})
+	require.NoError(t, err)
+
+	m := mongo.NewMongo(collection, nil)
+
+	w, err := m.Read(context.TODO(), id.Hex())
+
+	assert.Nil(t, w)
+	assert.ErrorIs(t, err, mongo.ErrUnsupportedSchemaVersion)
+
+	w, err = m.ReadByUserID(context.TODO(), "1")
+
+	assert.Nil(t, w)
+	assert.ErrorIs(t, err, mongo.ErrUnsupportedSchemaVersion)
+}
+
+type flakyInserter struct {
+	mu       sync.Mutex
+	failures int
+	inserted []*transaction.Transaction
+}
+
+func (f *flakyInserter) Insert(ctx context.Context, txns []*transaction.Transaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("insert failed")
+	}
+	f.inserted = append(f.inserted, txns...)
+	return nil
+}
+
+func (f *flakyInserter) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.inserted)
+}
+
+func TestMongoRunFlushRetriesFailedFlush(t *testing.T) {
+	uri, ok := os.LookupEnv("MONGO_TEST_URI")
+	if !ok {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	client, err := mongoDriver.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(context.TODO())
+
+	collection := client.Database("wallet-api-test").Collection(primitive.NewObjectID().Hex())
+	defer collection.Drop(context.TODO())
+
+	transactions := &flakyInserter{failures: 1}
+	m := mongo.NewMongo(collection, transactions)
+	id, err := m.Create(context.TODO(), &wallet.Wallet{UserID: "1"})
+	require.NoError(t, err)
+
+	txn := &transaction.Transaction{Type: wallet.Deposit, Amount: 10, BalanceAfter: 10}
+	_, err = m.ApplyTransaction(context.TODO(), &wallet.Wallet{ID: id}, txn)
+	require.NoError(t, err)
+	assert.Equal(t, 0, transactions.count())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go m.RunFlush(ctx, time.Hour)
+
+	assert.Eventually(t, func() bool { return transactions.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
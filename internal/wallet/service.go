@@ -5,7 +5,9 @@ import (
 	"errors"
 
 	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/challenge"
 	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/labstack/gommon/log"
 )
 
 const (
@@ -13,6 +15,11 @@ const (
 	Withdrawal string = "withdrawal"
 )
 
+const (
+	EstimatedTotalLimit      = 1000
+	MaxBalanceUpdateAttempts = 3
+)
+
 var (
 	ErrWalletNotFound               = errors.New("no wallet with the given id exists")
 	ErrWalletWithUserIDExists       = errors.New("wallet with user id already exists")
@@ -22,6 +29,9 @@ var (
 	ErrInvalidTransactionType       = errors.New("transaction type is invalid")
 	ErrInsufficientBalance          = errors.New("balance is insufficient")
 	ErrWalletBalanceUpdateFailed    = errors.New("wallet balance could not be updated")
+	ErrBalanceAboveUpperLimit       = errors.New("wallet balance is above the given balance upper limit")
+	ErrStepUpRequired               = errors.New("transaction needs to be verified with a one-time code")
+	ErrDuplicateTransaction         = errors.New("transaction with the given id was already applied")
 )
 
 type WalletRepository interface {
@@ -29,22 +39,50 @@ type WalletRepository interface {
 	Read(ctx context.Context, id string) (*Wallet, error)
 	ReadByUserID(ctx context.Context, userID string) (*Wallet, error)
 	Delete(ctx context.Context, id string) error
-	UpdateBalance(ctx context.Context, id string, newBalance float64) error
+	// ApplyTransaction moves the balance of w to the balance after txn and
+	// stores txn, both or neither, setting its id, wallet id and creation
+	// time. It fails with ErrWalletBalanceUpdateFailed when the wallet
+	// changed since w was read. An id already set on txn is kept, and
+	// repositories that hold transactions back before storing them fail
+	// with ErrDuplicateTransaction when one with that id is held back.
+	ApplyTransaction(ctx context.Context, w *Wallet, txn *transaction.Transaction) (string, error)
+	UpdateLimits(ctx context.Context, id string, balanceUpperLimit, transactionUpperLimit float64) error
+}
+
+// freshReader is implemented by repositories that may serve stale wallets
+// from Read.
+type freshReader interface {
+	ReadFresh(ctx context.Context, id string) (*Wallet, error)
+}
+
+// TransactionInserter stores transactions that already have an id and a
+// creation time, for the wallet repositories to store the transactions they
+// apply.
+type TransactionInserter interface {
+	Insert(ctx context.Context, txns []*transaction.Transaction) error
 }
 
 type TransactionService interface {
-	CreateTransaction(ctx context.Context, txn *transaction.Transaction) (string, error)
+	GetTransaction(ctx context.Context, id string) (*transaction.Transaction, error)
 	GetTransactionsByWalletID(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error)
+	CountTransactionsByWalletID(ctx context.Context, walletID, typeFilter string, limit int) (int64, error)
+}
+
+type ChallengeService interface {
+	CreateChallenge(ctx context.Context, walletID, txnType string, amount float64) (*challenge.Challenge, error)
+	VerifyChallenge(ctx context.Context, id, walletID, code string) (*challenge.Challenge, error)
+	ReleaseChallenge(ctx context.Context, id string) error
 }
 
 type service struct {
 	wr   WalletRepository
 	ts   TransactionService
+	cs   ChallengeService
 	conf config.Conf
 }
 
-func NewService(wr WalletRepository, ts TransactionService, conf config.Conf) *service {
-	return &service{wr, ts, conf}
+func NewService(wr WalletRepository, ts TransactionService, cs ChallengeService, conf config.Conf) *service {
+	return &service{wr, ts, cs, conf}
 }
 
 func (s *service) CreateWallet(ctx context.Context, info *WalletCreationInfo) (string, error) {
@@ -56,6 +94,7 @@ func (s *service) CreateWallet(ctx context.Context, info *WalletCreationInfo) (s
 		return "", ErrAboveMaximumTransactionLimit
 	}
 
+	// The repository rejects a second wallet too, this only saves the insert.
 	if s.checkWalletWithUserIDExists(ctx, info.UserID) {
 		return "", ErrWalletWithUserIDExists
 	}
@@ -83,25 +122,137 @@ func (s *service) DeleteWallet(ctx context.Context, id string) error {
 	return s.wr.Delete(ctx, id)
 }
 
+func (s *service) UpdateLimits(ctx context.Context, info *LimitsUpdateInfo) error {
+	if info.BalanceUpperLimit > s.conf.Wallet.MaxBalance {
+		return ErrAboveMaximumBalanceLimit
+	}
+
+	if info.TransactionUpperLimit > s.conf.Transaction.MaxAmount {
+		return ErrAboveMaximumTransactionLimit
+	}
+
+	w, err := s.readFresh(ctx, info.WalletID)
+	if err != nil {
+		return err
+	}
+
+	if w.Balance > info.BalanceUpperLimit {
+		return ErrBalanceAboveUpperLimit
+	}
+
+	return s.wr.UpdateLimits(ctx, info.WalletID, info.BalanceUpperLimit, info.TransactionUpperLimit)
+}
+
 func (s *service) CreateTransaction(ctx context.Context, info *TransactionCreationInfo) (string, error) {
 	if info.TransactionType != Deposit && info.TransactionType != Withdrawal {
 		return "", ErrInvalidTransactionType
 	}
 
-	w, err := s.wr.Read(ctx, info.WalletID)
-	if err != nil && errors.Is(err, ErrWalletNotFound) {
+	if s.needsStepUp(info) {
+		return "", ErrStepUpRequired
+	}
+
+	return s.createTransaction(ctx, info)
+}
+
+// ChallengeTransaction starts the verification of a transaction that needs a
+// step-up, sending a one-time code to the wallet owner. The transaction is
+// checked against the current balance and limits first, so that no code is
+// sent for a transaction that would be rejected anyway.
+func (s *service) ChallengeTransaction(ctx context.Context, info *TransactionCreationInfo) (*challenge.Challenge, error) {
+	if info.TransactionType != Deposit && info.TransactionType != Withdrawal {
+		return nil, ErrInvalidTransactionType
+	}
+
+	w, err := s.readFresh(ctx, info.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.processTransaction(w, info.Amount, info.TransactionType); err != nil {
+		return nil, err
+	}
+
+	return s.cs.CreateChallenge(ctx, info.WalletID, info.TransactionType, info.Amount)
+}
+
+// CompleteTransaction creates the transaction held back by a challenge once
+// the challenge is verified. The transaction takes the id of the challenge,
+// which is released again when the transaction fails, so that the code can be
+// entered again without the transaction ever being applied twice.
+func (s *service) CompleteTransaction(ctx context.Context, info *ChallengeCompletionInfo) (string, error) {
+	c, err := s.cs.VerifyChallenge(ctx, info.ChallengeID, info.WalletID, info.Code)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.createTransaction(ctx, &TransactionCreationInfo{
+		ID:              c.ID,
+		WalletID:        c.WalletID,
+		TransactionType: c.TransactionType,
+		Amount:          c.Amount,
+	})
+	if errors.Is(err, ErrDuplicateTransaction) {
+		return c.ID, nil
+	} else if err != nil {
+		if releaseErr := s.cs.ReleaseChallenge(ctx, c.ID); releaseErr != nil {
+			log.Error(releaseErr)
+		}
 		return "", err
 	}
 
-	err = s.processTransaction(ctx, w, info.Amount, info.TransactionType)
+	return id, nil
+}
+
+func (s *service) createTransaction(ctx context.Context, info *TransactionCreationInfo) (string, error) {
+	if info.ID != "" {
+		if err := s.checkNotApplied(ctx, info.ID); err != nil {
+			return "", err
+		}
+	}
+
+	var id string
+	var err error
+	for attempt := 0; attempt < MaxBalanceUpdateAttempts; attempt++ {
+		var w *Wallet
+		w, err = s.readFresh(ctx, info.WalletID)
+		if err != nil {
+			return "", err
+		}
+
+		txn := s.transactionFromTransactionCreationInfo(info)
+		txn.BalanceBefore = w.Balance
+		txn.BalanceAfter, err = s.processTransaction(w, info.Amount, info.TransactionType)
+		if err != nil {
+			return "", err
+		}
+
+		id, err = s.wr.ApplyTransaction(ctx, w, txn)
+		if !errors.Is(err, ErrWalletBalanceUpdateFailed) {
+			break
+		}
+	}
 	if err != nil {
 		return "", err
 	}
 
-	return s.ts.CreateTransaction(ctx, s.transactionFromTransactionCreationInfo(info))
+	return id, nil
+}
+
+// checkNotApplied fails with ErrDuplicateTransaction when a transaction with
+// the given id is stored already.
+func (s *service) checkNotApplied(ctx context.Context, id string) error {
+	_, err := s.ts.GetTransaction(ctx, id)
+	if err == nil {
+		return ErrDuplicateTransaction
+	} else if !errors.Is(err, transaction.ErrTransactionNotFound) {
+		return err
+	}
+
+	return nil
 }
 
-func (s *service) GetTransactions(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int) ([]*transaction.Transaction, error) {
+func (s *service) GetTransactions(ctx context.Context, walletID, typeFilter string, pageNo, pageSize int, estimateTotal bool) (*TransactionPage, error) {
 	if typeFilter != "" && typeFilter != Deposit && typeFilter != Withdrawal {
 		return nil, ErrInvalidTransactionType
 	}
@@ -111,7 +262,43 @@ func (s *service) GetTransactions(ctx context.Context, walletID, typeFilter stri
 		return nil, err
 	}
 
-	return s.ts.GetTransactionsByWalletID(ctx, walletID, typeFilter, pageNo, pageSize)
+	txns, err := s.ts.GetTransactionsByWalletID(ctx, walletID, typeFilter, pageNo, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var countLimit int
+	if estimateTotal {
+		countLimit = EstimatedTotalLimit
+	}
+
+	total, err := s.ts.CountTransactionsByWalletID(ctx, walletID, typeFilter, countLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransactionPage{
+		Transactions:   txns,
+		Total:          total,
+		TotalEstimated: estimateTotal && total >= EstimatedTotalLimit,
+	}, nil
+}
+
+// needsStepUp tells whether a withdrawal is large enough to need a one-time
+// code. A zero threshold turns step-up off.
+func (s *service) needsStepUp(info *TransactionCreationInfo) bool {
+	threshold := s.conf.StepUp.ThresholdAmount
+	return threshold > 0 && info.TransactionType == Withdrawal && info.Amount > threshold
+}
+
+// readFresh reads a wallet past any cache in front of the repository, for the
+// checks that need its current balance.
+func (s *service) readFresh(ctx context.Context, id string) (*Wallet, error) {
+	if fr, ok := s.wr.(freshReader); ok {
+		return fr.ReadFresh(ctx, id)
+	}
+
+	return s.wr.Read(ctx, id)
 }
 
 func (s *service) checkWalletWithUserIDExists(ctx context.Context, userID string) bool {
@@ -119,21 +306,21 @@ func (s *service) checkWalletWithUserIDExists(ctx context.Context, userID string
 	return w != nil && err == nil
 }
 
-func (s *service) processTransaction(ctx context.Context, w *Wallet, txnAmount float64, txnType string) error {
+func (s *service) processTransaction(w *Wallet, txnAmount float64, txnType string) (float64, error) {
 	if txnAmount > w.TransactionUpperLimit {
-		return ErrAboveMaximumTransactionLimit
+		return 0, ErrAboveMaximumTransactionLimit
 	}
 
 	if txnAmount < s.conf.Transaction.MinAmount {
-		return ErrBelowMinimumTransactionLimit
+		return 0, ErrBelowMinimumTransactionLimit
 	}
 
 	if txnType == Deposit && w.Balance+txnAmount > w.BalanceUpperLimit {
-		return ErrAboveMaximumBalanceLimit
+		return 0, ErrAboveMaximumBalanceLimit
 	}
 
 	if txnType == Withdrawal && w.Balance-txnAmount < s.conf.Wallet.MinBalance {
-		return ErrInsufficientBalance
+		return 0, ErrInsufficientBalance
 	}
 
 	var newBalance float64
@@ -143,11 +330,12 @@ func (s *service) processTransaction(ctx context.Context, w *Wallet, txnAmount f
 		newBalance = w.Balance - txnAmount
 	}
 
-	return s.wr.UpdateBalance(ctx, w.ID, newBalance)
+	return newBalance, nil
 }
 
 func (s *service) transactionFromTransactionCreationInfo(info *TransactionCreationInfo) *transaction.Transaction {
 	return &transaction.Transaction{
+		ID:       info.ID,
 		WalletID: info.WalletID,
 		Type:     info.TransactionType,
 		Amount:   info.Amount,
@@ -2,11 +2,15 @@ package wallet_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/challenge"
 	"github.com/gokcelb/wallet-api/internal/transaction"
 	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/cache"
 	"github.com/gokcelb/wallet-api/internal/wallet/mock"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +24,10 @@ func createMockTransactionService(t *testing.T) *mock.MockTransactionService {
 	return mock.NewMockTransactionService(gomock.NewController(t))
 }
 
+func createMockChallengeService(t *testing.T) *mock.MockChallengeService {
+	return mock.NewMockChallengeService(gomock.NewController(t))
+}
+
 func getConf() config.Conf {
 	conf, err := config.Read("../../.config/dev.json")
 	if err != nil {
@@ -31,7 +39,7 @@ func getConf() config.Conf {
 
 func TestServiceCreateWalletWithValidWalletCreationInfo(t *testing.T) {
 	mockWalletRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockWalletRepository, nil, getConf())
+	s := wallet.NewService(mockWalletRepository, nil, nil, getConf())
 
 	walletCreationInfo := &wallet.WalletCreationInfo{
 		UserID:                "1",
@@ -60,7 +68,7 @@ func TestServiceCreateWalletWithValidWalletCreationInfo(t *testing.T) {
 
 func TestServiceCreateWalletWithInvalidLimit(t *testing.T) {
 	mockWalletRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockWalletRepository, nil, getConf())
+	s := wallet.NewService(mockWalletRepository, nil, nil, getConf())
 
 	testCases := []struct {
 		desc                    string
@@ -101,7 +109,7 @@ func TestServiceCreateWalletWithInvalidLimit(t *testing.T) {
 
 func TestServiceCreateWalletWithExistingUserID(t *testing.T) {
 	mockRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockRepository, nil, getConf())
+	s := wallet.NewService(mockRepository, nil, nil, getConf())
 
 	givenWalletCreationInfo := &wallet.WalletCreationInfo{
 		UserID:                "1",
@@ -136,7 +144,7 @@ func TestServiceGetWallet(t *testing.T) {
 	}
 
 	mockRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockRepository, nil, getConf())
+	s := wallet.NewService(mockRepository, nil, nil, getConf())
 
 	testCases := []struct {
 		desc           string
@@ -177,7 +185,7 @@ func TestServiceGetWallet(t *testing.T) {
 
 func TestServiceDeleteWallet(t *testing.T) {
 	mockRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockRepository, nil, getConf())
+	s := wallet.NewService(mockRepository, nil, nil, getConf())
 
 	testCases := []struct {
 		desc                     string
@@ -220,10 +228,73 @@ func TestServiceDeleteWallet(t *testing.T) {
 	}
 }
 
+func TestServiceUpdateLimits(t *testing.T) {
+	mockWalletRepository := createMockWalletRepository(t)
+	s := wallet.NewService(mockWalletRepository, nil, nil, getConf())
+
+	testCases := []struct {
+		desc          string
+		givenInfo     *wallet.LimitsUpdateInfo
+		mockWallet    *wallet.Wallet
+		mockWRErr     error
+		expectRead    bool
+		expectUpdate  bool
+		expectedError error
+	}{
+		{
+			desc:         "limits are valid, update wallet",
+			givenInfo:    &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 2000, TransactionUpperLimit: 500},
+			mockWallet:   &wallet.Wallet{ID: "1", Balance: 1500},
+			expectRead:   true,
+			expectUpdate: true,
+		},
+		{
+			desc:          "balance upper limit is above maximum, return error",
+			givenInfo:     &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 20000, TransactionUpperLimit: 500},
+			expectedError: wallet.ErrAboveMaximumBalanceLimit,
+		},
+		{
+			desc:          "transaction upper limit is above maximum, return error",
+			givenInfo:     &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 2000, TransactionUpperLimit: 6000},
+			expectedError: wallet.ErrAboveMaximumTransactionLimit,
+		},
+		{
+			desc:          "balance is above the new balance upper limit, return error",
+			givenInfo:     &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 1000, TransactionUpperLimit: 500},
+			mockWallet:    &wallet.Wallet{ID: "1", Balance: 1500},
+			expectRead:    true,
+			expectedError: wallet.ErrBalanceAboveUpperLimit,
+		},
+		{
+			desc:          "wallet does not exist, return error",
+			givenInfo:     &wallet.LimitsUpdateInfo{WalletID: "1", BalanceUpperLimit: 1000, TransactionUpperLimit: 500},
+			mockWRErr:     wallet.ErrWalletNotFound,
+			expectRead:    true,
+			expectedError: wallet.ErrWalletNotFound,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.expectRead {
+				mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(tC.mockWallet, tC.mockWRErr)
+			}
+			if tC.expectUpdate {
+				mockWalletRepository.EXPECT().
+					UpdateLimits(context.TODO(), "1", tC.givenInfo.BalanceUpperLimit, tC.givenInfo.TransactionUpperLimit).
+					Return(nil)
+			}
+
+			err := s.UpdateLimits(context.TODO(), tC.givenInfo)
+
+			assert.ErrorIs(t, err, tC.expectedError)
+		})
+	}
+}
+
 func TestServiceCreateTransactionWithValidTransactionCreationInfo(t *testing.T) {
 	mockRepository := createMockWalletRepository(t)
 	mockTransactionService := createMockTransactionService(t)
-	s := wallet.NewService(mockRepository, mockTransactionService, getConf())
+	s := wallet.NewService(mockRepository, mockTransactionService, nil, getConf())
 
 	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
 		WalletID:        "1",
@@ -238,9 +309,11 @@ func TestServiceCreateTransactionWithValidTransactionCreationInfo(t *testing.T)
 		TransactionUpperLimit: 1000,
 	}
 	convertedTxnSvcTransaction := &transaction.Transaction{
-		WalletID: "1",
-		Type:     "withdrawal",
-		Amount:   300,
+		WalletID:      "1",
+		Type:          "withdrawal",
+		Amount:        300,
+		BalanceBefore: 500,
+		BalanceAfter:  200,
 	}
 	mockTxnSvcTransactionID := "1"
 
@@ -249,14 +322,7 @@ func TestServiceCreateTransactionWithValidTransactionCreationInfo(t *testing.T)
 		Return(mockRepoGetWalletWallet, nil)
 
 	mockRepository.EXPECT().
-		UpdateBalance(
-			context.TODO(),
-			givenTransactionCreationInfo.WalletID,
-			mockRepoGetWalletWallet.Balance-givenTransactionCreationInfo.Amount,
-		).Return(nil)
-
-	mockTransactionService.EXPECT().
-		CreateTransaction(context.TODO(), convertedTxnSvcTransaction).
+		ApplyTransaction(context.TODO(), mockRepoGetWalletWallet, convertedTxnSvcTransaction).
 		Return(mockTxnSvcTransactionID, nil)
 
 	txn, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
@@ -265,9 +331,193 @@ func TestServiceCreateTransactionWithValidTransactionCreationInfo(t *testing.T)
 	assert.Nil(t, err)
 }
 
+func TestServiceCreateTransactionRetriesConcurrentBalanceUpdate(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	s := wallet.NewService(mockRepository, mockTransactionService, nil, getConf())
+
+	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
+		WalletID:        "1",
+		TransactionType: "deposit",
+		Amount:          100,
+	}
+	staleWallet := &wallet.Wallet{
+		ID:                    "1",
+		UserID:                "1",
+		Balance:               500,
+		BalanceUpperLimit:     10000,
+		TransactionUpperLimit: 1000,
+	}
+	freshWallet := &wallet.Wallet{
+		ID:                    "1",
+		UserID:                "1",
+		Balance:               700,
+		BalanceUpperLimit:     10000,
+		TransactionUpperLimit: 1000,
+	}
+	staleTransaction := &transaction.Transaction{
+		WalletID:      "1",
+		Type:          "deposit",
+		Amount:        100,
+		BalanceBefore: 500,
+		BalanceAfter:  600,
+	}
+	freshTransaction := &transaction.Transaction{
+		WalletID:      "1",
+		Type:          "deposit",
+		Amount:        100,
+		BalanceBefore: 700,
+		BalanceAfter:  800,
+	}
+
+	gomock.InOrder(
+		mockRepository.EXPECT().Read(context.TODO(), "1").Return(staleWallet, nil),
+		mockRepository.EXPECT().
+			ApplyTransaction(context.TODO(), staleWallet, staleTransaction).
+			Return("", wallet.ErrWalletBalanceUpdateFailed),
+		mockRepository.EXPECT().Read(context.TODO(), "1").Return(freshWallet, nil),
+		mockRepository.EXPECT().ApplyTransaction(context.TODO(), freshWallet, freshTransaction).Return("1", nil),
+	)
+
+	id, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
+
+	assert.Equal(t, "1", id)
+	assert.Nil(t, err)
+}
+
+func TestServiceCreateTransactionReadsPastCache(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	cachedRepository := cache.NewCache(mockRepository, cache.NewLRU(10), time.Minute)
+	s := wallet.NewService(cachedRepository, mockTransactionService, nil, getConf())
+
+	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
+		WalletID:        "1",
+		TransactionType: "withdrawal",
+		Amount:          300,
+	}
+	cachedWallet := &wallet.Wallet{
+		ID:                    "1",
+		UserID:                "1",
+		Balance:               100,
+		BalanceUpperLimit:     10000,
+		TransactionUpperLimit: 1000,
+	}
+	freshWallet := &wallet.Wallet{
+		ID:                    "1",
+		UserID:                "1",
+		Balance:               500,
+		BalanceUpperLimit:     10000,
+		TransactionUpperLimit: 1000,
+	}
+
+	gomock.InOrder(
+		mockRepository.EXPECT().Read(context.TODO(), "1").Return(cachedWallet, nil),
+		mockRepository.EXPECT().Read(context.TODO(), "1").Return(freshWallet, nil),
+		mockRepository.EXPECT().ApplyTransaction(context.TODO(), freshWallet, gomock.Any()).Return("1", nil),
+	)
+
+	_, err := s.GetWallet(context.TODO(), "1")
+	assert.Nil(t, err)
+
+	txn, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
+
+	assert.Equal(t, "1", txn)
+	assert.Nil(t, err)
+}
+
+func TestServiceCreateTransactionBalanceUpdateAttemptsExhausted(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	s := wallet.NewService(mockRepository, nil, nil, getConf())
+
+	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
+		WalletID:        "1",
+		TransactionType: "deposit",
+		Amount:          100,
+	}
+	mockRepoGetWalletWallet := &wallet.Wallet{
+		ID:                    "1",
+		UserID:                "1",
+		Balance:               500,
+		BalanceUpperLimit:     10000,
+		TransactionUpperLimit: 1000,
+	}
+
+	mockRepository.EXPECT().
+		Read(context.TODO(), "1").
+		Return(mockRepoGetWalletWallet, nil).
+		Times(wallet.MaxBalanceUpdateAttempts)
+
+	mockRepository.EXPECT().
+		ApplyTransaction(context.TODO(), mockRepoGetWalletWallet, gomock.Any()).
+		Return("", wallet.ErrWalletBalanceUpdateFailed).
+		Times(wallet.MaxBalanceUpdateAttempts)
+
+	id, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
+
+	assert.Empty(t, id)
+	assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed)
+}
+
+func TestServiceCreateTransactionWithAppliedID(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	s := wallet.NewService(mockRepository, mockTransactionService, nil, getConf())
+
+	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
+		ID:              "63f000000000000000000002",
+		WalletID:        "1",
+		TransactionType: "deposit",
+		Amount:          100,
+	}
+
+	mockTransactionService.EXPECT().
+		GetTransaction(context.TODO(), givenTransactionCreationInfo.ID).
+		Return(&transaction.Transaction{ID: givenTransactionCreationInfo.ID}, nil)
+
+	id, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
+
+	assert.Empty(t, id)
+	assert.ErrorIs(t, err, wallet.ErrDuplicateTransaction)
+}
+
+func TestServiceCreateTransactionWithNewID(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	s := wallet.NewService(mockRepository, mockTransactionService, nil, getConf())
+
+	givenTransactionCreationInfo := &wallet.TransactionCreationInfo{
+		ID:              "63f000000000000000000002",
+		WalletID:        "1",
+		TransactionType: "deposit",
+		Amount:          100,
+	}
+	mockRepoGetWalletWallet := &wallet.Wallet{ID: "1", Balance: 500, BalanceUpperLimit: 10000, TransactionUpperLimit: 1000}
+
+	mockTransactionService.EXPECT().
+		GetTransaction(context.TODO(), givenTransactionCreationInfo.ID).
+		Return(nil, transaction.ErrTransactionNotFound)
+	mockRepository.EXPECT().Read(context.TODO(), "1").Return(mockRepoGetWalletWallet, nil)
+	mockRepository.EXPECT().
+		ApplyTransaction(context.TODO(), mockRepoGetWalletWallet, &transaction.Transaction{
+			ID:            givenTransactionCreationInfo.ID,
+			WalletID:      "1",
+			Type:          "deposit",
+			Amount:        100,
+			BalanceBefore: 500,
+			BalanceAfter:  600,
+		}).
+		Return(givenTransactionCreationInfo.ID, nil)
+
+	id, err := s.CreateTransaction(context.TODO(), givenTransactionCreationInfo)
+
+	assert.Equal(t, givenTransactionCreationInfo.ID, id)
+	assert.Nil(t, err)
+}
+
 func TestServiceCreateTransactionWithInvalidTransactionCreationInfo(t *testing.T) {
 	mockRepository := createMockWalletRepository(t)
-	s := wallet.NewService(mockRepository, nil, getConf())
+	s := wallet.NewService(mockRepository, nil, nil, getConf())
 
 	testCases := []struct {
 		desc                         string
@@ -361,9 +611,9 @@ func TestServiceCreateTransactionWithInvalidTransactionCreationInfo(t *testing.T
 func TestServiceGetTransactionsWithValidParams(t *testing.T) {
 	mockWalletRepository := createMockWalletRepository(t)
 	mockTransactionService := createMockTransactionService(t)
-	s := wallet.NewService(mockWalletRepository, mockTransactionService, getConf())
+	s := wallet.NewService(mockWalletRepository, mockTransactionService, nil, getConf())
 
-	expectedTxns := []*transaction.Transaction{
+	mockTxns := []*transaction.Transaction{
 		{
 			ID:       "1",
 			WalletID: "1",
@@ -372,38 +622,202 @@ func TestServiceGetTransactionsWithValidParams(t *testing.T) {
 		},
 	}
 
-	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, nil)
-
-	mockTransactionService.EXPECT().
-		GetTransactionsByWalletID(context.TODO(), "1", "deposit", wallet.DefaultPageNo, wallet.DefaultPageSize).
-		Return(expectedTxns, nil)
-
-	txns, err := s.GetTransactions(context.TODO(), "1", "deposit", wallet.DefaultPageNo, wallet.DefaultPageSize)
-
-	assert.Equal(t, expectedTxns, txns)
-	assert.Nil(t, err)
+	testCases := []struct {
+		desc                  string
+		givenEstimateTotal    bool
+		expectedCountLimit    int
+		mockTxnSvcCount       int64
+		expectedTotalEstimate bool
+	}{
+		{
+			desc:                  "exact total, count without limit",
+			givenEstimateTotal:    false,
+			expectedCountLimit:    0,
+			mockTxnSvcCount:       1,
+			expectedTotalEstimate: false,
+		},
+		{
+			desc:                  "estimated total below limit, return exact total",
+			givenEstimateTotal:    true,
+			expectedCountLimit:    wallet.EstimatedTotalLimit,
+			mockTxnSvcCount:       1,
+			expectedTotalEstimate: false,
+		},
+		{
+			desc:                  "estimated total reaches limit, return estimated total",
+			givenEstimateTotal:    true,
+			expectedCountLimit:    wallet.EstimatedTotalLimit,
+			mockTxnSvcCount:       int64(wallet.EstimatedTotalLimit),
+			expectedTotalEstimate: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, nil)
+
+			mockTransactionService.EXPECT().
+				GetTransactionsByWalletID(context.TODO(), "1", "deposit", wallet.DefaultPageNo, wallet.DefaultPageSize).
+				Return(mockTxns, nil)
+
+			mockTransactionService.EXPECT().
+				CountTransactionsByWalletID(context.TODO(), "1", "deposit", tC.expectedCountLimit).
+				Return(tC.mockTxnSvcCount, nil)
+
+			page, err := s.GetTransactions(
+				context.TODO(),
+				"1",
+				"deposit",
+				wallet.DefaultPageNo,
+				wallet.DefaultPageSize,
+				tC.givenEstimateTotal,
+			)
+
+			assert.Equal(t, mockTxns, page.Transactions)
+			assert.Equal(t, tC.mockTxnSvcCount, page.Total)
+			assert.Equal(t, tC.expectedTotalEstimate, page.TotalEstimated)
+			assert.Nil(t, err)
+		})
+	}
 }
 
 func TestServiceGetTransactionsWithInvalidType(t *testing.T) {
 	mockWalletRepository := createMockWalletRepository(t)
 	mockTransactionService := createMockTransactionService(t)
-	s := wallet.NewService(mockWalletRepository, mockTransactionService, getConf())
+	s := wallet.NewService(mockWalletRepository, mockTransactionService, nil, getConf())
 
-	txns, err := s.GetTransactions(context.TODO(), "1", "invalid", wallet.DefaultPageNo, wallet.DefaultPageSize)
+	page, err := s.GetTransactions(context.TODO(), "1", "invalid", wallet.DefaultPageNo, wallet.DefaultPageSize, false)
 
-	assert.Nil(t, txns)
+	assert.Nil(t, page)
 	assert.ErrorIs(t, err, wallet.ErrInvalidTransactionType)
 }
 
 func TestServiceGetTransactionsWithInvalidWalletID(t *testing.T) {
 	mockWalletRepository := createMockWalletRepository(t)
 	mockTransactionService := createMockTransactionService(t)
-	s := wallet.NewService(mockWalletRepository, mockTransactionService, getConf())
+	s := wallet.NewService(mockWalletRepository, mockTransactionService, nil, getConf())
 
 	mockWalletRepository.EXPECT().Read(context.TODO(), "1").Return(nil, wallet.ErrWalletNotFound)
 
-	txns, err := s.GetTransactions(context.TODO(), "1", "", wallet.DefaultPageNo, wallet.DefaultPageSize)
+	page, err := s.GetTransactions(context.TODO(), "1", "", wallet.DefaultPageNo, wallet.DefaultPageSize, false)
 
-	assert.Nil(t, txns)
+	assert.Nil(t, page)
 	assert.ErrorIs(t, err, wallet.ErrWalletNotFound)
 }
+
+func TestServiceCreateTransactionWithStepUp(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockChallengeService := createMockChallengeService(t)
+	conf := getConf()
+	conf.StepUp.ThresholdAmount = 1000
+	s := wallet.NewService(mockRepository, nil, mockChallengeService, conf)
+
+	largeWithdrawal := &wallet.TransactionCreationInfo{WalletID: "1", TransactionType: "withdrawal", Amount: 2000}
+	mockChallenge := &challenge.Challenge{ID: "c1", WalletID: "1", TransactionType: "withdrawal", Amount: 2000}
+
+	txnID, err := s.CreateTransaction(context.TODO(), largeWithdrawal)
+
+	assert.Empty(t, txnID)
+	assert.ErrorIs(t, err, wallet.ErrStepUpRequired)
+
+	mockRepository.EXPECT().
+		Read(context.TODO(), "1").
+		Return(&wallet.Wallet{ID: "1", Balance: 5000, BalanceUpperLimit: 10000, TransactionUpperLimit: 5000}, nil)
+	mockChallengeService.EXPECT().
+		CreateChallenge(context.TODO(), "1", "withdrawal", float64(2000)).
+		Return(mockChallenge, nil)
+
+	c, err := s.ChallengeTransaction(context.TODO(), largeWithdrawal)
+
+	assert.Equal(t, mockChallenge, c)
+	assert.Nil(t, err)
+}
+
+func TestServiceChallengeTransactionThatWouldFail(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockChallengeService := createMockChallengeService(t)
+	conf := getConf()
+	conf.StepUp.ThresholdAmount = 1000
+	s := wallet.NewService(mockRepository, nil, mockChallengeService, conf)
+
+	largeWithdrawal := &wallet.TransactionCreationInfo{WalletID: "1", TransactionType: "withdrawal", Amount: 2000}
+
+	mockRepository.EXPECT().
+		Read(context.TODO(), "1").
+		Return(&wallet.Wallet{ID: "1", Balance: 1500, BalanceUpperLimit: 10000, TransactionUpperLimit: 5000}, nil)
+
+	c, err := s.ChallengeTransaction(context.TODO(), largeWithdrawal)
+
+	assert.Nil(t, c)
+	assert.ErrorIs(t, err, wallet.ErrInsufficientBalance)
+}
+
+func TestServiceCompleteTransaction(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	mockChallengeService := createMockChallengeService(t)
+	s := wallet.NewService(mockRepository, mockTransactionService, mockChallengeService, getConf())
+
+	info := &wallet.ChallengeCompletionInfo{WalletID: "1", ChallengeID: "c1", Code: "123456"}
+	mockWallet := &wallet.Wallet{ID: "1", Balance: 5000, BalanceUpperLimit: 10000, TransactionUpperLimit: 5000}
+
+	mockChallengeService.EXPECT().
+		VerifyChallenge(context.TODO(), "c1", "1", "123456").
+		Return(&challenge.Challenge{ID: "c1", WalletID: "1", TransactionType: "withdrawal", Amount: 2000}, nil)
+	mockTransactionService.EXPECT().GetTransaction(context.TODO(), "c1").Return(nil, transaction.ErrTransactionNotFound)
+	mockRepository.EXPECT().Read(context.TODO(), "1").Return(mockWallet, nil)
+	mockRepository.EXPECT().
+		ApplyTransaction(context.TODO(), mockWallet, &transaction.Transaction{
+			ID:            "c1",
+			WalletID:      "1",
+			Type:          "withdrawal",
+			Amount:        2000,
+			BalanceBefore: 5000,
+			BalanceAfter:  3000,
+		}).
+		Return("c1", nil)
+
+	txnID, err := s.CompleteTransaction(context.TODO(), info)
+
+	assert.Equal(t, "c1", txnID)
+	assert.Nil(t, err)
+
+	mockChallengeService.EXPECT().
+		VerifyChallenge(context.TODO(), "c1", "1", "123456").
+		Return(nil, challenge.ErrInvalidCode)
+
+	txnID, err = s.CompleteTransaction(context.TODO(), info)
+
+	assert.Empty(t, txnID)
+	assert.ErrorIs(t, err, challenge.ErrInvalidCode)
+}
+
+func TestServiceCompleteTransactionThatFails(t *testing.T) {
+	mockRepository := createMockWalletRepository(t)
+	mockTransactionService := createMockTransactionService(t)
+	mockChallengeService := createMockChallengeService(t)
+	s := wallet.NewService(mockRepository, mockTransactionService, mockChallengeService, getConf())
+
+	info := &wallet.ChallengeCompletionInfo{WalletID: "1", ChallengeID: "c1", Code: "123456"}
+	mockChallenge := &challenge.Challenge{ID: "c1", WalletID: "1", TransactionType: "withdrawal", Amount: 2000}
+	mockErr := errors.New("connection reset")
+
+	mockChallengeService.EXPECT().VerifyChallenge(context.TODO(), "c1", "1", "123456").Return(mockChallenge, nil)
+	mockTransactionService.EXPECT().GetTransaction(context.TODO(), "c1").Return(nil, transaction.ErrTransactionNotFound)
+	mockRepository.EXPECT().Read(context.TODO(), "1").Return(nil, mockErr)
+	mockChallengeService.EXPECT().ReleaseChallenge(context.TODO(), "c1").Return(nil)
+
+	txnID, err := s.CompleteTransaction(context.TODO(), info)
+
+	assert.Empty(t, txnID)
+	assert.ErrorIs(t, err, mockErr)
+
+	// The transaction went through after all, the retry must not apply it
+	// again.
+	mockChallengeService.EXPECT().VerifyChallenge(context.TODO(), "c1", "1", "123456").Return(mockChallenge, nil)
+	mockTransactionService.EXPECT().GetTransaction(context.TODO(), "c1").Return(&transaction.Transaction{ID: "c1"}, nil)
+
+	txnID, err = s.CompleteTransaction(context.TODO(), info)
+
+	assert.Equal(t, "c1", txnID)
+	assert.Nil(t, err)
+}
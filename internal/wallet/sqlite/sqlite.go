@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/sqlite"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TransactionInserter inserts transactions as part of an SQL transaction of
+// the same database.
+type TransactionInserter interface {
+	InsertTx(ctx context.Context, tx *sql.Tx, txns []*transaction.Transaction) error
+}
+
+// SQLite keeps last_transaction_at as unix nanoseconds, 0 for wallets
+// without transactions.
+type SQLite struct {
+	db           *sql.DB
+	transactions TransactionInserter
+}
+
+func NewSQLite(db *sql.DB, transactions TransactionInserter) *SQLite {
+	return &SQLite{db, transactions}
+}
+
+// Create gives wallets ids in the same format as the mongo backend, which
+// other parts of the API, like bank imports, expect.
+func (s *SQLite) Create(ctx context.Context, w *wallet.Wallet) (string, error) {
+	id := primitive.NewObjectID().Hex()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO wallets (id, user_id, balance, balance_upper_limit, transaction_upper_limit) VALUES (?, ?, ?, ?, ?)`,
+		id, w.UserID, w.Balance, w.BalanceUpperLimit, w.TransactionUpperLimit)
+	if sqlite.IsUniqueViolation(err) {
+		return "", wallet.ErrWalletWithUserIDExists
+	} else if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) Read(ctx context.Context, id string) (*wallet.Wallet, error) {
+	return s.readOne(ctx, "WHERE id = ?", id)
+}
+
+func (s *SQLite) ReadByUserID(ctx context.Context, userID string) (*wallet.Wallet, error) {
+	return s.readOne(ctx, "WHERE user_id = ? LIMIT 1", userID)
+}
+
+func (s *SQLite) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM wallets WHERE id = ?", id)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return err
+}
+
+// ApplyTransaction updates the balance and stores the transaction in one SQL
+// transaction holding the write lock, failing when the wallet changed since
+// the caller read it.
+func (s *SQLite) ApplyTransaction(ctx context.Context, w *wallet.Wallet, txn *transaction.Transaction) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var balance float64
+	var lastTransactionAt int64
+	err = tx.QueryRowContext(ctx, "SELECT balance, last_transaction_at FROM wallets WHERE id = ?", w.ID).
+		Scan(&balance, &lastTransactionAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", wallet.ErrWalletBalanceUpdateFailed
+	} else if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	if balance != w.Balance || lastTransactionAt != toUnixNano(w.LastTransactionAt) {
+		return "", wallet.ErrWalletBalanceUpdateFailed
+	}
+
+	if txn.ID == "" {
+		txn.ID = primitive.NewObjectID().Hex()
+	}
+	txn.WalletID = w.ID
+	txn.CreatedAt = wallet.TransactionTime(w.LastTransactionAt)
+	_, err = tx.ExecContext(ctx, "UPDATE wallets SET balance = ?, last_transaction_at = ? WHERE id = ?",
+		txn.BalanceAfter, txn.CreatedAt.UnixNano(), w.ID)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	if err = s.transactions.InsertTx(ctx, tx, []*transaction.Transaction{txn}); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	return txn.ID, nil
+}
+
+func (s *SQLite) UpdateLimits(ctx context.Context, id string, balanceUpperLimit, transactionUpperLimit float64) error {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE wallets SET balance_upper_limit = ?, transaction_upper_limit = ? WHERE id = ?",
+		balanceUpperLimit, transactionUpperLimit, id)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		log.Error(err)
+		return err
+	} else if n == 0 {
+		return wallet.ErrWalletNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) readOne(ctx context.Context, where string, args ...interface{}) (*wallet.Wallet, error) {
+	var w wallet.Wallet
+	var lastTransactionAt int64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, user_id, balance, balance_upper_limit, transaction_upper_limit, last_transaction_at FROM wallets "+where, args...).
+		Scan(&w.ID, &w.UserID, &w.Balance, &w.BalanceUpperLimit, &w.TransactionUpperLimit, &lastTransactionAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, wallet.ErrWalletNotFound
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if lastTransactionAt != 0 {
+		w.LastTransactionAt = time.Unix(0, lastTransactionAt)
+	}
+
+	return &w, nil
+}
+
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
@@ -0,0 +1,58 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gokcelb/wallet-api/config"
+	sqliteDB "github.com/gokcelb/wallet-api/internal/sqlite"
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	transactionSQLite "github.com/gokcelb/wallet-api/internal/transaction/sqlite"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/gokcelb/wallet-api/internal/wallet/sqlite"
+	"github.com/gokcelb/wallet-api/internal/wallet/wallettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite(t *testing.T) {
+	wallettest.TestRepository(t, func(t *testing.T) (wallet.WalletRepository, transaction.TransactionRepository) {
+		db, err := sqliteDB.Open(context.TODO(), config.SQLiteConf{Path: filepath.Join(t.TempDir(), "test.db")})
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		transactions := transactionSQLite.NewSQLite(db)
+		return sqlite.NewSQLite(db, transactions), transactions
+	})
+}
+
+func TestSQLiteApplyTransactionIsAtomic(t *testing.T) {
+	db, err := sqliteDB.Open(context.TODO(), config.SQLiteConf{Path: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	defer db.Close()
+
+	s := sqlite.NewSQLite(db, transactionSQLite.NewSQLite(db))
+	id, err := s.Create(context.TODO(), &wallet.Wallet{UserID: "1", Balance: 10})
+	require.NoError(t, err)
+	w, err := s.Read(context.TODO(), id)
+	require.NoError(t, err)
+
+	// Without the table the insert fails after the balance is updated.
+	_, err = db.ExecContext(context.TODO(), "DROP TABLE transactions")
+	require.NoError(t, err)
+
+	_, err = s.ApplyTransaction(context.TODO(), w, &transaction.Transaction{
+		Type:          "deposit",
+		Amount:        5,
+		BalanceBefore: 10,
+		BalanceAfter:  15,
+	})
+	assert.Error(t, err)
+
+	w, err = s.Read(context.TODO(), id)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(10), w.Balance)
+	assert.True(t, w.LastTransactionAt.IsZero())
+}
@@ -0,0 +1,274 @@
+// Package wallettest holds the contract every wallet.WalletRepository has to
+// meet, so that the storage backends behave the same.
+package wallettest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gokcelb/wallet-api/internal/transaction"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unknownID is well formed for every backend but never handed out.
+const unknownID = "000000000000000000000000"
+
+const invalidID = "not-an-id"
+
+// TestRepository runs the contract against the repositories newRepository
+// returns, which have to be empty, along with the transactions the wallet
+// repository stores the applied transactions in.
+func TestRepository(t *testing.T, newRepository func(t *testing.T) (wallet.WalletRepository, transaction.TransactionRepository)) {
+	t.Run("create and read", func(t *testing.T) {
+		wr, _ := newRepository(t)
+		givenWallet := &wallet.Wallet{UserID: "1", Balance: 10, BalanceUpperLimit: 1000, TransactionUpperLimit: 100}
+
+		id, err := wr.Create(context.TODO(), givenWallet)
+		require.NoError(t, err)
+		assert.NotEmpty(t, id)
+
+		w, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+		assert.Equal(t, &wallet.Wallet{ID: id, UserID: "1", Balance: 10, BalanceUpperLimit: 1000, TransactionUpperLimit: 100}, w)
+
+		w, err = wr.ReadByUserID(context.TODO(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, id, w.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		wr, _ := newRepository(t)
+
+		for _, id := range []string{unknownID, invalidID} {
+			_, err := wr.Read(context.TODO(), id)
+			assert.ErrorIs(t, err, wallet.ErrWalletNotFound, id)
+
+			err = wr.UpdateLimits(context.TODO(), id, 1000, 100)
+			assert.ErrorIs(t, err, wallet.ErrWalletNotFound, id)
+
+			_, err = wr.ApplyTransaction(context.TODO(), &wallet.Wallet{ID: id}, deposit(0, 10))
+			assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed, id)
+
+			assert.NoError(t, wr.Delete(context.TODO(), id), id)
+		}
+
+		_, err := wr.ReadByUserID(context.TODO(), "1")
+		assert.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	})
+
+	t.Run("one wallet per user", func(t *testing.T) {
+		wr, _ := newRepository(t)
+		create(t, wr, 0)
+
+		_, err := wr.Create(context.TODO(), &wallet.Wallet{UserID: "1"})
+		assert.ErrorIs(t, err, wallet.ErrWalletWithUserIDExists)
+
+		_, err = wr.Create(context.TODO(), &wallet.Wallet{UserID: "2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent creates for the same user", func(t *testing.T) {
+		wr, _ := newRepository(t)
+
+		const n = 10
+		errs := make(chan error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := wr.Create(context.TODO(), &wallet.Wallet{UserID: "1"})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+			} else {
+				assert.ErrorIs(t, err, wallet.ErrWalletWithUserIDExists)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		wr, _ := newRepository(t)
+		id := create(t, wr, 0)
+
+		require.NoError(t, wr.Delete(context.TODO(), id))
+
+		_, err := wr.Read(context.TODO(), id)
+		assert.ErrorIs(t, err, wallet.ErrWalletNotFound)
+		assert.NoError(t, wr.Delete(context.TODO(), id))
+	})
+
+	t.Run("update limits", func(t *testing.T) {
+		wr, _ := newRepository(t)
+		id := create(t, wr, 10)
+
+		require.NoError(t, wr.UpdateLimits(context.TODO(), id, 2000, 200))
+
+		w, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+		assert.Equal(t, 2000.0, w.BalanceUpperLimit)
+		assert.Equal(t, 200.0, w.TransactionUpperLimit)
+		assert.Equal(t, 10.0, w.Balance)
+	})
+
+	t.Run("apply transaction", func(t *testing.T) {
+		wr, tr := newRepository(t)
+		id := create(t, wr, 10)
+		before := time.Now().Truncate(time.Millisecond)
+
+		w, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+
+		txnID, err := wr.ApplyTransaction(context.TODO(), w, deposit(10, 25))
+		require.NoError(t, err)
+
+		_, err = wr.ApplyTransaction(context.TODO(), w, deposit(10, 40))
+		assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed)
+
+		applied, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, applied.Balance)
+
+		txn, err := tr.Read(context.TODO(), txnID)
+		require.NoError(t, err)
+		assert.Equal(t, id, txn.WalletID)
+		assert.Equal(t, 10.0, txn.BalanceBefore)
+		assert.Equal(t, 25.0, txn.BalanceAfter)
+		assert.False(t, txn.CreatedAt.Before(before))
+		assert.True(t, txn.CreatedAt.Equal(applied.LastTransactionAt))
+
+		count, err := tr.CountByWalletID(context.TODO(), id, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("apply transaction after the balance came back", func(t *testing.T) {
+		wr, _ := newRepository(t)
+		id := create(t, wr, 10)
+
+		stale, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+
+		w := stale
+		for _, balanceAfter := range []float64{20, 10} {
+			_, err = wr.ApplyTransaction(context.TODO(), w, deposit(w.Balance, balanceAfter))
+			require.NoError(t, err)
+
+			w, err = wr.Read(context.TODO(), id)
+			require.NoError(t, err)
+		}
+
+		_, err = wr.ApplyTransaction(context.TODO(), stale, deposit(10, 30))
+		assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed)
+	})
+
+	t.Run("concurrent transactions from the same wallet", func(t *testing.T) {
+		wr, tr := newRepository(t)
+		id := create(t, wr, 0)
+
+		w, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+
+		const n = 20
+		errs := make(chan error, n)
+		var wg sync.WaitGroup
+		for i := 1; i <= n; i++ {
+			wg.Add(1)
+			go func(balanceAfter float64) {
+				defer wg.Done()
+				_, err := wr.ApplyTransaction(context.TODO(), w, deposit(0, balanceAfter))
+				errs <- err
+			}(float64(i))
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+			} else {
+				assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+
+		count, err := tr.CountByWalletID(context.TODO(), id, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("concurrent transactions with retries", func(t *testing.T) {
+		wr, tr := newRepository(t)
+		id := create(t, wr, 0)
+
+		const n = 20
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					w, err := wr.Read(context.TODO(), id)
+					if !assert.NoError(t, err) {
+						return
+					}
+
+					_, err = wr.ApplyTransaction(context.TODO(), w, deposit(w.Balance, w.Balance+1))
+					if err == nil {
+						return
+					}
+					if !assert.ErrorIs(t, err, wallet.ErrWalletBalanceUpdateFailed) {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		w, err := wr.Read(context.TODO(), id)
+		require.NoError(t, err)
+		assert.Equal(t, float64(n), w.Balance)
+
+		// Sorted by creation time, the transactions chain up in the order
+		// their balance changes were applied.
+		txns, err := tr.ReadByWalletIDBetween(context.TODO(), id, time.Time{}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, txns, n)
+		for i, txn := range txns {
+			assert.Equal(t, float64(i), txn.BalanceBefore)
+			assert.Equal(t, float64(i+1), txn.BalanceAfter)
+			if i > 0 {
+				assert.True(t, txn.CreatedAt.After(txns[i-1].CreatedAt))
+			}
+		}
+	})
+}
+
+func create(t *testing.T, wr wallet.WalletRepository, balance float64) string {
+	id, err := wr.Create(context.TODO(), &wallet.Wallet{UserID: "1", Balance: balance, BalanceUpperLimit: 1000, TransactionUpperLimit: 100})
+	require.NoError(t, err)
+
+	return id
+}
+
+func deposit(balanceBefore, balanceAfter float64) *transaction.Transaction {
+	return &transaction.Transaction{
+		Type:          "deposit",
+		Amount:        balanceAfter - balanceBefore,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+	}
+}
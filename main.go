@@ -9,16 +9,23 @@ import (
 	"time"
 
 	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/analytics"
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	"github.com/gokcelb/wallet-api/internal/archive"
 	"github.com/gokcelb/wallet-api/internal/auth"
+	"github.com/gokcelb/wallet-api/internal/balance"
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	"github.com/gokcelb/wallet-api/internal/challenge"
+	"github.com/gokcelb/wallet-api/internal/export"
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	"github.com/gokcelb/wallet-api/internal/ratelimit"
+	"github.com/gokcelb/wallet-api/internal/signing"
+	"github.com/gokcelb/wallet-api/internal/statement"
 	"github.com/gokcelb/wallet-api/internal/transaction"
-	transactionMongo "github.com/gokcelb/wallet-api/internal/transaction/mongo"
 	"github.com/gokcelb/wallet-api/internal/wallet"
-	walletMongo "github.com/gokcelb/wallet-api/internal/wallet/mongo"
+	walletCache "github.com/gokcelb/wallet-api/internal/wallet/cache"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func main() {
@@ -27,33 +34,126 @@ func main() {
 		panic(err)
 	}
 
-	tokenService := auth.NewTokenService(conf.JWT)
+	if err := conf.Archive.Check(); err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(ctx, conf, os.Args[2:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	repos, closeRepos, err := newRepositories(ctx, conf)
+	if err != nil {
+		panic(err)
+	}
+	defer closeRepos()
+
+	keySet, err := auth.NewKeySet(conf.JWT)
+	if err != nil {
+		panic(err)
+	}
+
+	tokenService := auth.NewTokenService(conf.JWT, keySet, repos.denylist)
+	userStore := auth.NewLocalUserStore(conf.Auth.Users)
+	authService := auth.NewService(userStore, tokenService, repos.refreshToken, repos.denylist, conf.JWT)
+	authHandler := auth.NewHandler(authService, tokenService)
+
+	if err := checkSigningKeys(ctx, repos, conf.Signing); err != nil {
+		panic(err)
+	}
+
+	apiKeyService := apikey.NewService(repos.apiKey, conf.Signing)
+	apiKeyHandler := apikey.NewHandler(apiKeyService)
+
+	oauthService := oauth.NewService(repos.oauthClient, tokenService, conf.Signing)
+	oauthHandler := oauth.NewHandler(oauthService)
 
 	e := echo.New()
+	// The API is not run behind a proxy, so X-Forwarded-For and X-Real-IP
+	// are not trusted for the caller's address.
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(apiKeyHandler.Authenticate)
 	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		ParseTokenFunc: tokenService.Decode,
+		Skipper: func(c echo.Context) bool {
+			return auth.Skipper(c) || oauth.Skipper(c) || apikey.HasKey(c)
+		},
+		ParseTokenFunc:          tokenService.Decode,
+		ErrorHandlerWithContext: auth.JWTErrorHandler,
 	}))
+	if conf.Signing.Enabled {
+		secretStore := signing.NewLocalSecretStore(conf.Signing.Partners)
+		verifier := signing.NewVerifier(secretStore, signing.NewMemoryNonceCache(), conf.Signing, func(c echo.Context) bool {
+			return auth.Skipper(c) || oauth.Skipper(c)
+		})
+		e.Use(verifier.Verify)
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), conf.RateLimit)
+	if conf.RateLimit.Enabled {
+		e.Use(limiter.LimitCaller)
+	}
 
-	ctx := context.Background()
-	mongoClient := connectToMongo(ctx, conf)
-	defer disconnectFromMongo(ctx, mongoClient)
-
-	transactionCollection := mongoClient.
-		Database(conf.Mongo.Database).
-		Collection(conf.Mongo.Collection.Transaction)
-	transactionRepository := transactionMongo.NewMongo(transactionCollection)
+	var transactionRepository transaction.TransactionRepository = repos.transaction
+	if conf.Archive.Enabled {
+		transactionRepository = archive.NewRepository(repos.transaction, repos.transactionArchive)
+	}
 	transactionService := transaction.NewService(transactionRepository)
 	transactionHandler := transaction.NewHandler(transactionService)
 
-	walletCollection := mongoClient.
-		Database(conf.Mongo.Database).
-		Collection(conf.Mongo.Collection.Wallet)
-	walletRepository := walletMongo.NewMongo(walletCollection)
-	walletService := wallet.NewService(walletRepository, transactionService, conf)
+	challengeService := challenge.NewService(repos.challenge, challenge.NewLogSender(), conf.StepUp)
+
+	var walletRepository wallet.WalletRepository = repos.wallet
+	if conf.Wallet.CacheSize > 0 {
+		cacheTTL := time.Second * time.Duration(conf.Wallet.CacheTTLInSec)
+		walletRepository = walletCache.NewCache(repos.wallet, walletCache.NewLRU(conf.Wallet.CacheSize), cacheTTL)
+	}
+	walletService := wallet.NewService(walletRepository, transactionService, challengeService, conf)
 	walletHandler := wallet.NewHandler(walletService)
 
+	statementCache := statement.NewMemoryCache(conf.Statement.CacheSize)
+	statementService := statement.NewService(repos.wallet, transactionRepository, statementCache, conf)
+	statementHandler := statement.NewHandler(statementService)
+
+	exportService := export.NewService(repos.wallet, transactionRepository, conf)
+	exportHandler := export.NewHandler(exportService)
+
+	bankImportService := bankimport.NewService(repos.bankImport, walletService, conf.Export.Currency)
+	bankImportHandler := bankimport.NewHandler(bankImportService)
+
+	analyticsService := analytics.NewService(repos.wallet, transactionRepository)
+	analyticsHandler := analytics.NewHandler(analyticsService)
+
+	balanceService := balance.NewService(repos.snapshot, repos.wallet, transactionRepository, conf)
+	balanceHandler := balance.NewHandler(balanceService)
+
+	jobCtx, cancelJobs := context.WithCancel(ctx)
+	defer cancelJobs()
+	go runPendingTransactionFlush(jobCtx, repos, time.Second*time.Duration(conf.Wallet.FlushIntervalInSec))
+	go balanceService.RunSnapshots(jobCtx, time.Minute*time.Duration(conf.Balance.SnapshotIntervalInMin))
+	if conf.Archive.Enabled {
+		archiver := archive.NewArchiver(repos.transaction, repos.transactionArchive, repos.archiveLease, conf.Archive)
+		go archiver.Run(jobCtx, time.Minute*time.Duration(conf.Archive.IntervalInMin))
+	}
+
+	e.Use(walletHandler.RequireOwner)
+	if conf.RateLimit.Enabled {
+		e.Use(limiter.LimitWallet)
+	}
+
+	authHandler.RegisterRoutes(e)
+	apiKeyHandler.RegisterRoutes(e)
+	oauthHandler.RegisterRoutes(e)
 	walletHandler.RegisterRoutes(e)
+	walletHandler.RegisterAdminRoutes(e)
 	transactionHandler.RegisterRoutes(e)
+	statementHandler.RegisterRoutes(e)
+	exportHandler.RegisterRoutes(e)
+	bankImportHandler.RegisterRoutes(e)
+	analyticsHandler.RegisterRoutes(e)
+	balanceHandler.RegisterRoutes(e)
 
 	go func() {
 		if err := e.Start(":8000"); err != nil && err != http.ErrServerClosed {
@@ -70,19 +170,3 @@ func main() {
 		e.Logger.Fatal(err)
 	}
 }
-
-func connectToMongo(ctx context.Context, conf config.Conf) *mongo.Client {
-	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.Mongo.URI))
-	if err != nil {
-		panic(err)
-	}
-
-	return mongoClient
-}
-
-func disconnectFromMongo(ctx context.Context, mongoClient *mongo.Client) {
-	if err := mongoClient.Disconnect(ctx); err != nil {
-		log.Error(err)
-	}
-	log.Info("disconnected from mongo")
-}
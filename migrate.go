@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/migration"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// runMigrateCommand runs the mongo migrations from the command line, as in
+// `wallet-api migrate -dry-run`.
+func runMigrateCommand(ctx context.Context, conf config.Conf, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the documents each pending migration would change")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if conf.Storage.Driver != "" && conf.Storage.Driver != config.StorageDriverMongo {
+		return errors.New("migrations only apply to the mongo storage driver")
+	}
+
+	mongoClient := connectToMongo(ctx, conf)
+	defer disconnectFromMongo(ctx, mongoClient)
+
+	results, err := runMigrations(ctx, mongoClient, conf.Mongo, *dryRun)
+	if len(results) == 0 && err == nil {
+		fmt.Println("nothing to migrate")
+	}
+
+	for _, r := range results {
+		if r.Applied {
+			fmt.Printf("%d %s: applied\n", r.Version, r.Name)
+		} else {
+			fmt.Printf("%d %s: %d documents to change\n", r.Version, r.Name, r.Pending)
+		}
+	}
+
+	return err
+}
+
+func migrateMongo(ctx context.Context, mongoClient *mongo.Client, conf config.MongoConf) error {
+	results, err := runMigrations(ctx, mongoClient, conf, false)
+	if err == nil && len(results) == 0 {
+		log.Info("mongo is migrated already")
+	}
+
+	return err
+}
+
+func runMigrations(ctx context.Context, mongoClient *mongo.Client, conf config.MongoConf, dryRun bool) ([]*migration.Result, error) {
+	db := mongoClient.Database(conf.Database)
+	migrator := migration.NewMigrator(db, conf.Collection.Migration, conf.Migration, migration.Migrations(conf.Collection))
+
+	return migrator.Run(ctx, dryRun)
+}
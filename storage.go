@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gokcelb/wallet-api/config"
+	"github.com/gokcelb/wallet-api/internal/apikey"
+	apiKeyMemory "github.com/gokcelb/wallet-api/internal/apikey/memory"
+	apiKeyMongo "github.com/gokcelb/wallet-api/internal/apikey/mongo"
+	"github.com/gokcelb/wallet-api/internal/archive"
+	archiveMongo "github.com/gokcelb/wallet-api/internal/archive/mongo"
+	"github.com/gokcelb/wallet-api/internal/auth"
+	authMemory "github.com/gokcelb/wallet-api/internal/auth/memory"
+	authMongo "github.com/gokcelb/wallet-api/internal/auth/mongo"
+	"github.com/gokcelb/wallet-api/internal/balance"
+	balanceMemory "github.com/gokcelb/wallet-api/internal/balance/memory"
+	balanceMongo "github.com/gokcelb/wallet-api/internal/balance/mongo"
+	"github.com/gokcelb/wallet-api/internal/bankimport"
+	bankImportMemory "github.com/gokcelb/wallet-api/internal/bankimport/memory"
+	bankImportMongo "github.com/gokcelb/wallet-api/internal/bankimport/mongo"
+	"github.com/gokcelb/wallet-api/internal/challenge"
+	challengeMemory "github.com/gokcelb/wallet-api/internal/challenge/memory"
+	challengeMongo "github.com/gokcelb/wallet-api/internal/challenge/mongo"
+	"github.com/gokcelb/wallet-api/internal/oauth"
+	oauthMemory "github.com/gokcelb/wallet-api/internal/oauth/memory"
+	oauthMongo "github.com/gokcelb/wallet-api/internal/oauth/mongo"
+	"github.com/gokcelb/wallet-api/internal/sqlite"
+	transactionMemory "github.com/gokcelb/wallet-api/internal/transaction/memory"
+	transactionMongo "github.com/gokcelb/wallet-api/internal/transaction/mongo"
+	transactionSQLite "github.com/gokcelb/wallet-api/internal/transaction/sqlite"
+	"github.com/gokcelb/wallet-api/internal/wallet"
+	walletMemory "github.com/gokcelb/wallet-api/internal/wallet/memory"
+	walletMongo "github.com/gokcelb/wallet-api/internal/wallet/mongo"
+	walletSQLite "github.com/gokcelb/wallet-api/internal/wallet/sqlite"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type repositories struct {
+	wallet             wallet.WalletRepository
+	transaction        archive.Store
+	transactionArchive archive.Store
+	archiveLease       archive.Lease
+	refreshToken       auth.RefreshTokenRepository
+	denylist           auth.Denylist
+	apiKey             apikey.APIKeyRepository
+	oauthClient        oauth.ClientRepository
+	challenge          challenge.ChallengeRepository
+	bankImport         bankimport.EntryRepository
+	snapshot           balance.SnapshotRepository
+}
+
+// newRepositories builds the repositories of the configured storage driver,
+// returning a func to release what they hold. The sqlite driver only keeps
+// wallets and transactions so far, the rest stays in memory.
+func newRepositories(ctx context.Context, conf config.Conf) (*repositories, func(), error) {
+	switch conf.Storage.Driver {
+	case "", config.StorageDriverMongo:
+		mongoClient := connectToMongo(ctx, conf)
+		repos := newMongoRepositories(mongoClient, conf.Mongo)
+		if err := createMongoIndexes(ctx, repos); err != nil {
+			disconnectFromMongo(ctx, mongoClient)
+			return nil, nil, err
+		}
+		if err := flushPendingTransactions(ctx, repos); err != nil {
+			disconnectFromMongo(ctx, mongoClient)
+			return nil, nil, err
+		}
+		if conf.Mongo.Migration.RunAtStartup {
+			if err := migrateMongo(ctx, mongoClient, conf.Mongo); err != nil {
+				disconnectFromMongo(ctx, mongoClient)
+				return nil, nil, err
+			}
+		}
+		return repos, func() { disconnectFromMongo(ctx, mongoClient) }, nil
+	case config.StorageDriverMemory:
+		log.Warn("using in-memory storage, data will be lost on shutdown")
+		return newMemoryRepositories(), func() {}, nil
+	case config.StorageDriverSQLite:
+		db, err := sqlite.Open(ctx, conf.SQLite)
+		if err != nil {
+			return nil, nil, err
+		}
+		return newSQLiteRepositories(db), func() { closeSQLite(db) }, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown storage driver %q", conf.Storage.Driver)
+}
+
+func newMongoRepositories(mongoClient *mongo.Client, conf config.MongoConf) *repositories {
+	db := mongoClient.Database(conf.Database)
+	transactions := transactionMongo.NewMongo(db.Collection(conf.Collection.Transaction))
+
+	return &repositories{
+		wallet:             walletMongo.NewMongo(db.Collection(conf.Collection.Wallet), transactions),
+		transaction:        transactions,
+		transactionArchive: transactionMongo.NewMongo(db.Collection(conf.Collection.TransactionArchive)),
+		archiveLease:       archiveMongo.NewLease(db.Collection(conf.Collection.Lease)),
+		refreshToken:       authMongo.NewMongo(db.Collection(conf.Collection.RefreshToken)),
+		denylist:           authMongo.NewDenylist(db.Collection(conf.Collection.Denylist)),
+		apiKey:             apiKeyMongo.NewMongo(db.Collection(conf.Collection.APIKey)),
+		oauthClient:        oauthMongo.NewMongo(db.Collection(conf.Collection.OAuthClient)),
+		challenge:          challengeMongo.NewMongo(db.Collection(conf.Collection.Challenge)),
+		bankImport:         bankImportMongo.NewMongo(db.Collection(conf.Collection.BankImport)),
+		snapshot:           balanceMongo.NewMongo(db.Collection(conf.Collection.BalanceSnapshot)),
+	}
+}
+
+// createMongoIndexes creates the indexes of every mongo repository that has
+// any, failing startup when one can't be built, like a unique index over
+// existing duplicates.
+func createMongoIndexes(ctx context.Context, repos *repositories) error {
+	type indexCreator interface {
+		CreateIndexes(ctx context.Context) error
+	}
+
+	named := map[string]interface{}{
+		"wallets":             repos.wallet,
+		"transactions":        repos.transaction,
+		"transaction archive": repos.transactionArchive,
+		"refresh tokens":      repos.refreshToken,
+		"denylist":            repos.denylist,
+		"api keys":            repos.apiKey,
+		"oauth clients":       repos.oauthClient,
+		"challenges":          repos.challenge,
+		"bank imports":        repos.bankImport,
+		"balance snapshots":   repos.snapshot,
+	}
+	for name, repo := range named {
+		if ic, ok := repo.(indexCreator); ok {
+			if err := ic.CreateIndexes(ctx); err != nil {
+				return fmt.Errorf("creating indexes on %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// flushPendingTransactions stores the transactions a crash left on the
+// wallets they were applied to.
+func flushPendingTransactions(ctx context.Context, repos *repositories) error {
+	type pendingFlusher interface {
+		FlushPendingTransactions(ctx context.Context) error
+	}
+
+	if pf, ok := repos.wallet.(pendingFlusher); ok {
+		if err := pf.FlushPendingTransactions(ctx); err != nil {
+			return fmt.Errorf("flushing pending transactions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// runPendingTransactionFlush keeps storing the transactions left pending on
+// wallets while the API runs, for the repositories that can leave them.
+func runPendingTransactionFlush(ctx context.Context, repos *repositories, interval time.Duration) {
+	type flushRunner interface {
+		RunFlush(ctx context.Context, interval time.Duration)
+	}
+
+	if fr, ok := repos.wallet.(flushRunner); ok {
+		fr.RunFlush(ctx, interval)
+	}
+}
+
+// checkSigningKeys fails when an API key or OAuth client requires a signing
+// key that isn't configured, since its requests would then go through
+// unsigned while signing is disabled, or never go through.
+func checkSigningKeys(ctx context.Context, repos *repositories, conf config.SigningConf) error {
+	keys, err := repos.apiKey.ReadAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !key.Revoked && key.SigningKeyID != "" && !conf.HasPartner(key.SigningKeyID) {
+			return fmt.Errorf("api key %s requires signing key %q, which is not configured", key.ID, key.SigningKeyID)
+		}
+	}
+
+	clients, err := repos.oauthClient.ReadAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		if !client.Revoked && client.SigningKeyID != "" && !conf.HasPartner(client.SigningKeyID) {
+			return fmt.Errorf("oauth client %s requires signing key %q, which is not configured", client.ID, client.SigningKeyID)
+		}
+	}
+
+	return nil
+}
+
+func newMemoryRepositories() *repositories {
+	transactions := transactionMemory.NewMemory()
+
+	return &repositories{
+		wallet:             walletMemory.NewMemory(transactions),
+		transaction:        transactions,
+		transactionArchive: transactionMemory.NewMemory(),
+		archiveLease:       archive.NewLocalLease(),
+		refreshToken:       authMemory.NewMemory(),
+		denylist:           auth.NewMemoryDenylist(),
+		apiKey:             apiKeyMemory.NewMemory(),
+		oauthClient:        oauthMemory.NewMemory(),
+		challenge:          challengeMemory.NewMemory(),
+		bankImport:         bankImportMemory.NewMemory(),
+		snapshot:           balanceMemory.NewMemory(),
+	}
+}
+
+func newSQLiteRepositories(db *sql.DB) *repositories {
+	transactions := transactionSQLite.NewSQLite(db)
+
+	repos := newMemoryRepositories()
+	repos.wallet = walletSQLite.NewSQLite(db, transactions)
+	repos.transaction = transactions
+	repos.transactionArchive = transactionSQLite.NewSQLiteArchive(db)
+
+	return repos
+}
+
+func connectToMongo(ctx context.Context, conf config.Conf) *mongo.Client {
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.Mongo.URI))
+	if err != nil {
+		panic(err)
+	}
+
+	return mongoClient
+}
+
+func disconnectFromMongo(ctx context.Context, mongoClient *mongo.Client) {
+	if err := mongoClient.Disconnect(ctx); err != nil {
+		log.Error(err)
+	}
+	log.Info("disconnected from mongo")
+}
+
+func closeSQLite(db *sql.DB) {
+	if err := db.Close(); err != nil {
+		log.Error(err)
+	}
+	log.Info("closed sqlite")
+}